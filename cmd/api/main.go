@@ -1,24 +1,510 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"repo-guardian/internal/config"
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/lifecycle"
+	"repo-guardian/internal/logging"
+	"repo-guardian/internal/user/audit"
+	"repo-guardian/internal/user/authz"
+	"repo-guardian/internal/user/cache"
+	"repo-guardian/internal/user/credential"
 	"repo-guardian/internal/user/handler"
+	"repo-guardian/internal/user/mail"
+	"repo-guardian/internal/user/metrics"
+	"repo-guardian/internal/user/mfa"
+	"repo-guardian/internal/user/ratelimit"
 	"repo-guardian/internal/user/repository"
+	"repo-guardian/internal/user/token"
+	"repo-guardian/internal/user/tracing"
 	"repo-guardian/internal/user/usecase"
 
 	"github.com/gofiber/fiber/v2"
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func main() {
-	app := fiber.New()
+	loader := &config.Loader{Args: os.Args[1:]}
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	if loader.PrintConfig {
+		dump, err := cfg.Dump()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(dump))
+		return
+	}
 
-	timeoutContext := 2 * time.Second
+	var logLevel slog.LevelVar
+	logLevel.Set(cfg.LogLevel())
+	slog.SetDefault(slog.New(logging.NewHandler(os.Stderr, cfg.Log.Format, &logLevel)))
 
-	userRepo := repository.NewMemoryUserRepository()
-	userUsecase := usecase.NewUserUsecase(userRepo, timeoutContext)
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		// The banner would break a stream of JSON records.
+		DisableStartupMessage: cfg.Log.Format == "json",
+	})
+
+	timeoutContext := cfg.Server.RequestTimeout
+	clock := domain.SystemClock{}
+	// Components register with manager after what they depend on, so that
+	// they stop before it.
+	manager := lifecycle.New(cfg.Server.ShutdownTimeout)
+
+	tracerProvider, stopTracing, err := newTracerProvider(cfg.Tracing)
+	if err != nil {
+		log.Fatalf("tracing: %v", err)
+	}
+	// Spans still buffered are exported on shutdown, after everything that
+	// creates them has stopped.
+	manager.OnShutdown("tracing", stopTracing)
+	otel.SetTracerProvider(tracerProvider)
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	otel.SetTextMapPropagator(propagator)
+
+	// Dependencies register their checks with health as they are created.
+	health := usecase.NewHealth(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	handler.NewHealthHandler(app, health)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	handler.NewMetricsHandler(app, registry)
+
+	passwordPolicy := credential.NewPolicy(12, 128)
+	if path := cfg.Auth.BreachedPasswordsFile; path != "" {
+		if err := passwordPolicy.LoadBreachedPasswords(path); err != nil {
+			log.Fatalf("load breached passwords: %v", err)
+		}
+	}
+	passwordHasher := credential.NewArgon2idHasher(credential.DefaultArgon2idParams)
+
+	signingKeys, err := loadSigningKeys(cfg.Auth)
+	if err != nil {
+		log.Fatalf("load signing keys: %v", err)
+	}
+	oneTimeTokens, err := token.NewOneTimeSigner(loadOneTimeTokenSecret(cfg.Auth), clock)
+	if err != nil {
+		log.Fatalf("one-time tokens: %v", err)
+	}
+	mailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("mailer: %v", err)
+	}
+	auditSink, err := newAuditSink(cfg.Audit, cfg.Repository.DSN)
+	if err != nil {
+		log.Fatalf("audit: %v", err)
+	}
+	manager.Close("audit log", auditSink)
+	registerHealth(health, "audit log", auditSink)
+
+	userStore, err := newUserStore(cfg.Repository)
+	if err != nil {
+		log.Fatalf("user repository: %v", err)
+	}
+	userRepo := metrics.NewUserRepository(tracing.NewUserRepository(userStore, tracerProvider), registry, cfg.Metrics.Buckets)
+	if cfg.Cache.Size > 0 {
+		// Cache hits skip the backend's spans and latency histogram, which
+		// then only show the work the backend does.
+		userRepo = cache.NewUserRepository(userRepo, registry,
+			cache.WithSize(cfg.Cache.Size), cache.WithTTL(cfg.Cache.TTL), cache.WithNegativeTTL(cfg.Cache.NegativeTTL))
+	}
+	credRepo := repository.NewMemoryCredentialRepository()
+	refreshRepo := repository.NewMemoryRefreshTokenRepository()
+	apiKeyRepo := repository.NewMemoryAPIKeyRepository()
+	usedTokenRepo := repository.NewMemoryOneTimeTokenRepository()
+	mfaRepo := repository.NewMemoryMFARepository()
+	if durable, ok := userStore.(*repository.DurableUserRepository); ok {
+		// Users kept across restarts must still be able to sign in.
+		credRepo, mfaRepo = durable.Credentials(), durable.MFA()
+	}
+	rateLimitStore, err := newRateLimitStore(cfg.RateLimit, clock)
+	if err != nil {
+		log.Fatalf("rate limit store: %v", err)
+	}
+	erasureJobRepo := repository.NewMemoryErasureJobRepository()
+	for _, repo := range []struct {
+		name string
+		repo any
+	}{
+		{"user repository", userStore},
+		{"credential repository", credRepo},
+		{"refresh token repository", refreshRepo},
+		{"api key repository", apiKeyRepo},
+		{"one-time token repository", usedTokenRepo},
+		{"mfa repository", mfaRepo},
+		{"rate limit store", rateLimitStore},
+		{"erasure job repository", erasureJobRepo},
+	} {
+		manager.Close(repo.name, repo.repo)
+		registerHealth(health, repo.name, repo.repo)
+	}
+	if durable, ok := userStore.(*repository.DurableUserRepository); ok {
+		durableCtx, stopDurable := context.WithCancel(context.Background())
+		var persisting lifecycle.Group
+		persisting.Go(func() { durable.Run(durableCtx) })
+		manager.OnShutdown("user snapshots", func(ctx context.Context) error {
+			stopDurable()
+			return persisting.Wait(ctx)
+		})
+	}
+	authorizer := authz.NewPolicy(authz.DefaultRules...)
+
+	accessTokens := token.NewJWTIssuer(signingKeys, "repo-guardian", cfg.Auth.AccessTokenTTL, clock)
+	tokenUsecase := usecase.NewTokenUsecase(userRepo, refreshRepo, accessTokens, cfg.Auth.RefreshTokenTTL, timeoutContext)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, authorizer, timeoutContext)
+	cors := handler.NewCORS(cfg.CORS.AllowOrigins, cfg.CORS.AllowCredentials, cfg.CORS.MaxAge)
+	app.Use(handler.Metrics(registry, cfg.Metrics.Buckets))
+	app.Use(handler.Tracing(tracerProvider, propagator))
+	app.Use(cors.Handle)
+	app.Use(handler.RequestID())
+	app.Use(handler.AccessLog(slog.Default()))
+	// Checking a token or API key costs enough that floods of forged ones
+	// are limited before they are checked.
+	app.Use(handler.RateLimit(ratelimit.NewTokenBucket(rateLimitStore, 300, 100*time.Millisecond, clock), handler.CredentialKey))
+	app.Use(handler.Authenticate(tokenUsecase, apiKeyUsecase))
+	app.Use(handler.RateLimit(ratelimit.NewTokenBucket(rateLimitStore, 60, time.Second, clock), handler.ClientKey))
+	// Sign-ups and anything that checks a secret share a much smaller budget.
+	strictLimit := handler.RateLimit(ratelimit.NewSlidingWindow(rateLimitStore, 10, time.Minute, clock), handler.ClientKey)
+	for _, path := range []string{"/users", "/auth/login", "/auth/login/mfa", "/auth/password-reset",
+		"/users/:id/mfa/totp/confirm", "/users/:id/mfa/totp/disable"} {
+		app.Post(path, strictLimit)
+	}
+
+	userUsecase := metrics.NewUserUsecase(tracing.NewUserUsecase(usecase.NewUserUsecase(userRepo, timeoutContext,
+		usecase.WithCredentials(credRepo, passwordHasher, passwordPolicy),
+		usecase.WithAuthorizer(authorizer),
+		usecase.WithAudit(auditSink)), tracerProvider), registry)
 	handler.NewUserHandler(app, userUsecase)
+	handler.NewAuditHandler(app, usecase.NewAuditUsecase(auditSink, authorizer, timeoutContext))
+
+	if err := bootstrapAdmin(userUsecase, cfg.Auth); err != nil {
+		log.Fatalf("bootstrap admin: %v", err)
+	}
+
+	loginLockout := ratelimit.NewLockout(rateLimitStore, 5, time.Minute, time.Hour, clock)
+	mfaUsecase := usecase.NewMFAUsecase(userRepo, mfaRepo, mfa.NewTOTP("repo-guardian"), authorizer, loginLockout, timeoutContext)
+	handler.NewMFAHandler(app, mfaUsecase)
+
+	var erasureJobs lifecycle.Group
+	manager.OnShutdown("erasure jobs", erasureJobs.Wait)
+	health.Register("erasure jobs", domain.HealthFunc(func(context.Context) error {
+		if n := erasureJobs.Running(); n > maxErasureJobs {
+			return fmt.Errorf("%d erasure jobs in progress", n)
+		}
+		return nil
+	}))
+	privacyUsecase := usecase.NewPrivacyUsecase(userRepo, credRepo, refreshRepo, apiKeyRepo, mfaRepo, auditSink,
+		erasureJobRepo, authorizer, timeoutContext, usecase.WithJobRunner(erasureJobs.Go))
+	handler.NewPrivacyHandler(app, privacyUsecase)
+
+	authUsecase, err := usecase.NewAuthUsecase(userRepo, credRepo, passwordHasher, timeoutContext,
+		usecase.WithMFA(mfaUsecase, oneTimeTokens, usedTokenRepo),
+		usecase.WithLockout(loginLockout))
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler.NewAuthHandler(app, authUsecase, tokenUsecase)
+	handler.NewAPIKeyHandler(app, apiKeyUsecase)
+
+	accountUsecase := usecase.NewAccountUsecase(usecase.AccountDeps{
+		Users:         userRepo,
+		Credentials:   credRepo,
+		RefreshTokens: refreshRepo,
+		Hasher:        passwordHasher,
+		Policy:        passwordPolicy,
+		Tokens:        oneTimeTokens,
+		UsedTokens:    usedTokenRepo,
+		Mailer:        mailer,
+		Authorizer:    authorizer,
+	}, cfg.Server.BaseURL, timeoutContext)
+	handler.NewAccountHandler(app, accountUsecase)
+
+	purger := usecase.NewPurger(userRepo, cfg.Repository.DeletedRetention, cfg.Repository.PurgeInterval, clock)
+	purgeCtx, stopPurger := context.WithCancel(context.Background())
+	var purging lifecycle.Group
+	purging.Go(func() { purger.Run(purgeCtx) })
+	manager.OnShutdown("purger", func(ctx context.Context) error {
+		stopPurger()
+		return purging.Wait(ctx)
+	})
+
+	go reloadOnHangup(loader, cfg, func(cfg *config.Config) {
+		logLevel.Set(cfg.LogLevel())
+		cors.Update(cfg.CORS.AllowOrigins, cfg.CORS.AllowCredentials, cfg.CORS.MaxAge)
+	})
+
+	// The server stops first: it no longer accepts connections and waits
+	// for the requests in flight.
+	manager.OnShutdown("http server", app.ShutdownWithContext)
+	manager.OnShutdown("readiness", func(ctx context.Context) error {
+		health.Drain()
+		select {
+		case <-time.After(cfg.Health.DrainDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// A second signal kills the process rather than waiting.
+		<-ctx.Done()
+		stop()
+	}()
+	err = manager.Run(ctx, func() error {
+		if cfg.TLS.CertFile != "" {
+			return app.ListenTLS(cfg.Server.Listen, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		}
+		return app.Listen(cfg.Server.Listen)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// reloadOnHangup reloads the configuration on SIGHUP and applies the
+// settings that can change while running. A configuration that fails to
+// load is ignored, and changes to other settings are only logged.
+func reloadOnHangup(loader *config.Loader, current *config.Config, apply func(cfg *config.Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		cfg, err := loader.Load()
+		if err != nil {
+			slog.Error("reload config failed", "error", err)
+			continue
+		}
+		reloaded, restart := config.Changes(current, cfg)
+		apply(cfg)
+		if len(restart) > 0 {
+			slog.Warn("reloaded config needs a restart to apply", "settings", restart)
+		}
+		slog.Info("reloaded config", "changed", reloaded)
+		current = cfg
+	}
+}
+
+// newUserStore opens the configured user repository backend.
+func newUserStore(cfg config.Repository) (domain.UserRepository, error) {
+	switch cfg.Backend {
+	case "sharded":
+		return repository.NewShardedMemoryUserRepository(cfg.Shards), nil
+	case "durable":
+		return repository.OpenDurableUserRepository(cfg.Dir,
+			repository.WithFsync(repository.FsyncPolicy(cfg.Fsync), cfg.FsyncInterval),
+			repository.WithSnapshotInterval(cfg.SnapshotInterval))
+	default:
+		return repository.NewMemoryUserRepository(), nil
+	}
+}
+
+func newRateLimitStore(cfg config.RateLimit, clock domain.Clock) (domain.RateLimitStore, error) {
+	if cfg.RedisURL == "" {
+		return ratelimit.NewMemoryStore(clock), nil
+	}
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewRedisStore(redis.NewClient(opts), "repo-guardian:ratelimit:"), nil
+}
+
+// loadSigningKeys reads the access token signing keys. JWTKeyFiles lists
+// PEM files with the active key first; the others only verify tokens issued
+// before a rotation. JWTSecret selects HS256 instead. Without either, an
+// ephemeral key is generated and tokens do not survive a restart.
+func loadSigningKeys(cfg config.Auth) (*token.KeySet, error) {
+	if len(cfg.JWTKeyFiles) > 0 {
+		var keys []token.Key
+		for _, path := range cfg.JWTKeyFiles {
+			key, err := token.LoadKeyFile(path)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		return token.NewKeySet(keys[0], keys[1:]...)
+	}
+	if secret := cfg.JWTSecret; secret != "" {
+		key, err := token.NewHMACKey("hs256", []byte(secret))
+		if err != nil {
+			return nil, err
+		}
+		return token.NewKeySet(key)
+	}
+
+	slog.Warn("JWT_KEY_FILES and JWT_SECRET are unset; using an ephemeral signing key")
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return token.NewKeySet(token.NewEd25519Key("ephemeral", private))
+}
+
+// bootstrapAdmin registers the first admin, since registration over HTTP
+// only ever creates regular users.
+func bootstrapAdmin(users domain.UserUsecase, cfg config.Auth) error {
+	username := cfg.BootstrapAdminUsername
+	if username == "" {
+		return nil
+	}
+	ctx := domain.WithPrincipal(context.Background(), domain.Principal{Subject: "system", Role: domain.RoleAdmin})
+	err := users.Register(ctx, &domain.User{
+		ID:       1,
+		Username: username,
+		Email:    cfg.BootstrapAdminEmail,
+		Role:     domain.RoleAdmin,
+		Password: cfg.BootstrapAdminPassword,
+	})
+	if errors.Is(err, domain.ErrConflict) {
+		// A durable repository kept the admin from an earlier run.
+		slog.Info("bootstrap admin already exists", "username", username)
+		return nil
+	}
+	return err
+}
+
+// loadOneTimeTokenSecret returns the configured secret, or a random secret
+// that invalidates mailed links on restart.
+func loadOneTimeTokenSecret(cfg config.Auth) []byte {
+	if secret := cfg.OneTimeTokenSecret; secret != "" {
+		return []byte(secret)
+	}
+	slog.Warn("ONE_TIME_TOKEN_SECRET is unset; using an ephemeral secret")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal(err)
+	}
+	return secret
+}
+
+// newMailer sends through the SMTP server if set, otherwise appends mails
+// to a file or logs them.
+func newMailer(cfg config.Mail) (domain.Mailer, error) {
+	if cfg.SMTPAddr != "" {
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}), nil
+	}
+	if cfg.File != "" {
+		return mail.NewFileMailer(cfg.From, cfg.File)
+	}
+	return mail.NewLogMailer(cfg.From), nil
+}
+
+// newTracerProvider exports spans through the configured exporter, and
+// returns a function that flushes and stops it. Without an exporter, spans
+// are not recorded at all.
+func newTracerProvider(cfg config.Tracing) (trace.TracerProvider, func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	return tp, tp.Shutdown, nil
+}
+
+// maxErasureJobs is how many erasure jobs may run at once before the server
+// reports itself unready.
+const maxErasureJobs = 100
+
+// registerHealth adds v to the readiness checks if it can be checked.
+func registerHealth(health *usecase.Health, name string, v any) {
+	if checker, ok := v.(domain.HealthChecker); ok {
+		health.Register(name, checker)
+	}
+}
+
+// auditCheckpointEvery is how many audit events a signed checkpoint covers.
+const auditCheckpointEvery = 100
 
-	log.Fatal(app.Listen(":3000"))
+// newAuditSink stores audit events in the PostgreSQL database at dsn,
+// appends them to a file as JSON lines, or keeps them in memory. Events are
+// hash-chained, and every auditCheckpointEvery events is signed with the
+// Ed25519 key in the signing key file if it is set.
+func newAuditSink(cfg config.Audit, dsn string) (domain.AuditSink, error) {
+	var sink domain.AuditSink = audit.NewMemorySink()
+	switch {
+	case dsn != "":
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := db.ExecContext(context.Background(), audit.SQLSchema); err != nil {
+			db.Close()
+			return nil, err
+		}
+		sink = audit.NewSQLSink(db, audit.Dollar)
+	case cfg.File != "":
+		file, err := audit.NewFileSink(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		sink = file
+	}
+	var key ed25519.PrivateKey
+	if path := cfg.SigningKeyFile; path != "" {
+		var err error
+		if key, err = audit.LoadSigningKey(path); err != nil {
+			return nil, err
+		}
+	}
+	return audit.NewChainedSink(context.Background(), sink, key, auditCheckpointEvery)
 }
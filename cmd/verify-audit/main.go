@@ -0,0 +1,68 @@
+// Command verify-audit checks that an audit log written as JSON lines has not
+// been altered. It walks the hash chain and, given the checkpoint public key,
+// the checkpoint signatures, and reports the first broken link.
+//
+//	verify-audit -file audit.jsonl -key audit_pub.pem
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"repo-guardian/internal/user/audit"
+)
+
+func main() {
+	file := flag.String("file", os.Getenv("AUDIT_FILE"), "audit log to verify, as JSON lines")
+	keyFile := flag.String("key", "", "PEM file with the Ed25519 checkpoint public key")
+	flag.Parse()
+
+	os.Exit(run(*file, *keyFile))
+}
+
+func run(file, keyFile string) int {
+	if file == "" {
+		fmt.Fprintln(os.Stderr, "verify-audit: -file is required")
+		return 2
+	}
+	var pub ed25519.PublicKey
+	if keyFile != "" {
+		var err error
+		if pub, err = audit.LoadVerifyKey(keyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "verify-audit: %v\n", err)
+			return 2
+		}
+	}
+
+	sink, err := audit.OpenFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify-audit: %v\n", err)
+		return 2
+	}
+	report, err := audit.Verify(context.Background(), sink, pub)
+	var broken *audit.BrokenLinkError
+	switch {
+	case errors.As(err, &broken):
+		fmt.Printf("FAIL: %v (after %d valid events)\n", broken, report.Events)
+		return 1
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "verify-audit: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("OK: %d events, %d checkpoints", report.Events, report.Checkpoints)
+	if pub == nil {
+		fmt.Print(" (signatures not checked, no -key given)")
+	} else if report.LastCheckpoint > 0 {
+		fmt.Printf(", last checkpoint at event %d", report.LastCheckpoint)
+	}
+	if report.Erased > 0 {
+		fmt.Printf("; personal data erased from %d events", report.Erased)
+	}
+	fmt.Println()
+	return 0
+}
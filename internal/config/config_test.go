@@ -0,0 +1,261 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoader_Defaults(t *testing.T) {
+	l := &Loader{Args: []string{"-env-file", filepath.Join(t.TempDir(), "missing.env")}, LookupEnv: env(nil)}
+	if _, err := l.Load(); err == nil {
+		t.Error("Load() with a missing -env-file succeeded")
+	}
+
+	t.Chdir(t.TempDir())
+	l = &Loader{LookupEnv: env(nil)}
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Server.Listen != ":3000" || cfg.Server.RequestTimeout != 2*time.Second || cfg.Repository.Backend != "memory" {
+		t.Errorf("Load() = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoader_Precedence(t *testing.T) {
+	for _, file := range []struct{ name, content string }{
+		{"config.yaml", `
+server:
+  listen: ":4000"
+  request_timeout: 5s
+  base_url: https://file.example.com
+log:
+  level: warn
+cors:
+  allow_origins: [https://app.example.com]
+`},
+		{"config.toml", `
+[server]
+listen = ":4000"          # overridden below
+request_timeout = "5s"
+base_url = "https://file.example.com"
+
+[log]
+level = "warn"
+
+[cors]
+allow_origins = ["https://app.example.com"]
+`},
+	} {
+		t.Run(file.name, func(t *testing.T) {
+			dir := t.TempDir()
+			configPath := writeFile(t, file.name, file.content)
+			envPath := filepath.Join(dir, ".env")
+			os.WriteFile(envPath, []byte("LISTEN_ADDR=:5000\nLOG_LEVEL=debug\nMAIL_FROM=dotenv@example.com\n"), 0o600)
+
+			l := &Loader{
+				Args: []string{"-env-file", envPath, "-server.listen", ":7000"},
+				LookupEnv: env(map[string]string{
+					"CONFIG_FILE":          configPath,
+					"LISTEN_ADDR":          ":6000",
+					"LOG_LEVEL":            "error",
+					"WRITE_TIMEOUT":        "3s",
+					"METRICS_BUCKETS":      "0.1, 1,10",
+					"TRACING_SAMPLE_RATIO": "0.25",
+				}),
+			}
+			cfg, err := l.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			checks := []struct {
+				name      string
+				got, want any
+			}{
+				{"flag over environment", cfg.Server.Listen, ":7000"},
+				{"environment over .env", cfg.Log.Level, "error"},
+				{".env over defaults", cfg.Mail.From, "dotenv@example.com"},
+				{"file over defaults", cfg.Server.RequestTimeout, 5 * time.Second},
+				{"file list", cfg.CORS.AllowOrigins, []string{"https://app.example.com"}},
+				{"environment over defaults", cfg.Server.WriteTimeout, 3 * time.Second},
+				{"environment list", cfg.Metrics.Buckets, []float64{0.1, 1, 10}},
+				{"environment float", cfg.Tracing.SampleRatio, 0.25},
+				{"default", cfg.Server.IdleTimeout, time.Minute},
+			}
+			for _, c := range checks {
+				if !equal(c.got, c.want) {
+					t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func equal(a, b any) bool {
+	if as, ok := a.([]string); ok {
+		return slices.Equal(as, b.([]string))
+	}
+	if af, ok := a.([]float64); ok {
+		return slices.Equal(af, b.([]float64))
+	}
+	return a == b
+}
+
+func TestLoader_Errors(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"unknown flag", []string{"-nope"}, nil, "nope"},
+		{"bad duration", nil, map[string]string{"REQUEST_TIMEOUT": "soon"}, "REQUEST_TIMEOUT"},
+		{"bad bucket", nil, map[string]string{"METRICS_BUCKETS": "0.1,fast"}, "METRICS_BUCKETS"},
+		{"bad bool flag", []string{"-cors.allow_credentials", "maybe"}, nil, "cors.allow_credentials"},
+		{"unknown key in file", []string{"-config", writeFile(t, "c.yaml", "server:\n  listne: x\n")}, nil, "listne"},
+		{"unknown key in TOML file", []string{"-config", writeFile(t, "c.toml", "[server]\nlistne = \"x\"\n")}, nil, "server.listne"},
+		{"bad TOML", []string{"-config", writeFile(t, "d.toml", "[server\n")}, nil, "d.toml"},
+		{"bad duration in TOML", []string{"-config", writeFile(t, "e.toml", "[server]\nrequest_timeout = \"soon\"\n")}, nil, "e.toml"},
+		{"missing file", []string{"-config", "missing.yaml"}, nil, "missing.yaml"},
+		{"invalid", nil, map[string]string{"REPOSITORY_BACKEND": "postgres"}, "repository.backend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Loader{Args: tt.args, LookupEnv: env(tt.env)}).Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_TOMLKeys(t *testing.T) {
+	var check func(t reflect.Type)
+	check = func(typ reflect.Type) {
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			if yamlKey, tomlKey := f.Tag.Get("yaml"), f.Tag.Get("toml"); yamlKey != tomlKey {
+				t.Errorf("%s.%s: toml key %q, want the yaml key %q", typ.Name(), f.Name, tomlKey, yamlKey)
+			}
+			if f.Type.Kind() == reflect.Struct {
+				check(f.Type)
+			}
+		}
+	}
+	check(reflect.TypeFor[Config]())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"no listen address", func(c *Config) { c.Server.Listen = "" }, "server.listen"},
+		{"relative base URL", func(c *Config) { c.Server.BaseURL = "/app" }, "server.base_url"},
+		{"zero timeout", func(c *Config) { c.Server.RequestTimeout = 0 }, "server.request_timeout"},
+		{"negative cache size", func(c *Config) { c.Cache.Size = -1 }, "cache.size"},
+		{"no shards", func(c *Config) { c.Repository.Shards = 0 }, "repository.shards"},
+		{"fsync policy", func(c *Config) { c.Repository.Fsync = "sometimes" }, "repository.fsync"},
+		{"durable without dir", func(c *Config) {
+			c.Repository.Backend = "durable"
+			c.Repository.Dir = ""
+		}, "repository.dir"},
+		{"dsn", func(c *Config) { c.Repository.DSN = "postgres://repo:secret@db/repo" }, ""},
+		{"dsn without host", func(c *Config) { c.Repository.DSN = "repo:secret@db" }, "repository.dsn"},
+		{"dsn and audit file", func(c *Config) {
+			c.Repository.DSN = "postgres://db/repo"
+			c.Audit.File = "audit.jsonl"
+		}, "audit.file"},
+		{"log level", func(c *Config) { c.Log.Level = "loud" }, "log.level"},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "tracing.exporter"},
+		{"log format", func(c *Config) { c.Log.Format = "logfmt" }, "log.format"},
+		{"tracing endpoint", func(c *Config) { c.Tracing.Endpoint = "localhost:4318" }, "tracing.endpoint"},
+		{"redis url", func(c *Config) { c.RateLimit.RedisURL = "localhost:6379" }, "rate_limit.redis_url"},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "tracing.sample_ratio"},
+		{"unsorted buckets", func(c *Config) { c.Metrics.Buckets = []float64{1, 0.5} }, "metrics.buckets"},
+		{"repeated bucket", func(c *Config) { c.Metrics.Buckets = []float64{0.5, 0.5} }, "metrics.buckets"},
+		{"no buckets", func(c *Config) { c.Metrics.Buckets = nil }, "metrics.buckets"},
+		{"drain longer than shutdown", func(c *Config) { c.Health.DrainDelay = time.Minute }, "health.drain_delay"},
+		{"half TLS", func(c *Config) { c.TLS.CertFile = "cert.pem" }, "tls.cert_file"},
+		{"origin with path", func(c *Config) { c.CORS.AllowOrigins = []string{"https://a.example.com/x"} }, "cors.allow_origins"},
+		{"credentials for any origin", func(c *Config) {
+			c.CORS.AllowOrigins = []string{"*"}
+			c.CORS.AllowCredentials = true
+		}, "cors.allow_credentials"},
+		{"admin without password", func(c *Config) { c.Auth.BootstrapAdminUsername = "root" }, "bootstrap_admin_password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.modify(c)
+			err := c.Validate()
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Dump(t *testing.T) {
+	c := Default()
+	c.Auth.JWTSecret = "super secret value"
+	c.Mail.SMTPPassword = "hunter2"
+	c.Repository.DSN = "postgres://repo:pa55word@db/repo"
+
+	out, err := c.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := string(out)
+	if strings.Contains(dump, "super secret") || strings.Contains(dump, "hunter2") || strings.Contains(dump, "pa55word") {
+		t.Errorf("Dump() leaked a secret:\n%s", dump)
+	}
+	if !strings.Contains(dump, "jwt_secret: '"+Redacted+"'") || !strings.Contains(dump, "request_timeout: 2s") {
+		t.Errorf("Dump() =\n%s", dump)
+	}
+	if strings.Contains(dump, "redis_url: '"+Redacted) {
+		t.Errorf("Dump() redacted an empty secret")
+	}
+	if c.Auth.JWTSecret != "super secret value" {
+		t.Errorf("Dump() modified the configuration")
+	}
+}
+
+func TestChanges(t *testing.T) {
+	old, updated := Default(), Default()
+	updated.Log.Level = "debug"
+	updated.CORS.AllowOrigins = []string{"https://app.example.com"}
+	updated.Server.Listen = ":4000"
+
+	reloadable, restart := Changes(old, updated)
+	if !slices.Equal(reloadable, []string{"log.level", "cors.allow_origins"}) || !slices.Equal(restart, []string{"server.listen"}) {
+		t.Errorf("Changes() = %v, %v", reloadable, restart)
+	}
+	if reloadable, restart := Changes(old, Default()); reloadable != nil || restart != nil {
+		t.Errorf("Changes() of equal configurations = %v, %v", reloadable, restart)
+	}
+}
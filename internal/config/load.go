@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Loader reads the configuration from its sources. Load can be called again
+// to pick up changes to the files and the environment.
+type Loader struct {
+	// Args are the command line flags, without the program name.
+	Args []string
+	// LookupEnv reads the environment; os.LookupEnv if nil.
+	LookupEnv func(key string) (string, bool)
+
+	// PrintConfig is set by Load when -print-config was given.
+	PrintConfig bool
+}
+
+// Load merges the defaults, the file named by -config or CONFIG_FILE, the
+// .env file named by -env-file, the environment and the flags, and
+// validates the result.
+func (l *Loader) Load() (*Config, error) {
+	cfg := Default()
+	settings := settingsOf(cfg)
+
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFile := fs.String("config", "", "YAML or TOML configuration file")
+	envFile := fs.String("env-file", ".env", "file of environment variables")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration and exit")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.path] = fs.String(s.path, "", s.usage)
+	}
+	if err := fs.Parse(l.Args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	l.PrintConfig = *printConfig
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	dotenv, err := godotenv.Read(*envFile)
+	if err != nil && (explicit["env-file"] || !errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("%s: %w", *envFile, err)
+	}
+	lookup := func(key string) (string, bool) {
+		if value, ok := l.lookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := dotenv[key]
+		return value, ok
+	}
+
+	if !explicit["config"] {
+		*configFile, _ = lookup("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := decodeFile(*configFile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := lookup(s.env); ok {
+			if err := s.set(value); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+		if explicit[s.path] {
+			if err := s.set(*flagValues[s.path]); err != nil {
+				return nil, fmt.Errorf("-%s: %w", s.path, err)
+			}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (l *Loader) lookupEnv(key string) (string, bool) {
+	if l.LookupEnv == nil {
+		return os.LookupEnv(key)
+	}
+	return l.LookupEnv(key)
+}
+
+// decodeFile reads a YAML file, or a TOML file if its name ends in .toml,
+// into cfg. Unknown keys are an error, to catch typos.
+func decodeFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		md, err := toml.Decode(string(raw), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown key %q", path, undecoded[0].String())
+		}
+		return nil
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// setting is one leaf of a Config.
+type setting struct {
+	path   string
+	env    string
+	usage  string
+	secret bool
+	reload bool
+	value  reflect.Value
+}
+
+// settingsOf lists the settings of cfg, which stay addressable through
+// their values.
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			path := prefix + strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if f.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			settings = append(settings, setting{
+				path:   path,
+				env:    f.Tag.Get("env"),
+				usage:  f.Tag.Get("usage"),
+				secret: f.Tag.Get("secret") == "true",
+				reload: f.Tag.Get("reload") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return settings
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// set parses value into the setting.
+func (s setting) set(value string) error {
+	switch {
+	case s.value.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(d))
+	case s.value.Kind() == reflect.String:
+		s.value.SetString(value)
+	case s.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		s.value.SetBool(b)
+	case s.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(n))
+	case s.value.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		s.value.SetFloat(f)
+	case s.value.Type() == reflect.TypeFor[[]string]():
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		s.value.Set(reflect.ValueOf(list))
+	case s.value.Type() == reflect.TypeFor[[]float64]():
+		var list []float64
+		for _, item := range strings.Split(value, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+			if err != nil {
+				return err
+			}
+			list = append(list, f)
+		}
+		s.value.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+// Dump renders cfg as YAML with secrets redacted.
+func (c *Config) Dump() ([]byte, error) {
+	redacted := *c
+	for _, s := range settingsOf(&redacted) {
+		if s.secret && s.value.String() != "" {
+			s.value.SetString(Redacted)
+		}
+	}
+	return yaml.Marshal(&redacted)
+}
+
+// Changes lists the dotted paths of the settings that differ between old and
+// new, split by whether they can be applied without a restart.
+func Changes(old, new *Config) (reloadable, restart []string) {
+	oldSettings, newSettings := settingsOf(old), settingsOf(new)
+	for i, s := range newSettings {
+		if reflect.DeepEqual(oldSettings[i].value.Interface(), s.value.Interface()) {
+			continue
+		}
+		if s.reload {
+			reloadable = append(reloadable, s.path)
+		} else {
+			restart = append(restart, s.path)
+		}
+	}
+	return reloadable, restart
+}
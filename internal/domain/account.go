@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type TokenPurpose string
+
+const (
+	PurposeVerifyEmail   TokenPurpose = "verify_email"
+	PurposeResetPassword TokenPurpose = "reset_password"
+)
+
+// OneTimeToken is the content of a signed token mailed to a user. Email binds
+// a verification token to the address it was sent to.
+type OneTimeToken struct {
+	ID        string       `json:"jti"`
+	Purpose   TokenPurpose `json:"pur"`
+	UserID    int64        `json:"sub"`
+	Email     string       `json:"eml,omitempty"`
+	ExpiresAt time.Time    `json:"exp"`
+}
+
+// OneTimeTokenSigner turns tokens into tamper-proof strings and back. Parse
+// rejects tokens issued for another purpose and expired tokens with
+// ErrInvalidToken.
+type OneTimeTokenSigner interface {
+	Sign(token *OneTimeToken) (string, error)
+	Parse(purpose TokenPurpose, signed string) (*OneTimeToken, error)
+}
+
+// OneTimeTokenRepository remembers which tokens have been used.
+type OneTimeTokenRepository interface {
+	// Consume records the token with id as used, or returns ErrTokenReused
+	// if it already was. The record can be dropped after expiresAt.
+	Consume(ctx context.Context, id string, expiresAt time.Time) error
+}
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type AccountUsecase interface {
+	RequestEmailVerification(ctx context.Context, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	// RequestPasswordReset mails a reset link if username belongs to a user
+	// with a verified email. It reports success either way, so that it cannot
+	// be used to discover accounts.
+	RequestPasswordReset(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, token, password string) error
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeUsersRead  Scope = "users:read"
+	ScopeUsersWrite Scope = "users:write"
+	ScopeUsersAdmin Scope = "users:admin"
+)
+
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeUsersRead, ScopeUsersWrite, ScopeUsersAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// Scope returns the scope a caller needs to perform the action.
+func (a Action) Scope() Scope {
+	switch a {
+	case ActionRead, ActionList, ActionExport:
+		return ScopeUsersRead
+	case ActionCreate, ActionUpdate, ActionDelete, ActionErase:
+		return ScopeUsersWrite
+	default:
+		return ScopeUsersAdmin
+	}
+}
+
+// APIKey lets a non-interactive caller authenticate. The secret is shown once
+// on creation; only its hash is stored. The ID is public and doubles as the
+// prefix of the key, so a key can be looked up without scanning.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Role       Role       `json:"role"`
+	Scopes     []Scope    `json:"scopes"`
+	Hash       string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Validate checks the fields a client is allowed to set.
+func (k *APIKey) Validate() error {
+	if k.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrBadParamInput)
+	}
+	if k.Role != RoleService && k.Role != RoleAdmin {
+		return fmt.Errorf("%w: role must be %q or %q", ErrBadParamInput, RoleService, RoleAdmin)
+	}
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrBadParamInput)
+	}
+	for _, s := range k.Scopes {
+		if !s.Valid() {
+			return fmt.Errorf("%w: unknown scope %q", ErrBadParamInput, s)
+		}
+	}
+	return nil
+}
+
+// Principal returns the principal requests made with the key act as.
+func (k *APIKey) Principal() Principal {
+	return Principal{Subject: "apikey:" + k.ID, Role: k.Role, Scopes: slices.Clone(k.Scopes)}
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	Touch(ctx context.Context, id string, at time.Time) error
+}
+
+type APIKeyUsecase interface {
+	// Create stores key and returns the secret to hand to the caller.
+	Create(ctx context.Context, key *APIKey) (string, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, secret string) (Principal, error)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAction_Scope(t *testing.T) {
+	tests := map[Action]Scope{
+		ActionRead:        ScopeUsersRead,
+		ActionList:        ScopeUsersRead,
+		ActionCreate:      ScopeUsersWrite,
+		ActionUpdate:      ScopeUsersWrite,
+		ActionDelete:      ScopeUsersWrite,
+		ActionRestore:     ScopeUsersAdmin,
+		ActionReadDeleted: ScopeUsersAdmin,
+		ActionAssignRole:  ScopeUsersAdmin,
+		ActionManageKeys:  ScopeUsersAdmin,
+	}
+	for action, want := range tests {
+		if got := action.Scope(); got != want {
+			t.Errorf("%s.Scope() = %s, want %s", action, got, want)
+		}
+	}
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	if !(Principal{}).HasScope(ScopeUsersAdmin) {
+		t.Errorf("HasScope() of an unrestricted principal = false")
+	}
+	p := Principal{Scopes: []Scope{ScopeUsersRead}}
+	if !p.HasScope(ScopeUsersRead) || p.HasScope(ScopeUsersWrite) {
+		t.Errorf("HasScope() does not follow the principal's scopes")
+	}
+	if (Principal{Scopes: []Scope{}}).HasScope(ScopeUsersRead) {
+		t.Errorf("HasScope() of a principal with no scopes = true")
+	}
+}
+
+func TestAPIKey_Principal(t *testing.T) {
+	key := &APIKey{ID: "abc", Role: RoleService, Scopes: []Scope{ScopeUsersRead}}
+	p := key.Principal()
+	want := Principal{Subject: "apikey:abc", Role: RoleService, Scopes: []Scope{ScopeUsersRead}}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Principal() = %+v, want %+v", p, want)
+	}
+	p.Scopes[0] = ScopeUsersAdmin
+	if key.Scopes[0] != ScopeUsersRead {
+		t.Errorf("Principal() shares its scopes with the key")
+	}
+}
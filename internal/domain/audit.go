@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Redacted stands in for the value of a sensitive field in audit events.
+const Redacted = "[REDACTED]"
+
+// Change is the value of a field before and after a mutation. A nil side
+// means the user did not exist or no longer exists.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEvent records a mutation: who did what to whom, when, and what
+// changed.
+type AuditEvent struct {
+	// ID is assigned by the sink, in the order events are appended.
+	ID        int64             `json:"id"`
+	Time      time.Time         `json:"time"`
+	Actor     string            `json:"actor,omitempty"`
+	Action    Action            `json:"action"`
+	Target    string            `json:"target"`
+	RequestID string            `json:"request_id,omitempty"`
+	Changes   map[string]Change `json:"changes,omitempty"`
+	// ChangeHashes holds a hash of each change, salted with the field's own
+	// salt in Salts. The chain covers the hashes rather than Changes, so that
+	// personal data can be erased from Changes without breaking the chain.
+	// Erasing a field drops its salt, so the erased values cannot be
+	// confirmed by guessing, while every other change can still be checked.
+	ChangeHashes map[string]string `json:"change_hashes,omitempty"`
+	Salts        map[string]string `json:"salts,omitempty"`
+	// PrevHash and Hash chain the events together: Hash covers the event
+	// including PrevHash, the Hash of the event before it, so altering or
+	// removing an event breaks every later link.
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+	// Signature is set on checkpoint events. It signs Hash, and with it the
+	// whole chain up to the event.
+	Signature string `json:"signature,omitempty"`
+}
+
+// PersonalFields are the changes that identify a person, and are redacted
+// when their data is erased.
+var PersonalFields = []string{"username", "email"}
+
+// Redact replaces the values of fields in the event's changes with Redacted
+// and drops their salts. It reports whether anything changed.
+func (e *AuditEvent) Redact(fields []string) bool {
+	var redacted bool
+	for _, field := range fields {
+		change, ok := e.Changes[field]
+		if !ok {
+			continue
+		}
+		var erased bool
+		if change.Before != nil && change.Before != Redacted {
+			change.Before, erased = Redacted, true
+		}
+		if change.After != nil && change.After != Redacted {
+			change.After, erased = Redacted, true
+		}
+		if erased {
+			e.Changes[field] = change
+			delete(e.Salts, field)
+			redacted = true
+		}
+	}
+	return redacted
+}
+
+// IsRedacted reports whether both sides of the change are Redacted or
+// missing.
+func (c Change) IsRedacted() bool {
+	return (c.Before == nil || c.Before == Redacted) && (c.After == nil || c.After == Redacted)
+}
+
+// UserTarget names a user as the target of audit events.
+func UserTarget(id int64) string {
+	return userSubjectPrefix + strconv.FormatInt(id, 10)
+}
+
+type AuditFilter struct {
+	// Target restricts the events to those about one target, if set.
+	Target string
+	// After is the ID of the last event already seen.
+	After int64
+	Limit int
+}
+
+func (f *AuditFilter) Normalize() error {
+	if f.Limit < 0 || f.Limit > MaxListLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrBadParamInput, MaxListLimit)
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultListLimit
+	}
+	return nil
+}
+
+// AuditSink stores audit events. It is append-only: events are never
+// removed, nor modified other than by AuditEraser.
+type AuditSink interface {
+	// Append assigns the event its ID and stores it.
+	Append(ctx context.Context, event *AuditEvent) error
+	// Query returns the events matching filter in the order they were
+	// appended.
+	Query(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error)
+}
+
+// AuditEraser is implemented by sinks that can erase personal data from
+// stored events.
+type AuditEraser interface {
+	// Erase redacts fields in the changes of every event about target, see
+	// AuditEvent.Redact, and returns how many events it modified.
+	Erase(ctx context.Context, target string, fields []string) (int, error)
+}
+
+type AuditUsecase interface {
+	Query(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error)
+}
+
+// auditedFields are the user fields whose changes are recorded. Bookkeeping
+// fields such as UpdatedAt are left out, as the event itself records them.
+var auditedFields = []struct {
+	name  string
+	value func(u *User) any
+}{
+	{"username", func(u *User) any { return u.Username }},
+	{"email", func(u *User) any { return u.Email }},
+	{"role", func(u *User) any { return string(u.Role) }},
+	{"email_verified_at", func(u *User) any { return auditTime(u.EmailVerifiedAt) }},
+	{"deleted_at", func(u *User) any { return auditTime(u.DeletedAt) }},
+}
+
+// DiffUsers returns the audited fields that differ between before and
+// after, either of which may be nil. A password is only recorded as having
+// been set, never with its value.
+func DiffUsers(before, after *User) map[string]Change {
+	changes := make(map[string]Change)
+	for _, field := range auditedFields {
+		var b, a any
+		if before != nil {
+			b = field.value(before)
+		}
+		if after != nil {
+			a = field.value(after)
+		}
+		if b != a {
+			changes[field.name] = Change{Before: b, After: a}
+		}
+	}
+	if after != nil && after.Password != "" {
+		changes["password"] = Change{After: Redacted}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func auditTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
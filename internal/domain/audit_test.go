@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffUsers(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := &User{ID: 1, Username: "john", Email: "john@example.com", Role: RoleUser, Version: 1}
+	renamed := *user
+	renamed.Username, renamed.Version, renamed.UpdatedAt = "johnny", 2, now
+	deleted := *user
+	deleted.DeletedAt = &now
+	withPassword := *user
+	withPassword.Password = "correct horse"
+
+	tests := []struct {
+		name          string
+		before, after *User
+		want          map[string]Change
+	}{
+		{name: "no change", before: user, after: user},
+		{
+			name:   "field changed",
+			before: user, after: &renamed,
+			want: map[string]Change{"username": {Before: "john", After: "johnny"}},
+		},
+		{
+			name:   "deleted",
+			before: user, after: &deleted,
+			want: map[string]Change{"deleted_at": {Before: nil, After: "2024-01-02T03:04:05Z"}},
+		},
+		{
+			name:  "created with password",
+			after: &withPassword,
+			want: map[string]Change{
+				"username": {After: "john"},
+				"email":    {After: "john@example.com"},
+				"role":     {After: "user"},
+				"password": {After: Redacted},
+			},
+		},
+		{
+			name:   "removed",
+			before: user,
+			want: map[string]Change{
+				"username": {Before: "john"},
+				"email":    {Before: "john@example.com"},
+				"role":     {Before: "user"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffUsers(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffUsers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditFilter_Normalize(t *testing.T) {
+	f := AuditFilter{}
+	if err := f.Normalize(); err != nil || f.Limit != DefaultListLimit {
+		t.Errorf("Normalize() = %+v, %v, want the default limit", f, err)
+	}
+	f = AuditFilter{Limit: MaxListLimit + 1}
+	if err := f.Normalize(); !errors.Is(err, ErrBadParamInput) {
+		t.Errorf("Normalize() error = %v, want %v", err, ErrBadParamInput)
+	}
+}
+
+func TestUserTarget(t *testing.T) {
+	if got := UserTarget(42); got != "user:42" {
+		t.Errorf("UserTarget() = %q, want user:42", got)
+	}
+}
+
+func TestAuditEvent_Redact(t *testing.T) {
+	event := &AuditEvent{
+		Changes: map[string]Change{
+			"username": {Before: "john", After: "johnny"},
+			"email":    {After: "john@example.com"},
+			"role":     {Before: "user", After: "admin"},
+		},
+		Salts: map[string]string{"username": "s1", "email": "s2", "role": "s3"},
+	}
+	if !event.Redact(PersonalFields) {
+		t.Fatal("Redact() = false, want true")
+	}
+	want := map[string]Change{
+		"username": {Before: Redacted, After: Redacted},
+		"email":    {After: Redacted},
+		"role":     {Before: "user", After: "admin"},
+	}
+	if !reflect.DeepEqual(event.Changes, want) || !reflect.DeepEqual(event.Salts, map[string]string{"role": "s3"}) {
+		t.Errorf("Redact() left %+v, salts %v, want only the role salt", event.Changes, event.Salts)
+	}
+
+	if event.Redact(PersonalFields) || len(event.Salts) != 1 {
+		t.Errorf("Redact() of a redacted event changed it")
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+	// RoleService is a read-only role for other services.
+	RoleService Role = "service"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleUser, RoleService:
+		return true
+	default:
+		return false
+	}
+}
+
+type Action string
+
+const (
+	ActionCreate      Action = "create"
+	ActionRead        Action = "read"
+	ActionList        Action = "list"
+	ActionUpdate      Action = "update"
+	ActionDelete      Action = "delete"
+	ActionRestore     Action = "restore"
+	ActionReadDeleted Action = "read_deleted"
+	ActionAssignRole  Action = "assign_role"
+	ActionManageKeys  Action = "manage_api_keys"
+	ActionReadAudit   Action = "read_audit"
+	ActionExport      Action = "export"
+	ActionErase       Action = "erase"
+)
+
+// Authorizer decides whether a principal may perform an action. Target is
+// the user acted upon, or nil for actions on the collection. It returns
+// ErrUnauthenticated or ErrForbidden when the action is denied.
+type Authorizer interface {
+	Authorize(p Principal, action Action, target *User) error
+}
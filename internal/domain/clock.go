@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Clock abstracts the current time so that timestamps can be controlled in
+// tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemClock_Now(t *testing.T) {
+	before := time.Now()
+	got := SystemClock{}.Now()
+	after := time.Now()
+
+	if got.Location() != time.UTC {
+		t.Errorf("SystemClock.Now() location = %v, want UTC", got.Location())
+	}
+	if got.Before(before.Truncate(time.Second)) || got.After(after) {
+		t.Errorf("SystemClock.Now() = %v, want between %v and %v", got, before, after)
+	}
+}
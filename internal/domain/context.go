@@ -0,0 +1,52 @@
+package domain
+
+import "context"
+
+type (
+	expectedVersionKey struct{}
+	includeDeletedKey  struct{}
+	requestIDKey       struct{}
+)
+
+// WithExpectedVersion makes writes issued with the returned context conditional
+// on the stored user still being at version. Repositories must check it in the
+// same critical section or transaction as the write itself.
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+func ExpectedVersion(ctx context.Context) (int64, bool) {
+	version, ok := ctx.Value(expectedVersionKey{}).(int64)
+	return version, ok
+}
+
+// CheckVersion returns ErrVersionConflict when ctx carries an expected version
+// that differs from current.
+func CheckVersion(ctx context.Context, current int64) error {
+	if expected, ok := ExpectedVersion(ctx); ok && expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// WithDeleted makes reads issued with the returned context also return
+// soft-deleted users.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+func IncludeDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
+// WithRequestID tags work done with the returned context with the ID of the
+// request it serves.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		current int64
+		wantErr error
+	}{
+		{name: "no precondition", ctx: context.Background(), current: 3},
+		{name: "matching version", ctx: WithExpectedVersion(context.Background(), 3), current: 3},
+		{name: "stale version", ctx: WithExpectedVersion(context.Background(), 2), current: 3, wantErr: ErrVersionConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckVersion(tt.ctx, tt.current); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckVersion() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	if _, ok := ExpectedVersion(context.Background()); ok {
+		t.Errorf("ExpectedVersion() on empty context reported a version")
+	}
+	if v, ok := ExpectedVersion(WithExpectedVersion(context.Background(), 7)); !ok || v != 7 {
+		t.Errorf("ExpectedVersion() = %d, %v, want 7, true", v, ok)
+	}
+}
+
+func TestIncludeDeleted(t *testing.T) {
+	if IncludeDeleted(context.Background()) {
+		t.Errorf("IncludeDeleted() on empty context = true")
+	}
+	if !IncludeDeleted(WithDeleted(context.Background())) {
+		t.Errorf("IncludeDeleted() after WithDeleted = false")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(context.Background()); id != "" {
+		t.Errorf("RequestID() on empty context = %q", id)
+	}
+	if id := RequestID(WithRequestID(context.Background(), "abc")); id != "abc" {
+		t.Errorf("RequestID() = %q, want abc", id)
+	}
+}
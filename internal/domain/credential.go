@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Credential is the stored password hash of a user. It never leaves the
+// server and is kept apart from User so it cannot be serialized by accident.
+type Credential struct {
+	UserID       int64
+	PasswordHash string
+	UpdatedAt    time.Time
+}
+
+type CredentialRepository interface {
+	Get(ctx context.Context, userID int64) (*Credential, error)
+	Set(ctx context.Context, cred *Credential) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// PasswordHasher produces and checks encoded password hashes. Verify reports
+// needsRehash when the hash was produced with an algorithm or parameters other
+// than the current ones.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (ok bool, needsRehash bool, err error)
+}
+
+type PasswordPolicy interface {
+	Check(password string) error
+}
+
+type AuthUsecase interface {
+	Login(ctx context.Context, username, password string) (*LoginResult, error)
+	// CompleteLogin finishes a login that requires a second factor.
+	CompleteLogin(ctx context.Context, mfaToken, code string) (*User, error)
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound           = errors.New("user not found")
+	ErrConflict           = errors.New("user already exists")
+	ErrBadParamInput      = errors.New("given param is not valid")
+	ErrImmutableField     = errors.New("field is immutable")
+	ErrVersionConflict    = errors.New("user version does not match")
+	ErrNotDeleted         = errors.New("user is not deleted")
+	ErrCredentialNotFound = errors.New("credential not found")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrWeakPassword       = errors.New("password does not meet policy")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrTokenReused        = errors.New("refresh token was already used")
+	ErrUnauthenticated    = errors.New("authentication required")
+	ErrForbidden          = errors.New("permission denied")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidAPIKey      = errors.New("invalid or revoked api key")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not set up")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode     = errors.New("invalid two-factor code")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrJobNotFound        = errors.New("job not found")
+)
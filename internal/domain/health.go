@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	HealthOK   HealthStatus = "ok"
+	HealthFail HealthStatus = "fail"
+)
+
+// HealthChecker is implemented by dependencies the server cannot serve
+// requests without, such as databases.
+type HealthChecker interface {
+	// CheckHealth returns an error if the dependency is unusable.
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthFunc adapts a function to a HealthChecker.
+type HealthFunc func(ctx context.Context) error
+
+func (f HealthFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+type CheckResult struct {
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	// Duration is how long the check took, such as "1.5ms".
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport is the outcome of every check. Its status is ok only if every
+// check passed.
+type HealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type HealthUsecase interface {
+	// Ready reports whether the server can serve requests.
+	Ready(ctx context.Context) *HealthReport
+}
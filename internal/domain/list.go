@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByUsername  SortField = "username"
+	SortByCreatedAt SortField = "created_at"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByID, SortByUsername, SortByCreatedAt:
+		return true
+	default:
+		return false
+	}
+}
+
+type ListOptions struct {
+	Limit          int
+	Cursor         string
+	SortBy         SortField
+	Descending     bool
+	UsernamePrefix string
+	EmailDomain    string
+	IncludeDeleted bool
+}
+
+// Normalize fills in defaults and rejects options no repository can serve.
+func (o *ListOptions) Normalize() error {
+	if o.SortBy == "" {
+		o.SortBy = SortByID
+	}
+	if !o.SortBy.Valid() {
+		return fmt.Errorf("%w: unknown sort field %q", ErrBadParamInput, o.SortBy)
+	}
+	if o.Limit < 0 || o.Limit > MaxListLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrBadParamInput, MaxListLimit)
+	}
+	if o.Limit == 0 {
+		o.Limit = DefaultListLimit
+	}
+	o.EmailDomain = strings.ToLower(strings.TrimPrefix(o.EmailDomain, "@"))
+	return nil
+}
+
+// Matches reports whether user passes the options' filters.
+func (o ListOptions) Matches(user *User) bool {
+	if o.UsernamePrefix != "" && !strings.HasPrefix(user.Username, o.UsernamePrefix) {
+		return false
+	}
+	if o.EmailDomain != "" {
+		_, domain, ok := strings.Cut(user.Email, "@")
+		if !ok || strings.ToLower(domain) != o.EmailDomain {
+			return false
+		}
+	}
+	return true
+}
+
+type UserPage struct {
+	Users      []*User
+	NextCursor string
+}
+
+// ListCursor is the position after the last user of a page. It is handed to
+// clients as an opaque string and bound to the sort order it was issued for.
+type ListCursor struct {
+	SortBy     SortField `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Key        string    `json:"k,omitempty"`
+	ID         int64     `json:"i"`
+}
+
+// CursorAfter returns the cursor positioned just after user.
+func CursorAfter(user *User, opts ListOptions) ListCursor {
+	c := ListCursor{SortBy: opts.SortBy, Descending: opts.Descending, ID: user.ID}
+	switch opts.SortBy {
+	case SortByUsername:
+		c.Key = user.Username
+	case SortByCreatedAt:
+		c.Key = user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
+}
+
+// Pivot rebuilds the sort-relevant fields of the user the cursor points after.
+func (c ListCursor) Pivot() (*User, error) {
+	u := &User{ID: c.ID}
+	switch c.SortBy {
+	case SortByUsername:
+		u.Username = c.Key
+	case SortByCreatedAt:
+		t, err := time.Parse(time.RFC3339Nano, c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", ErrBadParamInput)
+		}
+		u.CreatedAt = t
+	}
+	return u, nil
+}
+
+func (c ListCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor and checks that it belongs to opts' ordering.
+func DecodeCursor(cursor string, opts ListOptions) (ListCursor, error) {
+	var c ListCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", ErrBadParamInput)
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", ErrBadParamInput)
+	}
+	if c.SortBy != opts.SortBy || c.Descending != opts.Descending {
+		return c, fmt.Errorf("%w: cursor does not match sort order", ErrBadParamInput)
+	}
+	return c, nil
+}
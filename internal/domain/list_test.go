@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestListOptions_Normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ListOptions
+		want    ListOptions
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			opts: ListOptions{},
+			want: ListOptions{Limit: DefaultListLimit, SortBy: SortByID},
+		},
+		{
+			name: "email domain is lowercased without @",
+			opts: ListOptions{Limit: 5, SortBy: SortByUsername, EmailDomain: "@Example.COM"},
+			want: ListOptions{Limit: 5, SortBy: SortByUsername, EmailDomain: "example.com"},
+		},
+		{
+			name:    "unknown sort field",
+			opts:    ListOptions{SortBy: "email"},
+			wantErr: true,
+		},
+		{
+			name:    "limit too large",
+			opts:    ListOptions{Limit: MaxListLimit + 1},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			opts:    ListOptions{Limit: -1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListOptions.Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrBadParamInput) {
+					t.Errorf("ListOptions.Normalize() error = %v, want wrapped %v", err, ErrBadParamInput)
+				}
+				return
+			}
+			if tt.opts != tt.want {
+				t.Errorf("ListOptions.Normalize() = %+v, want %+v", tt.opts, tt.want)
+			}
+		})
+	}
+}
+
+func TestListOptions_Matches(t *testing.T) {
+	user := &User{Username: "alice", Email: "alice@Example.com"}
+	tests := []struct {
+		name string
+		opts ListOptions
+		want bool
+	}{
+		{name: "no filters", opts: ListOptions{}, want: true},
+		{name: "prefix matches", opts: ListOptions{UsernamePrefix: "al"}, want: true},
+		{name: "prefix does not match", opts: ListOptions{UsernamePrefix: "bo"}, want: false},
+		{name: "domain matches", opts: ListOptions{EmailDomain: "example.com"}, want: true},
+		{name: "domain does not match", opts: ListOptions{EmailDomain: "example.org"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Matches(user); got != tt.want {
+				t.Errorf("ListOptions.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCursor_RoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	user := &User{ID: 42, Username: "alice", CreatedAt: created}
+	opts := ListOptions{SortBy: SortByCreatedAt, Descending: true}
+
+	encoded := CursorAfter(user, opts).Encode()
+	cursor, err := DecodeCursor(encoded, opts)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	pivot, err := cursor.Pivot()
+	if err != nil {
+		t.Fatalf("ListCursor.Pivot() error = %v", err)
+	}
+	if pivot.ID != user.ID || !pivot.CreatedAt.Equal(created) {
+		t.Errorf("ListCursor.Pivot() = %+v, want id %d created %v", pivot, user.ID, created)
+	}
+
+	if _, err := DecodeCursor(encoded, ListOptions{SortBy: SortByCreatedAt}); !errors.Is(err, ErrBadParamInput) {
+		t.Errorf("DecodeCursor() with other direction error = %v, want %v", err, ErrBadParamInput)
+	}
+	if _, err := DecodeCursor("not a cursor", opts); !errors.Is(err, ErrBadParamInput) {
+		t.Errorf("DecodeCursor() malformed error = %v, want %v", err, ErrBadParamInput)
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const PurposeMFALogin TokenPurpose = "mfa_login"
+
+// MFAEnrollment is a user's TOTP second factor. It only takes effect once
+// confirmed with a code, which proves the user's authenticator works.
+type MFAEnrollment struct {
+	UserID int64
+	// Secret is the shared TOTP key.
+	Secret      []byte
+	ConfirmedAt *time.Time
+	// LastStep is the last TOTP time step accepted, so a code cannot be
+	// replayed within its validity window.
+	LastStep int64
+	// RecoveryCodes holds hashes of the unused recovery codes.
+	RecoveryCodes []string
+}
+
+func (e *MFAEnrollment) Enabled() bool {
+	return e.ConfirmedAt != nil
+}
+
+type MFARepository interface {
+	Get(ctx context.Context, userID int64) (*MFAEnrollment, error)
+	Set(ctx context.Context, enrollment *MFAEnrollment) error
+	Delete(ctx context.Context, userID int64) error
+	// UseStep records step as used, or returns ErrInvalidMFACode if that
+	// step or a later one already was.
+	UseStep(ctx context.Context, userID int64, step int64) error
+	// UseRecoveryCode removes the recovery code with the given hash, or
+	// returns ErrInvalidMFACode if there is none.
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) error
+}
+
+// TOTP generates and checks RFC 6238 codes.
+type TOTP interface {
+	GenerateSecret() ([]byte, error)
+	// URI returns the otpauth:// URI authenticator apps enroll from.
+	URI(account string, secret []byte) string
+	// Validate reports whether code is valid at the given time, allowing for
+	// clock skew, and returns the time step it belongs to.
+	Validate(secret []byte, code string, at time.Time) (step int64, ok bool)
+}
+
+type MFASetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type MFAUsecase interface {
+	// Enroll starts a new enrollment, replacing an unconfirmed one.
+	Enroll(ctx context.Context, userID int64) (*MFASetup, error)
+	// Confirm enables the enrollment and returns the recovery codes, which
+	// are shown only this once.
+	Confirm(ctx context.Context, userID int64, code string) ([]string, error)
+	Disable(ctx context.Context, userID int64, code string) error
+	Enabled(ctx context.Context, userID int64) (bool, error)
+	// Verify checks a TOTP or recovery code as a second login factor.
+	Verify(ctx context.Context, userID int64, code string) error
+}
+
+// LoginResult is the outcome of the first login step. If MFAToken is set,
+// the login has to be completed with a second factor.
+type LoginResult struct {
+	User     *User
+	MFAToken string
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const userSubjectPrefix = "user:"
+
+// Principal identifies who is making a request. Transports authenticate the
+// caller and attach the principal to the request context.
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    Role   `json:"role,omitempty"`
+	// Scopes restrict what the principal may do beyond its role. Nil means
+	// unrestricted, as for users who logged in.
+	Scopes []Scope `json:"scopes,omitempty"`
+}
+
+// UserPrincipal returns the principal of an authenticated user.
+func UserPrincipal(id int64, role Role) Principal {
+	return Principal{Subject: userSubjectPrefix + strconv.FormatInt(id, 10), Role: role}
+}
+
+// UserID returns the ID of the user the principal stands for, if any.
+func (p Principal) UserID() (int64, bool) {
+	rest, ok := strings.CutPrefix(p.Subject, userSubjectPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	return id, err == nil
+}
+
+// HasScope reports whether the principal's scopes allow s.
+func (p Principal) HasScope(s Scope) bool {
+	return p.Scopes == nil || slices.Contains(p.Scopes, s)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Actor returns the subject recorded as the author of a change, or an empty
+// string for unauthenticated callers.
+func Actor(ctx context.Context) string {
+	p, _ := PrincipalFrom(ctx)
+	return p.Subject
+}
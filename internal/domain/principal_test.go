@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPrincipal(t *testing.T) {
+	if _, ok := PrincipalFrom(context.Background()); ok {
+		t.Errorf("PrincipalFrom() on empty context reported a principal")
+	}
+	if got := Actor(context.Background()); got != "" {
+		t.Errorf("Actor() on empty context = %q, want empty", got)
+	}
+
+	ctx := WithPrincipal(context.Background(), Principal{Subject: "user:1"})
+	if p, ok := PrincipalFrom(ctx); !ok || p.Subject != "user:1" {
+		t.Errorf("PrincipalFrom() = %+v, %v, want user:1", p, ok)
+	}
+	if got := Actor(ctx); got != "user:1" {
+		t.Errorf("Actor() = %q, want user:1", got)
+	}
+}
+
+func TestUserPrincipal(t *testing.T) {
+	p := UserPrincipal(42, RoleAdmin)
+	if p.Subject != "user:42" || p.Role != RoleAdmin {
+		t.Errorf("UserPrincipal() = %+v, want user:42 with role admin", p)
+	}
+	if id, ok := p.UserID(); !ok || id != 42 {
+		t.Errorf("UserID() = %d, %v, want 42, true", id, ok)
+	}
+
+	for _, subject := range []string{"", "service:42", "user:", "user:abc"} {
+		if _, ok := (Principal{Subject: subject}).UserID(); ok {
+			t.Errorf("UserID() of %q reported a user", subject)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ErasureStatus string
+
+const (
+	ErasurePending   ErasureStatus = "pending"
+	ErasureRunning   ErasureStatus = "running"
+	ErasureCompleted ErasureStatus = "completed"
+	ErasureFailed    ErasureStatus = "failed"
+)
+
+// ErasureJob tracks the erasure of a user's personal data, which runs in the
+// background once requested.
+type ErasureJob struct {
+	ID          string        `json:"id"`
+	UserID      int64         `json:"user_id"`
+	Status      ErasureStatus `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	RequestedBy string        `json:"requested_by,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
+
+type ErasureJobRepository interface {
+	Create(ctx context.Context, job *ErasureJob) error
+	Get(ctx context.Context, id string) (*ErasureJob, error)
+	Update(ctx context.Context, job *ErasureJob) error
+}
+
+// UserExport is everything held about a user. Secrets are described, never
+// included.
+type UserExport struct {
+	ExportedAt  time.Time     `json:"exported_at"`
+	User        *User         `json:"user"`
+	Password    *PasswordInfo `json:"password,omitempty"`
+	MFA         *MFAInfo      `json:"mfa,omitempty"`
+	Sessions    []SessionInfo `json:"sessions"`
+	APIKeys     []*APIKey     `json:"api_keys"`
+	AuditEvents []*AuditEvent `json:"audit_events"`
+}
+
+type PasswordInfo struct {
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MFAInfo struct {
+	Enabled             bool       `json:"enabled"`
+	ConfirmedAt         *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesUnused int        `json:"recovery_codes_unused"`
+}
+
+// SessionInfo describes a login session by the refresh token currently
+// redeemable in it.
+type SessionInfo struct {
+	Family    string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PrivacyUsecase interface {
+	Export(ctx context.Context, userID int64) (*UserExport, error)
+	// RequestErasure starts erasing the user's personal data and returns the
+	// job tracking it.
+	RequestErasure(ctx context.Context, userID int64) (*ErasureJob, error)
+	GetErasure(ctx context.Context, jobID string) (*ErasureJob, error)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RateLimit is the outcome of a rate limit check.
+type RateLimit struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the limit is fully replenished.
+	Reset time.Duration
+	// RetryAfter is how long to wait before a request is allowed again. It
+	// is zero when Allowed is true.
+	RetryAfter time.Duration
+}
+
+// RateLimiter counts a request against the limit for key.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (RateLimit, error)
+}
+
+// RateLimitStore holds rate limit state, possibly shared between instances.
+// Each method is a single atomic operation: the Redis store implements them
+// with SET NX PX and INCRBY in one transaction, GET, SET PX, DEL and a
+// compare-and-set script. Missing or expired keys read as zero.
+type RateLimitStore interface {
+	// Incr adds delta to the counter at key and returns the new value. The
+	// ttl is only applied when the key is created.
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	Get(ctx context.Context, key string) (int64, error)
+	Set(ctx context.Context, key string, value int64, ttl time.Duration) error
+	// CompareAndSwap sets key to new if it currently holds old.
+	CompareAndSwap(ctx context.Context, key string, old, new int64, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LoginThrottle locks out a key, such as a username, after repeated failed
+// logins, for longer with every further failure.
+type LoginThrottle interface {
+	// Check returns how long key is still locked out, or zero.
+	Check(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt and returns the lockout it triggered, if
+	// any.
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failed attempts after a successful login.
+	Reset(ctx context.Context, key string) error
+}
+
+// RetryAfterError reports that a request was refused for being too frequent.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v, retry after %v", ErrTooManyRequests, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return ErrTooManyRequests
+}
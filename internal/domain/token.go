@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TokenPair is handed to a client after it authenticates. The access token is
+// a short-lived bearer token; the refresh token is opaque and single-use.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken is the server-side record of an issued refresh token. Only a
+// hash of the token is stored. Tokens rotated from the same login share a
+// Family, so that a replayed token can revoke the whole session.
+type RefreshToken struct {
+	Hash      string
+	UserID    int64
+	Family    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RotatedAt *time.Time
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	Get(ctx context.Context, hash string) (*RefreshToken, error)
+	// Rotate marks the token with hash as used and stores next in one step.
+	// It returns ErrTokenReused if the token had already been rotated.
+	Rotate(ctx context.Context, hash string, at time.Time, next *RefreshToken) error
+	RevokeFamily(ctx context.Context, family string) error
+	// ListByUser returns the user's tokens that have not been rotated, one
+	// per session, possibly expired.
+	ListByUser(ctx context.Context, userID int64) ([]*RefreshToken, error)
+	// RevokeUser ends every session of the user.
+	RevokeUser(ctx context.Context, userID int64) error
+}
+
+// AccessTokenIssuer signs and verifies access tokens.
+type AccessTokenIssuer interface {
+	Issue(user *User) (token string, expiresAt time.Time, err error)
+	Verify(token string) (Principal, error)
+}
+
+type TokenUsecase interface {
+	Issue(ctx context.Context, user *User) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Revoke ends the session the refresh token belongs to.
+	Revoke(ctx context.Context, refreshToken string) error
+	Authenticate(ctx context.Context, accessToken string) (Principal, error)
+}
@@ -1,21 +1,164 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"time"
+	"unicode/utf8"
+)
+
+const maxUsernameLength = 64
 
 type User struct {
 	ID       int64  `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     Role   `json:"role,omitempty"`
+	// EmailVerifiedAt is set once the user proves they own Email, and cleared
+	// whenever Email changes.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CreatedBy       string     `json:"created_by,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	UpdatedBy       string     `json:"updated_by,omitempty"`
+	Version         int64      `json:"version"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	// Password is only set on registration input. The usecase hashes it into
+	// a Credential and clears it before the user is stored.
+	Password string `json:"-"`
+}
+
+// LogValue logs a user by ID, username and role, leaving out personal data
+// and the password.
+func (u *User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("id", u.ID),
+		slog.String("username", u.Username),
+		slog.String("role", string(u.Role)),
+	)
+}
+
+// Validate checks the fields a client is allowed to set. It is applied on
+// every write, so a patch cannot leave a user in a state Register would reject.
+func (u *User) Validate() error {
+	if u.Username == "" {
+		return fmt.Errorf("%w: username is required", ErrBadParamInput)
+	}
+	if utf8.RuneCountInString(u.Username) > maxUsernameLength {
+		return fmt.Errorf("%w: username must be at most %d characters", ErrBadParamInput, maxUsernameLength)
+	}
+	if u.Email == "" {
+		return fmt.Errorf("%w: email is required", ErrBadParamInput)
+	}
+	if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		return fmt.Errorf("%w: email is invalid", ErrBadParamInput)
+	}
+	if u.Role != "" && !u.Role.Valid() {
+		return fmt.Errorf("%w: unknown role %q", ErrBadParamInput, u.Role)
+	}
+	return nil
+}
+
+// Anonymize replaces the personal data in u with placeholders and marks it
+// deleted. The ID stays, so that references to the user still resolve.
+func (u *User) Anonymize(at time.Time) {
+	u.Username = fmt.Sprintf("erased-%d", u.ID)
+	u.Email = fmt.Sprintf("erased-%d@erased.invalid", u.ID)
+	u.EmailVerifiedAt = nil
+	u.UpdatedAt = at
+	if u.DeletedAt == nil {
+		u.DeletedAt = &at
+	}
+}
+
+// Clone returns a copy of u that shares no memory with it, so that
+// repositories can hand out users without callers reaching their state.
+func (u *User) Clone() *User {
+	c := *u
+	c.EmailVerifiedAt = cloneTime(u.EmailVerifiedAt)
+	c.DeletedAt = cloneTime(u.DeletedAt)
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// ChangedServerField returns the JSON name of the first field managed by the
+// server that differs between u and other, or an empty string.
+func (u *User) ChangedServerField(other *User) string {
+	switch {
+	case u.ID != other.ID:
+		return "id"
+	case !u.CreatedAt.Equal(other.CreatedAt):
+		return "created_at"
+	case u.CreatedBy != other.CreatedBy:
+		return "created_by"
+	case !u.UpdatedAt.Equal(other.UpdatedAt):
+		return "updated_at"
+	case u.UpdatedBy != other.UpdatedBy:
+		return "updated_by"
+	case u.Version != other.Version:
+		return "version"
+	case !timePtrEqual(u.EmailVerifiedAt, other.EmailVerifiedAt):
+		return "email_verified_at"
+	case !timePtrEqual(u.DeletedAt, other.DeletedAt):
+		return "deleted_at"
+	default:
+		return ""
+	}
+}
+
+func timePtrEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// UserPatch describes a partial modification of a user, such as a JSON Merge
+// Patch or a JSON Patch document.
+type UserPatch interface {
+	Apply(user *User) error
 }
 
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByID(ctx context.Context, id int64) (*User, error)
-	Delete(ctx context.Context, id int64) error
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	Update(ctx context.Context, user *User) error
+	// Delete soft-deletes the user as of at. Its ID, username and email stay
+	// reserved until it is restored or purged.
+	Delete(ctx context.Context, id int64, at time.Time) error
+	List(ctx context.Context, opts ListOptions) (*UserPage, error)
+	Restore(ctx context.Context, id int64) (*User, error)
+	// VerifyEmail marks the user's email as verified at the given time if it
+	// is still email, and returns ErrConflict otherwise.
+	VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error
+	// Purge permanently removes users soft-deleted before cutoff and returns
+	// how many were removed.
+	Purge(ctx context.Context, cutoff time.Time) (int, error)
+	// Anonymize erases the personal data of the user, deleted or not, see
+	// User.Anonymize, and returns the result.
+	Anonymize(ctx context.Context, id int64, at time.Time) (*User, error)
 }
 
 type UserUsecase interface {
 	Register(ctx context.Context, user *User) error
 	GetUser(ctx context.Context, id int64) (*User, error)
+	UpdateUser(ctx context.Context, user *User) error
+	PatchUser(ctx context.Context, id int64, patch UserPatch) (*User, error)
 	DeleteUser(ctx context.Context, id int64) error
+	ListUsers(ctx context.Context, opts ListOptions) (*UserPage, error)
+	RestoreUser(ctx context.Context, id int64) (*User, error)
 }
@@ -4,13 +4,22 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockUserRepository struct {
-	createFunc  func(ctx context.Context, user *User) error
-	getByIDFunc func(ctx context.Context, id int64) (*User, error)
-	deleteFunc  func(ctx context.Context, id int64) error
+	createFunc      func(ctx context.Context, user *User) error
+	getByIDFunc     func(ctx context.Context, id int64) (*User, error)
+	getByNameFunc   func(ctx context.Context, username string) (*User, error)
+	updateFunc      func(ctx context.Context, user *User) error
+	deleteFunc      func(ctx context.Context, id int64, at time.Time) error
+	listFunc        func(ctx context.Context, opts ListOptions) (*UserPage, error)
+	restoreFunc     func(ctx context.Context, id int64) (*User, error)
+	verifyEmailFunc func(ctx context.Context, id int64, email string, at time.Time) error
+	purgeFunc       func(ctx context.Context, cutoff time.Time) (int, error)
+	anonymizeFunc   func(ctx context.Context, id int64, at time.Time) (*User, error)
 }
 
 func (m *mockUserRepository) Create(ctx context.Context, user *User) error {
@@ -21,8 +30,36 @@ func (m *mockUserRepository) GetByID(ctx context.Context, id int64) (*User, erro
 	return m.getByIDFunc(ctx, id)
 }
 
-func (m *mockUserRepository) Delete(ctx context.Context, id int64) error {
-	return m.deleteFunc(ctx, id)
+func (m *mockUserRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return m.getByNameFunc(ctx, username)
+}
+
+func (m *mockUserRepository) Update(ctx context.Context, user *User) error {
+	return m.updateFunc(ctx, user)
+}
+
+func (m *mockUserRepository) Delete(ctx context.Context, id int64, at time.Time) error {
+	return m.deleteFunc(ctx, id, at)
+}
+
+func (m *mockUserRepository) List(ctx context.Context, opts ListOptions) (*UserPage, error) {
+	return m.listFunc(ctx, opts)
+}
+
+func (m *mockUserRepository) VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error {
+	return m.verifyEmailFunc(ctx, id, email, at)
+}
+
+func (m *mockUserRepository) Restore(ctx context.Context, id int64) (*User, error) {
+	return m.restoreFunc(ctx, id)
+}
+
+func (m *mockUserRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	return m.purgeFunc(ctx, cutoff)
+}
+
+func (m *mockUserRepository) Anonymize(ctx context.Context, id int64, at time.Time) (*User, error) {
+	return m.anonymizeFunc(ctx, id, at)
 }
 
 type userUsecase struct {
@@ -43,8 +80,31 @@ func (u *userUsecase) GetUser(ctx context.Context, id int64) (*User, error) {
 	return u.userRepo.GetByID(ctx, id)
 }
 
+func (u *userUsecase) UpdateUser(ctx context.Context, user *User) error {
+	return u.userRepo.Update(ctx, user)
+}
+
+func (u *userUsecase) PatchUser(ctx context.Context, id int64, patch UserPatch) (*User, error) {
+	user, err := u.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := patch.Apply(user); err != nil {
+		return nil, err
+	}
+	return user, u.userRepo.Update(ctx, user)
+}
+
 func (u *userUsecase) DeleteUser(ctx context.Context, id int64) error {
-	return u.userRepo.Delete(ctx, id)
+	return u.userRepo.Delete(ctx, id, time.Now())
+}
+
+func (u *userUsecase) ListUsers(ctx context.Context, opts ListOptions) (*UserPage, error) {
+	return u.userRepo.List(ctx, opts)
+}
+
+func (u *userUsecase) RestoreUser(ctx context.Context, id int64) (*User, error) {
+	return u.userRepo.Restore(ctx, id)
 }
 
 func TestUserUsecase_Register(t *testing.T) {
@@ -218,7 +278,7 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 			name: "success",
 			fields: fields{
 				userRepo: &mockUserRepository{
-					deleteFunc: func(ctx context.Context, id int64) error {
+					deleteFunc: func(ctx context.Context, id int64, at time.Time) error {
 						return nil
 					},
 				},
@@ -233,7 +293,7 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 			name: "failure - repo error",
 			fields: fields{
 				userRepo: &mockUserRepository{
-					deleteFunc: func(ctx context.Context, id int64) error {
+					deleteFunc: func(ctx context.Context, id int64, at time.Time) error {
 						return errors.New("repo error")
 					},
 				},
@@ -263,3 +323,123 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    User
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			user: User{Username: "test", Email: "test@example.com"},
+		},
+		{
+			name:    "missing username",
+			user:    User{Email: "test@example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "username too long",
+			user:    User{Username: strings.Repeat("a", maxUsernameLength+1), Email: "test@example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "missing email",
+			user:    User{Username: "test"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid email",
+			user:    User{Username: "test", Email: "not-an-email"},
+			wantErr: true,
+		},
+		{
+			name: "valid role",
+			user: User{Username: "test", Email: "test@example.com", Role: RoleService},
+		},
+		{
+			name:    "unknown role",
+			user:    User{Username: "test", Email: "test@example.com", Role: "root"},
+			wantErr: true,
+		},
+		{
+			name:    "email with display name",
+			user:    User{Username: "test", Email: "Test <test@example.com>"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.user.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("User.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrBadParamInput) {
+				t.Errorf("User.Validate() error = %v, want wrapped %v", err, ErrBadParamInput)
+			}
+		})
+	}
+}
+
+func TestUser_ChangedServerField(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := User{ID: 1, Username: "test", Email: "test@example.com", CreatedAt: now, UpdatedAt: now, Version: 2}
+	tests := []struct {
+		name   string
+		mutate func(u *User)
+		want   string
+	}{
+		{name: "client fields only", mutate: func(u *User) { u.Username, u.Email = "other", "other@example.com" }, want: ""},
+		{name: "id", mutate: func(u *User) { u.ID = 2 }, want: "id"},
+		{name: "created_at", mutate: func(u *User) { u.CreatedAt = now.Add(time.Second) }, want: "created_at"},
+		{name: "created_by", mutate: func(u *User) { u.CreatedBy = "user:1" }, want: "created_by"},
+		{name: "updated_at", mutate: func(u *User) { u.UpdatedAt = now.Add(time.Second) }, want: "updated_at"},
+		{name: "updated_by", mutate: func(u *User) { u.UpdatedBy = "user:1" }, want: "updated_by"},
+		{name: "version", mutate: func(u *User) { u.Version = 3 }, want: "version"},
+		{name: "email_verified_at", mutate: func(u *User) { u.EmailVerifiedAt = &now }, want: "email_verified_at"},
+		{name: "deleted_at", mutate: func(u *User) { u.DeletedAt = &now }, want: "deleted_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.mutate(&changed)
+			if got := changed.ChangedServerField(&base); got != tt.want {
+				t.Errorf("User.ChangedServerField() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUser_Anonymize(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := &User{ID: 42, Username: "john", Email: "john@example.com", EmailVerifiedAt: &at, Role: RoleAdmin}
+	u.Anonymize(at)
+
+	if u.Username != "erased-42" || u.Email != "erased-42@erased.invalid" || u.EmailVerifiedAt != nil ||
+		u.DeletedAt == nil || !u.UpdatedAt.Equal(at) || u.ID != 42 || u.Role != RoleAdmin {
+		t.Errorf("Anonymize() = %+v", u)
+	}
+	if err := u.Validate(); err != nil {
+		t.Errorf("Validate() of an anonymized user error = %v", err)
+	}
+}
+
+func TestUser_Clone(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := &User{ID: 42, Username: "john", EmailVerifiedAt: &at, DeletedAt: &at}
+	c := u.Clone()
+	if !reflect.DeepEqual(c, u) {
+		t.Errorf("Clone() = %+v, want %+v", c, u)
+	}
+
+	c.Username = "jane"
+	*c.EmailVerifiedAt = at.Add(time.Hour)
+	*c.DeletedAt = at.Add(time.Hour)
+	if u.Username != "john" || !u.EmailVerifiedAt.Equal(at) || !u.DeletedAt.Equal(at) {
+		t.Errorf("changing the clone changed the original: %+v", u)
+	}
+	if (&User{}).Clone().EmailVerifiedAt != nil {
+		t.Error("Clone() of a user without times set them")
+	}
+}
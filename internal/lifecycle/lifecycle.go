@@ -0,0 +1,125 @@
+// Package lifecycle shuts the components of a server down in order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Manager runs the shutdown hooks that components register. Hooks run in the
+// reverse order of registration, so registering each component after the
+// ones it depends on stops it before them.
+type Manager struct {
+	mu      sync.Mutex
+	hooks   []hook
+	timeout time.Duration
+}
+
+// New returns a manager that gives the hooks timeout to finish in all.
+func New(timeout time.Duration) *Manager {
+	return &Manager{timeout: timeout}
+}
+
+// OnShutdown registers stop to run on shutdown.
+func (m *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Close registers v's Close method to run on shutdown, if it has one.
+func (m *Manager) Close(name string, v any) {
+	if c, ok := v.(io.Closer); ok {
+		m.OnShutdown(name, func(context.Context) error { return c.Close() })
+	}
+}
+
+// Run calls serve and waits until it returns or ctx is done, typically on a
+// signal, then shuts down. serve should return once the server it runs is
+// stopped by a hook. Run returns serve's error, if it failed on its own, or
+// the errors of the hooks.
+func (m *Manager) Run(ctx context.Context, serve func() error) error {
+	served := make(chan error, 1)
+	go func() { served <- serve() }()
+
+	var serveErr error
+	select {
+	case serveErr = <-served:
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	err := m.Shutdown(shutdownCtx)
+	if serveErr != nil {
+		return errors.Join(serveErr, err)
+	}
+	return err
+}
+
+// Shutdown runs every hook, latest first, even if some fail or ctx is done,
+// and returns their errors.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Group tracks goroutines so that shutdown can wait for them.
+type Group struct {
+	wg      sync.WaitGroup
+	running atomic.Int64
+}
+
+// Go runs fn in a goroutine.
+func (g *Group) Go(fn func()) {
+	g.wg.Add(1)
+	g.running.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.running.Add(-1)
+		fn()
+	}()
+}
+
+// Running returns how many goroutines started by Go have not returned.
+func (g *Group) Running() int {
+	return int(g.running.Load())
+}
+
+// Wait waits for every goroutine started by Go to return, or for ctx to be
+// done.
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closer struct{ closed *[]string }
+
+func (c closer) Close() error {
+	*c.closed = append(*c.closed, "closer")
+	return nil
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	var stopped []string
+	m := New(time.Second)
+	record := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			stopped = append(stopped, name)
+			return err
+		}
+	}
+	m.OnShutdown("audit", record("audit", nil))
+	m.Close("repository", closer{&stopped})
+	m.Close("not a closer", struct{}{})
+	m.OnShutdown("jobs", record("jobs", io.ErrUnexpectedEOF))
+	m.OnShutdown("http", record("http", nil))
+
+	err := m.Shutdown(context.Background())
+	assert.Equal(t, []string{"http", "jobs", "closer", "audit"}, stopped)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.ErrorContains(t, err, "stop jobs")
+
+	stopped = nil
+	assert.NoError(t, m.Shutdown(context.Background()))
+	assert.Empty(t, stopped, "hooks run once")
+}
+
+func TestManager_Run(t *testing.T) {
+	t.Run("cancelled", func(t *testing.T) {
+		m := New(time.Second)
+		stop := make(chan struct{})
+		m.OnShutdown("server", func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok, "hooks get a deadline")
+			close(stop)
+			return nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := m.Run(ctx, func() error {
+			<-stop
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("serve fails", func(t *testing.T) {
+		m := New(time.Second)
+		var stopped bool
+		m.OnShutdown("audit", func(context.Context) error {
+			stopped = true
+			return nil
+		})
+		errListen := errors.New("address in use")
+		err := m.Run(context.Background(), func() error { return errListen })
+		assert.ErrorIs(t, err, errListen)
+		assert.True(t, stopped)
+	})
+}
+
+func TestGroup_Wait(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	g.Go(func() { <-release })
+	assert.Equal(t, 1, g.Running())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, g.Wait(ctx), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, g.Wait(context.Background()))
+	assert.Equal(t, 0, g.Running())
+}
@@ -0,0 +1,77 @@
+// Package logging sets up structured logging with log/slog. Records logged
+// with a context carry what the context knows about the work being done:
+// the request ID, the trace, the caller and attributes added with With.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"repo-guardian/internal/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type attrsKey struct{}
+
+// With returns ctx carrying attrs, given as to slog.Logger.With, for every
+// record logged with it.
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := append(attrsFrom(ctx), slog.Group("", args...).Value.Group()...)
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+func attrsFrom(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	// Copy, so that appending never shares the array of ctx's attributes.
+	return append([]slog.Attr(nil), attrs...)
+}
+
+// NewHandler returns a handler writing records to w as text or JSON lines.
+// Fields whose name mentions email are redacted, wherever they are logged.
+func NewHandler(w io.Writer, format string, level slog.Leveler) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	var h slog.Handler
+	if format == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+	return &contextHandler{Handler: h}
+}
+
+// redact hides personal data that ends up in log fields.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindString && strings.Contains(strings.ToLower(a.Key), "email") && a.Value.String() != "" {
+		a.Value = slog.StringValue(domain.Redacted)
+	}
+	return a
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := domain.RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	if actor := domain.Actor(ctx); actor != "" {
+		r.AddAttrs(slog.String("actor", actor))
+	}
+	r.AddAttrs(attrsFrom(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHandler_Context(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, "json", slog.LevelInfo))
+
+	ctx := domain.WithRequestID(context.Background(), "req-1")
+	ctx = domain.WithPrincipal(ctx, domain.UserPrincipal(7, domain.RoleUser))
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	ctx = With(ctx, "job_id", "j1")
+
+	logger.With("component", "test").InfoContext(ctx, "hello", "n", 1)
+	logger.DebugContext(ctx, "hidden")
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	delete(got, "time")
+	assert.Equal(t, map[string]any{
+		"level":      "INFO",
+		"msg":        "hello",
+		"component":  "test",
+		"n":          1.0,
+		"request_id": "req-1",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+		"actor":      "user:7",
+		"job_id":     "j1",
+	}, got)
+}
+
+func TestWith(t *testing.T) {
+	base := With(context.Background(), "a", 1)
+	first := With(base, "b", 2)
+	second := With(base, "c", 3)
+
+	assert.Equal(t, []slog.Attr{slog.Int("a", 1)}, attrsFrom(base))
+	assert.Equal(t, []slog.Attr{slog.Int("a", 1), slog.Int("b", 2)}, attrsFrom(first))
+	assert.Equal(t, []slog.Attr{slog.Int("a", 1), slog.Int("c", 3)}, attrsFrom(second))
+}
+
+func TestHandler_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, "text", slog.LevelInfo))
+
+	user := &domain.User{ID: 1, Username: "john", Email: "john@example.com", Role: domain.RoleAdmin, Password: "hunter2"}
+	logger.Info("registered", "user", user, "email", "john@example.com",
+		slog.Group("mail", slog.String("to_email", "jane@example.com")), "email_verified", false)
+
+	line := buf.String()
+	assert.NotContains(t, line, "@example.com")
+	assert.NotContains(t, line, "hunter2")
+	assert.Contains(t, line, "user.id=1 user.username=john user.role=admin")
+	assert.Contains(t, line, "email="+domain.Redacted)
+	assert.Contains(t, line, "mail.to_email="+domain.Redacted)
+	assert.Contains(t, line, "email_verified=false")
+	assert.True(t, strings.HasPrefix(line, "time="), line)
+}
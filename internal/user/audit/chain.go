@@ -0,0 +1,315 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// checkpointContext separates checkpoint signatures from anything else the
+// key might sign.
+const checkpointContext = "repo-guardian audit checkpoint\n"
+
+var errNotErasable = errors.New("audit: sink cannot erase events")
+
+type chainedSink struct {
+	mu         sync.Mutex
+	inner      domain.AuditSink
+	key        ed25519.PrivateKey
+	every      int64
+	lastHash   string
+	sinceCheck int64
+}
+
+// NewChainedSink hash-chains the events appended to inner and signs every
+// so many events with key as a checkpoint. A nil key disables checkpoints.
+// The chain continues from the last event already in inner. Only one
+// chained sink may append to inner at a time.
+func NewChainedSink(ctx context.Context, inner domain.AuditSink, key ed25519.PrivateKey, every int) (domain.AuditSink, error) {
+	s := &chainedSink{inner: inner, key: key, every: int64(every)}
+	err := walk(ctx, inner, func(event *domain.AuditEvent) error {
+		s.lastHash = event.Hash
+		s.sinceCheck++
+		if event.Signature != "" {
+			s.sinceCheck = 0
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *chainedSink) Append(ctx context.Context, event *domain.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Databases may store less precision, which would change the hash.
+	event.Time = event.Time.UTC().Truncate(time.Microsecond)
+	event.PrevHash = s.lastHash
+	event.Salts, event.ChangeHashes = nil, nil
+	for field, change := range event.Changes {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		encoded := base64.RawURLEncoding.EncodeToString(salt)
+		changeHash, err := ChangeHash(field, encoded, change)
+		if err != nil {
+			return err
+		}
+		if event.Salts == nil {
+			event.Salts = make(map[string]string, len(event.Changes))
+			event.ChangeHashes = make(map[string]string, len(event.Changes))
+		}
+		event.Salts[field] = encoded
+		event.ChangeHashes[field] = changeHash
+	}
+	hash, err := Hash(event)
+	if err != nil {
+		return err
+	}
+	event.Hash = hash
+	event.Signature = ""
+	if s.key != nil && s.every > 0 && s.sinceCheck+1 >= s.every {
+		event.Signature = base64.RawURLEncoding.EncodeToString(ed25519.Sign(s.key, checkpointMessage(hash)))
+	}
+
+	if err := s.inner.Append(ctx, event); err != nil {
+		return err
+	}
+	s.lastHash = hash
+	s.sinceCheck++
+	if event.Signature != "" {
+		s.sinceCheck = 0
+	}
+	return nil
+}
+
+func (s *chainedSink) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	return s.inner.Query(ctx, filter)
+}
+
+// Erase erases personal data from the inner sink. It leaves the chain
+// intact, as the chain covers the hashes of the changes rather than the
+// changes themselves.
+func (s *chainedSink) Erase(ctx context.Context, target string, fields []string) (int, error) {
+	eraser, ok := s.inner.(domain.AuditEraser)
+	if !ok {
+		return 0, errNotErasable
+	}
+	return eraser.Erase(ctx, target, fields)
+}
+
+func (s *chainedSink) walk(ctx context.Context, fn func(event *domain.AuditEvent) error) error {
+	return walk(ctx, s.inner, fn)
+}
+
+// CheckHealth checks the inner sink, if it can be checked.
+func (s *chainedSink) CheckHealth(ctx context.Context) error {
+	if c, ok := s.inner.(domain.HealthChecker); ok {
+		return c.CheckHealth(ctx)
+	}
+	return nil
+}
+
+// Close waits for the event being appended, if any, and closes the inner
+// sink if it can be closed.
+func (s *chainedSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.inner.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Hash computes an event's chain hash: the SHA-256 of a canonical JSON
+// encoding of every field but ID, Hash, Signature, Salts and Changes, which
+// are covered by ChangeHashes instead.
+func Hash(event *domain.AuditEvent) (string, error) {
+	b, err := json.Marshal(struct {
+		PrevHash     string            `json:"prev_hash"`
+		Time         string            `json:"time"`
+		Actor        string            `json:"actor"`
+		Action       domain.Action     `json:"action"`
+		Target       string            `json:"target"`
+		RequestID    string            `json:"request_id"`
+		ChangeHashes map[string]string `json:"change_hashes"`
+	}{
+		PrevHash:     event.PrevHash,
+		Time:         event.Time.UTC().Format(time.RFC3339Nano),
+		Actor:        event.Actor,
+		Action:       event.Action,
+		Target:       event.Target,
+		RequestID:    event.RequestID,
+		ChangeHashes: event.ChangeHashes,
+	})
+	if err != nil {
+		return "", err
+	}
+	return sum(b), nil
+}
+
+// ChangeHash computes the SHA-256 of a field's salt followed by the JSON
+// encoding of the field's name and change.
+func ChangeHash(field, salt string, change domain.Change) (string, error) {
+	b, err := json.Marshal(map[string]domain.Change{field: change})
+	if err != nil {
+		return "", err
+	}
+	return sum(append([]byte(salt), b...)), nil
+}
+
+// checkChanges checks the event's changes against their hashes. A change
+// without a salt must be a personal field erased to Redacted; every other
+// change must still match its hash. It returns why the changes fail, if they
+// do, and whether any were erased.
+func checkChanges(event *domain.AuditEvent) (reason string, erased bool, err error) {
+	if len(event.Changes) != len(event.ChangeHashes) || len(event.Salts) > len(event.Changes) {
+		return "changes do not match the event's change hashes", false, nil
+	}
+	for field, change := range event.Changes {
+		want, ok := event.ChangeHashes[field]
+		if !ok {
+			return "changes do not match the event's change hashes", false, nil
+		}
+		salt, ok := event.Salts[field]
+		if !ok {
+			if !slices.Contains(domain.PersonalFields, field) || !change.IsRedacted() {
+				return fmt.Sprintf("changes of %s are neither erased nor salted", field), false, nil
+			}
+			erased = true
+			continue
+		}
+		got, err := ChangeHash(field, salt, change)
+		if err != nil {
+			return "", false, err
+		}
+		if got != want {
+			return fmt.Sprintf("changes of %s do not match their hash", field), false, nil
+		}
+	}
+	return "", erased, nil
+}
+
+func sum(b []byte) string {
+	digest := sha256.Sum256(b)
+	return hex.EncodeToString(digest[:])
+}
+
+func checkpointMessage(hash string) []byte {
+	return []byte(checkpointContext + hash)
+}
+
+// BrokenLinkError reports the first event at which a chain fails to verify.
+type BrokenLinkError struct {
+	ID     int64
+	Reason string
+}
+
+func (e *BrokenLinkError) Error() string {
+	return fmt.Sprintf("audit chain broken at event %d: %s", e.ID, e.Reason)
+}
+
+// Report summarises a verified chain.
+type Report struct {
+	Events      int
+	Checkpoints int
+	// LastCheckpoint is the ID of the last signed event, or zero. Events
+	// after it are only protected by the chain, which whoever can write to
+	// the sink could recompute.
+	LastCheckpoint int64
+	// Erased counts the events personal data was erased from. The erased
+	// fields can no longer be checked, only the rest of the event.
+	Erased int
+}
+
+// Verify walks the chain in sink from the start, checking every hash and
+// link and, if pub is set, every checkpoint signature. The changes of events
+// without a salt must have been erased, and the rest must match their hashes.
+// It returns a *BrokenLinkError for the first event that fails.
+func Verify(ctx context.Context, sink domain.AuditSink, pub ed25519.PublicKey) (*Report, error) {
+	report := &Report{}
+	prevHash := ""
+	err := walk(ctx, sink, func(event *domain.AuditEvent) error {
+		if event.PrevHash != prevHash {
+			return &BrokenLinkError{ID: event.ID, Reason: "previous hash does not match the event before it"}
+		}
+		reason, erased, err := checkChanges(event)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return &BrokenLinkError{ID: event.ID, Reason: reason}
+		}
+		if erased {
+			report.Erased++
+		}
+		hash, err := Hash(event)
+		if err != nil {
+			return err
+		}
+		if event.Hash != hash {
+			return &BrokenLinkError{ID: event.ID, Reason: "hash does not match the event's content"}
+		}
+		if event.Signature != "" && pub != nil {
+			sig, err := base64.RawURLEncoding.DecodeString(event.Signature)
+			if err != nil || !ed25519.Verify(pub, checkpointMessage(hash), sig) {
+				return &BrokenLinkError{ID: event.ID, Reason: "checkpoint signature is invalid"}
+			}
+			report.Checkpoints++
+			report.LastCheckpoint = event.ID
+		}
+		prevHash = hash
+		report.Events++
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// walker is implemented by sinks that can stream their events in order in
+// one pass, rather than be paged through with Query.
+type walker interface {
+	walk(ctx context.Context, fn func(event *domain.AuditEvent) error) error
+}
+
+// walk calls fn with every event in sink, in order.
+func walk(ctx context.Context, sink domain.AuditSink, fn func(event *domain.AuditEvent) error) error {
+	if w, ok := sink.(walker); ok {
+		return w.walk(ctx, fn)
+	}
+	filter := domain.AuditFilter{Limit: domain.MaxListLimit}
+	for {
+		events, err := sink.Query(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		if len(events) < filter.Limit {
+			return nil
+		}
+		filter.After = events[len(events)-1].ID
+	}
+}
@@ -0,0 +1,276 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func appendEvents(t *testing.T, s domain.AuditSink, n int) {
+	t.Helper()
+	for i := range n {
+		event := &domain.AuditEvent{
+			Time:    testNow.Add(time.Duration(i) * time.Second),
+			Actor:   "user:1",
+			Action:  domain.ActionUpdate,
+			Target:  "user:42",
+			Changes: map[string]domain.Change{"email": {Before: "a@example.com", After: "b@example.com"}},
+		}
+		if err := s.Append(context.Background(), event); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+}
+
+func TestChainedSink(t *testing.T) {
+	pub, priv := newTestKey(t)
+	inner := NewMemorySink()
+	s, err := NewChainedSink(context.Background(), inner, priv, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, s, 7)
+
+	events, _ := s.Query(context.Background(), domain.AuditFilter{Limit: 10})
+	if events[0].PrevHash != "" || events[1].PrevHash != events[0].Hash || events[0].Hash == "" {
+		t.Errorf("events are not chained: %+v", events[:2])
+	}
+	var signed []int64
+	for _, event := range events {
+		if event.Signature != "" {
+			signed = append(signed, event.ID)
+		}
+	}
+	if len(signed) != 2 || signed[0] != 3 || signed[1] != 6 {
+		t.Errorf("checkpoints at %v, want 3 and 6", signed)
+	}
+
+	report, err := Verify(context.Background(), s, pub)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if *report != (Report{Events: 7, Checkpoints: 2, LastCheckpoint: 6}) {
+		t.Errorf("Verify() = %+v", report)
+	}
+
+	// A new sink on the same events continues the chain and the checkpoint
+	// count.
+	resumed, err := NewChainedSink(context.Background(), inner, priv, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, resumed, 2)
+	report, err = Verify(context.Background(), inner, pub)
+	if err != nil || report.Events != 9 || report.LastCheckpoint != 9 {
+		t.Errorf("Verify() after resuming = %+v, %v", report, err)
+	}
+}
+
+func TestVerify_Tampering(t *testing.T) {
+	pub, priv := newTestKey(t)
+	otherPub, _ := newTestKey(t)
+
+	tests := []struct {
+		name   string
+		pub    ed25519.PublicKey
+		edit   func(lines []string) []string
+		wantID int64
+		reason string
+	}{
+		{
+			name:   "altered changes",
+			edit:   func(l []string) []string { l[2] = strings.Replace(l[2], "b@example.com", "c@example.com", 1); return l },
+			wantID: 3, reason: "changes",
+		},
+		{
+			name:   "altered actor",
+			edit:   func(l []string) []string { l[2] = strings.Replace(l[2], "user:1", "user:7", 1); return l },
+			wantID: 3, reason: "content",
+		},
+		{
+			name:   "removed event",
+			edit:   func(l []string) []string { return append(l[:1], l[2:]...) },
+			wantID: 3, reason: "previous hash",
+		},
+		{
+			name:   "reordered events",
+			edit:   func(l []string) []string { l[0], l[1] = l[1], l[0]; return l },
+			wantID: 2, reason: "previous hash",
+		},
+		{
+			name:   "wrong key",
+			pub:    otherPub,
+			edit:   func(l []string) []string { return l },
+			wantID: 2, reason: "signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			file, _ := NewFileSink(path)
+			s, err := NewChainedSink(context.Background(), file, priv, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			appendEvents(t, s, 4)
+
+			data, _ := os.ReadFile(path)
+			lines := tt.edit(strings.Split(strings.TrimSpace(string(data)), "\n"))
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			key := pub
+			if tt.pub != nil {
+				key = tt.pub
+			}
+			tampered, _ := OpenFile(path)
+			_, err = Verify(context.Background(), tampered, key)
+			var broken *BrokenLinkError
+			if !errors.As(err, &broken) || broken.ID != tt.wantID || !strings.Contains(broken.Reason, tt.reason) {
+				t.Errorf("Verify() error = %v, want a broken link at %d about %s", err, tt.wantID, tt.reason)
+			}
+		})
+	}
+}
+
+func TestVerify_ErasedChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(event *domain.AuditEvent)
+		reason string
+	}{
+		{"erased", func(e *domain.AuditEvent) { e.Redact(domain.PersonalFields) }, ""},
+		{"altered role", func(e *domain.AuditEvent) {
+			e.Changes["role"] = domain.Change{Before: "user", After: "admin"}
+		}, "role"},
+		{"altered role with its salt dropped", func(e *domain.AuditEvent) {
+			e.Changes["role"] = domain.Change{Before: "user", After: "admin"}
+			delete(e.Salts, "role")
+		}, "role"},
+		{"role redacted", func(e *domain.AuditEvent) {
+			e.Changes["role"] = domain.Change{Before: domain.Redacted, After: domain.Redacted}
+			delete(e.Salts, "role")
+		}, "role"},
+		{"erased email replaced", func(e *domain.AuditEvent) {
+			e.Redact(domain.PersonalFields)
+			e.Changes["email"] = domain.Change{Before: domain.Redacted, After: "c@example.com"}
+		}, "email"},
+		{"change added", func(e *domain.AuditEvent) {
+			e.Changes["username"] = domain.Change{Before: domain.Redacted, After: domain.Redacted}
+		}, "change hashes"},
+		{"change removed", func(e *domain.AuditEvent) { delete(e.Changes, "role") }, "change hashes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chained, err := NewChainedSink(context.Background(), NewMemorySink(), nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := chained.Append(context.Background(), &domain.AuditEvent{
+				Time: testNow, Action: domain.ActionUpdate, Target: "user:42",
+				Changes: map[string]domain.Change{
+					"email": {Before: "a@example.com", After: "b@example.com"},
+					"role":  {Before: "user", After: "user"},
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			events, _ := chained.Query(context.Background(), domain.AuditFilter{Limit: 1})
+			tt.edit(events[0])
+			edited := NewMemorySink()
+			edited.Append(context.Background(), events[0])
+
+			report, err := Verify(context.Background(), edited, nil)
+			if tt.reason == "" {
+				if err != nil || report.Erased != 1 {
+					t.Errorf("Verify() = %+v, %v, want one erased event", report, err)
+				}
+				return
+			}
+			var broken *BrokenLinkError
+			if !errors.As(err, &broken) || !strings.Contains(broken.Reason, tt.reason) {
+				t.Errorf("Verify() error = %v, want a broken link about %s", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestChainedSink_Erase(t *testing.T) {
+	pub, priv := newTestKey(t)
+	sinks := map[string]func(t *testing.T) domain.AuditSink{
+		"memory": func(t *testing.T) domain.AuditSink { return NewMemorySink() },
+		"file": func(t *testing.T) domain.AuditSink {
+			s, err := NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+	for name, newSink := range sinks {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewChainedSink(context.Background(), newSink(t), priv, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			appendEvents(t, s, 3)
+			other := &domain.AuditEvent{Time: testNow, Action: domain.ActionUpdate, Target: "user:7",
+				Changes: map[string]domain.Change{"email": {Before: "x@example.com", After: "y@example.com"}}}
+			if err := s.Append(context.Background(), other); err != nil {
+				t.Fatal(err)
+			}
+
+			n, err := s.(domain.AuditEraser).Erase(context.Background(), "user:42", domain.PersonalFields)
+			if err != nil || n != 3 {
+				t.Fatalf("Erase() = %d, %v, want 3 events", n, err)
+			}
+			if n, _ := s.(domain.AuditEraser).Erase(context.Background(), "user:42", domain.PersonalFields); n != 0 {
+				t.Errorf("Erase() again modified %d events", n)
+			}
+
+			events, _ := s.Query(context.Background(), domain.AuditFilter{Limit: 10})
+			if events[0].Changes["email"].Before != domain.Redacted || events[0].Salts["email"] != "" {
+				t.Errorf("event about the user was not erased: %+v", events[0])
+			}
+			if events[3].Changes["email"].Before != "x@example.com" || events[3].Salts["email"] == "" {
+				t.Errorf("event about another user was erased: %+v", events[3])
+			}
+
+			report, err := Verify(context.Background(), s, pub)
+			if err != nil || report.Events != 4 || report.Erased != 3 || report.Checkpoints != 2 {
+				t.Errorf("Verify() after erasing = %+v, %v", report, err)
+			}
+
+			// Appending continues after the rewrite.
+			appendEvents(t, s, 1)
+			if report, err := Verify(context.Background(), s, pub); err != nil || report.Events != 5 {
+				t.Errorf("Verify() after appending = %+v, %v", report, err)
+			}
+		})
+	}
+
+	t.Run("inner sink cannot erase", func(t *testing.T) {
+		s, _ := NewChainedSink(context.Background(), struct{ domain.AuditSink }{NewMemorySink()}, nil, 0)
+		if _, err := s.(domain.AuditEraser).Erase(context.Background(), "user:42", domain.PersonalFields); !errors.Is(err, errNotErasable) {
+			t.Errorf("Erase() error = %v, want %v", err, errNotErasable)
+		}
+	})
+}
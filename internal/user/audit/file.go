@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"repo-guardian/internal/domain"
+)
+
+// maxLineSize bounds the length of a single event in the file.
+const maxLineSize = 1 << 20
+
+var (
+	errReadOnly = errors.New("audit: log was opened read-only")
+	errClosed   = errors.New("audit: log is closed")
+)
+
+type fileSink struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	lastID int64
+	closed bool
+}
+
+// NewFileSink appends events to the file at path as JSON lines, creating it
+// if needed. Every event is synced to disk before Append returns.
+func NewFileSink(path string) (domain.AuditSink, error) {
+	s := &fileSink{path: path}
+	if err := s.scan(func(event *domain.AuditEvent) bool {
+		s.lastID = event.ID
+		return true
+	}); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.file = f
+	return s, nil
+}
+
+// OpenFile opens an existing log for reading only; Append fails.
+func OpenFile(path string) (domain.AuditSink, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &fileSink{path: path}, nil
+}
+
+func (s *fileSink) Append(ctx context.Context, event *domain.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errClosed
+	}
+	if s.file == nil {
+		return errReadOnly
+	}
+
+	stored := *event
+	stored.ID = s.lastID + 1
+	line, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.lastID = stored.ID
+	event.ID = stored.ID
+	return nil
+}
+
+func (s *fileSink) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*domain.AuditEvent
+	err := s.scan(func(event *domain.AuditEvent) bool {
+		if matches(event, filter) {
+			events = append(events, event)
+		}
+		return len(events) < filter.Limit
+	})
+	return events, err
+}
+
+// walk reads the file once, rather than once per page as Query would.
+func (s *fileSink) walk(ctx context.Context, fn func(event *domain.AuditEvent) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fnErr error
+	err := s.scan(func(event *domain.AuditEvent) bool {
+		if fnErr = ctx.Err(); fnErr == nil {
+			fnErr = fn(event)
+		}
+		return fnErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// Erase rewrites the file with fields redacted from the events about target.
+// The new file replaces the old one atomically, so a crash leaves either.
+func (s *fileSink) Erase(ctx context.Context, target string, fields []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, errClosed
+	}
+	if s.file == nil {
+		return 0, errReadOnly
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	var n int
+	var writeErr error
+	w := bufio.NewWriter(tmp)
+	err = s.scan(func(event *domain.AuditEvent) bool {
+		if event.Target == target && event.Redact(fields) {
+			n++
+		}
+		var line []byte
+		if line, writeErr = json.Marshal(event); writeErr == nil {
+			_, writeErr = w.Write(append(line, '\n'))
+		}
+		return writeErr == nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if writeErr != nil {
+		return 0, writeErr
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return 0, err
+	}
+
+	// Keep appending to the new file rather than the unlinked old one.
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return n, err
+	}
+	s.file.Close()
+	s.file = f
+	return n, nil
+}
+
+// CheckHealth reports whether events can still be appended to the file.
+func (s *fileSink) CheckHealth(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errClosed
+	}
+	if s.file == nil {
+		return errReadOnly
+	}
+	_, err := s.file.Stat()
+	return err
+}
+
+// Close closes the file once every event being appended is written. Events
+// are synced as they are appended, so there is nothing left to flush.
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// scan calls fn with every event in the file until it returns false.
+func (s *fileSink) scan(fn func(event *domain.AuditEvent) bool) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var event domain.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("%s:%d: %w", s.path, line, err)
+		}
+		if !fn(&event) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
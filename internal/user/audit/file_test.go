@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	s, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	testSink(t, s)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("file has %d lines, want 3", lines)
+	}
+
+	reopened, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink() on an existing file error = %v", err)
+	}
+	event := &domain.AuditEvent{Action: domain.ActionUpdate, Target: "user:42"}
+	if err := reopened.Append(context.Background(), event); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if event.ID != 4 {
+		t.Errorf("Append() after reopening assigned ID %d, want 4", event.ID)
+	}
+}
+
+func TestFileSink_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("{\"id\":1}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileSink(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("NewFileSink() error = %v, want the corrupt line reported", err)
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if _, err := OpenFile(path); !os.IsNotExist(err) {
+		t.Errorf("OpenFile() of a missing file error = %v, want not exist", err)
+	}
+
+	s, _ := NewFileSink(path)
+	s.Append(context.Background(), &domain.AuditEvent{Action: domain.ActionCreate, Target: "user:1"})
+	r, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if events, err := r.Query(context.Background(), domain.AuditFilter{Limit: 10}); err != nil || len(events) != 1 {
+		t.Errorf("Query() = %+v, %v, want the event", events, err)
+	}
+	if err := r.Append(context.Background(), &domain.AuditEvent{}); !errors.Is(err, errReadOnly) {
+		t.Errorf("Append() error = %v, want %v", err, errReadOnly)
+	}
+}
+
+func TestFileSink_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	inner, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewChainedSink(context.Background(), inner, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(context.Background(), &domain.AuditEvent{Action: domain.ActionCreate, Target: "user:1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := s.(domain.HealthChecker)
+	if err := checker.CheckHealth(context.Background()); err != nil {
+		t.Errorf("CheckHealth() error = %v", err)
+	}
+
+	closer := s.(io.Closer)
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close() again error = %v", err)
+	}
+	if err := checker.CheckHealth(context.Background()); !errors.Is(err, errClosed) {
+		t.Errorf("CheckHealth() after Close() error = %v, want %v", err, errClosed)
+	}
+	if err := s.Append(context.Background(), &domain.AuditEvent{Action: domain.ActionUpdate, Target: "user:1"}); !errors.Is(err, errClosed) {
+		t.Errorf("Append() after Close() error = %v, want %v", err, errClosed)
+	}
+	if events, err := s.Query(context.Background(), domain.AuditFilter{Limit: 10}); err != nil || len(events) != 1 {
+		t.Errorf("Query() after Close() = %d events, %v", len(events), err)
+	}
+}
+
+func TestFileSink_Walk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	file, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewChainedSink(context.Background(), file, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := domain.MaxListLimit*2 + 1
+	appendEvents(t, s, n)
+
+	var ids []int64
+	if err := file.(walker).walk(context.Background(), func(event *domain.AuditEvent) error {
+		ids = append(ids, event.ID)
+		return nil
+	}); err != nil || len(ids) != n || ids[0] != 1 || ids[n-1] != int64(n) {
+		t.Errorf("walk() visited %d events, %v, want all %d in order", len(ids), err, n)
+	}
+
+	stop := errors.New("stop")
+	var visited int
+	err = file.(walker).walk(context.Background(), func(event *domain.AuditEvent) error {
+		visited++
+		return stop
+	})
+	if !errors.Is(err, stop) || visited != 1 {
+		t.Errorf("walk() = %v after %d events, want it to stop at the first error", err, visited)
+	}
+
+	// A chain longer than a page resumes from its last event.
+	resumed, err := NewChainedSink(context.Background(), file, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, resumed, 1)
+	if report, err := Verify(context.Background(), s, nil); err != nil || report.Events != n+1 {
+		t.Errorf("Verify() = %+v, %v, want %d events", report, err, n+1)
+	}
+}
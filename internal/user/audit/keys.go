@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// LoadSigningKey reads a PKCS #8 PEM encoded Ed25519 private key for signing
+// checkpoints.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	key, err := loadPEM(path, "PRIVATE KEY", x509.ParsePKCS8PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 private key", path)
+	}
+	return private, nil
+}
+
+// LoadVerifyKey reads a PKIX PEM encoded Ed25519 public key for verifying
+// checkpoints. A private key file is accepted too.
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	key, err := loadPEM(path, "PUBLIC KEY", x509.ParsePKIXPublicKey)
+	if err != nil {
+		private, perr := LoadSigningKey(path)
+		if perr != nil {
+			return nil, err
+		}
+		return private.Public().(ed25519.PublicKey), nil
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 public key", path)
+	}
+	return public, nil
+}
+
+func loadPEM(path, blockType string, parse func(der []byte) (any, error)) (any, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s: no %s PEM block", path, blockType)
+	}
+	return parse(block.Bytes)
+}
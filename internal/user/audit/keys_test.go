@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKeys(t *testing.T) {
+	pub, priv := newTestKey(t)
+	privDER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+	privPath := writePEM(t, "PRIVATE KEY", privDER)
+	pubPath := writePEM(t, "PUBLIC KEY", pubDER)
+
+	signing, err := LoadSigningKey(privPath)
+	if err != nil || !signing.Equal(priv) {
+		t.Errorf("LoadSigningKey() = %v, want the private key", err)
+	}
+	for _, path := range []string{pubPath, privPath} {
+		verify, err := LoadVerifyKey(path)
+		if err != nil || !verify.Equal(pub) {
+			t.Errorf("LoadVerifyKey(%s) = %v, want the public key", filepath.Base(path), err)
+		}
+	}
+
+	if _, err := LoadSigningKey(pubPath); err == nil {
+		t.Error("LoadSigningKey() of a public key succeeded")
+	}
+	if _, err := LoadVerifyKey(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("LoadVerifyKey() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"repo-guardian/internal/domain"
+)
+
+type memorySink struct {
+	mu     sync.RWMutex
+	events []domain.AuditEvent
+}
+
+func NewMemorySink() domain.AuditSink {
+	return &memorySink{}
+}
+
+func (s *memorySink) Append(ctx context.Context, event *domain.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.ID = int64(len(s.events)) + 1
+	stored := *event
+	stored.Changes = maps.Clone(event.Changes)
+	stored.Salts = maps.Clone(event.Salts)
+	s.events = append(s.events, stored)
+	return nil
+}
+
+func (s *memorySink) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []*domain.AuditEvent
+	// IDs are positions in the slice, so the scan can start after filter.After.
+	for i := max(filter.After, 0); i < int64(len(s.events)) && len(events) < filter.Limit; i++ {
+		if matches(&s.events[i], filter) {
+			event := s.events[i]
+			event.Changes = maps.Clone(event.Changes)
+			event.Salts = maps.Clone(event.Salts)
+			events = append(events, &event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memorySink) Erase(ctx context.Context, target string, fields []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for i := range s.events {
+		if s.events[i].Target == target && s.events[i].Redact(fields) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func matches(event *domain.AuditEvent, filter domain.AuditFilter) bool {
+	return event.ID > filter.After && (filter.Target == "" || event.Target == filter.Target)
+}
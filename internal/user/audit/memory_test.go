@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+var testNow = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testSink checks the behaviour every sink shares.
+func testSink(t *testing.T, s domain.AuditSink) {
+	t.Helper()
+	ctx := context.Background()
+
+	events := []*domain.AuditEvent{
+		{Time: testNow, Actor: "user:1", Action: domain.ActionCreate, Target: "user:42", RequestID: "r1",
+			Changes: map[string]domain.Change{"username": {After: "john"}, "password": {After: domain.Redacted}}},
+		{Time: testNow.Add(time.Second), Action: domain.ActionCreate, Target: "user:43"},
+		{Time: testNow.Add(2 * time.Second), Actor: "user:1", Action: domain.ActionDelete, Target: "user:42"},
+	}
+	for i, event := range events {
+		if err := s.Append(ctx, event); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if event.ID != int64(i+1) {
+			t.Errorf("Append() assigned ID %d, want %d", event.ID, i+1)
+		}
+	}
+
+	got, err := s.Query(ctx, domain.AuditFilter{Target: "user:42", Limit: 10})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+		t.Fatalf("Query() by target = %+v, want events 1 and 3", got)
+	}
+	first := got[0]
+	if first.Actor != "user:1" || first.Action != domain.ActionCreate || first.RequestID != "r1" || !first.Time.Equal(testNow) {
+		t.Errorf("Query() event = %+v", first)
+	}
+	if first.Changes["username"].After != "john" || first.Changes["username"].Before != nil || first.Changes["password"].After != domain.Redacted {
+		t.Errorf("Query() changes = %+v", first.Changes)
+	}
+
+	got, _ = s.Query(ctx, domain.AuditFilter{After: 1, Limit: 1})
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("Query() after 1 with limit 1 = %+v, want event 2", got)
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	s := NewMemorySink()
+	testSink(t, s)
+
+	got, _ := s.Query(context.Background(), domain.AuditFilter{Limit: 10})
+	got[0].Actor = "mutated"
+	if again, _ := s.Query(context.Background(), domain.AuditFilter{Limit: 1}); again[0].Actor != "user:1" {
+		t.Errorf("Query() returned the stored event instead of a copy")
+	}
+}
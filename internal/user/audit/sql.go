@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"repo-guardian/internal/domain"
+)
+
+// SQLSchema creates the table the SQL sink writes to, in PostgreSQL syntax.
+// Other databases need their own way of generating the id column.
+const SQLSchema = `CREATE TABLE IF NOT EXISTS audit_events (
+	id            INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	time          TIMESTAMP NOT NULL,
+	actor         TEXT NOT NULL,
+	action        TEXT NOT NULL,
+	target        TEXT NOT NULL,
+	request_id    TEXT NOT NULL,
+	changes       TEXT,
+	change_hashes TEXT,
+	salts         TEXT,
+	prev_hash     TEXT NOT NULL,
+	hash          TEXT NOT NULL,
+	signature     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_events_target ON audit_events (target, id);`
+
+// Placeholder returns the bind parameter for the n-th argument, counting
+// from 1, in a driver's syntax.
+type Placeholder func(n int) string
+
+var (
+	// Dollar is the PostgreSQL syntax, $1.
+	Dollar Placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+	// Question is the MySQL and SQLite syntax, ?.
+	Question Placeholder = func(int) string { return "?" }
+)
+
+type sqlSink struct {
+	db *sql.DB
+	ph Placeholder
+}
+
+// NewSQLSink stores events in the audit_events table of db, see SQLSchema.
+// The database needs to support INSERT ... RETURNING. Closing the sink
+// closes db.
+func NewSQLSink(db *sql.DB, ph Placeholder) domain.AuditSink {
+	return &sqlSink{db: db, ph: ph}
+}
+
+func (s *sqlSink) Close() error {
+	return s.db.Close()
+}
+
+// CheckHealth pings the database.
+func (s *sqlSink) CheckHealth(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *sqlSink) Append(ctx context.Context, event *domain.AuditEvent) error {
+	changes, err := encodeJSON(event.Changes)
+	if err != nil {
+		return err
+	}
+	changeHashes, err := encodeJSON(event.ChangeHashes)
+	if err != nil {
+		return err
+	}
+	salts, err := encodeJSON(event.Salts)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO audit_events (time, actor, action, target, request_id, changes, change_hashes, salts, prev_hash, hash, signature) VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s) RETURNING id",
+		s.ph(1), s.ph(2), s.ph(3), s.ph(4), s.ph(5), s.ph(6), s.ph(7), s.ph(8), s.ph(9), s.ph(10), s.ph(11))
+	return s.db.QueryRowContext(ctx, query,
+		event.Time, event.Actor, string(event.Action), event.Target, event.RequestID, changes,
+		changeHashes, salts, event.PrevHash, event.Hash, event.Signature,
+	).Scan(&event.ID)
+}
+
+func (s *sqlSink) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	args := []any{filter.After}
+	where := []string{"id > " + s.ph(1)}
+	if filter.Target != "" {
+		args = append(args, filter.Target)
+		where = append(where, "target = "+s.ph(len(args)))
+	}
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(
+		"SELECT id, time, actor, action, target, request_id, changes, change_hashes, salts, prev_hash, hash, signature FROM audit_events WHERE %s ORDER BY id LIMIT %s",
+		strings.Join(where, " AND "), s.ph(len(args)))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.AuditEvent
+	for rows.Next() {
+		var event domain.AuditEvent
+		var action string
+		var changes, changeHashes, salts sql.NullString
+		if err := rows.Scan(&event.ID, &event.Time, &event.Actor, &action, &event.Target, &event.RequestID, &changes,
+			&changeHashes, &salts, &event.PrevHash, &event.Hash, &event.Signature); err != nil {
+			return nil, err
+		}
+		event.Action = domain.Action(action)
+		if err := decodeJSON(changes, &event.Changes); err != nil {
+			return nil, fmt.Errorf("audit event %d: %w", event.ID, err)
+		}
+		if err := decodeJSON(changeHashes, &event.ChangeHashes); err != nil {
+			return nil, fmt.Errorf("audit event %d: %w", event.ID, err)
+		}
+		if err := decodeJSON(salts, &event.Salts); err != nil {
+			return nil, fmt.Errorf("audit event %d: %w", event.ID, err)
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// Erase redacts fields from the events about target in one transaction.
+func (s *sqlSink) Erase(ctx context.Context, target string, fields []string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, changes, salts FROM audit_events WHERE target = "+s.ph(1)+" AND changes IS NOT NULL ORDER BY id", target)
+	if err != nil {
+		return 0, err
+	}
+	var redacted []*domain.AuditEvent
+	for rows.Next() {
+		var event domain.AuditEvent
+		var changes, salts sql.NullString
+		if err := rows.Scan(&event.ID, &changes, &salts); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := decodeJSON(changes, &event.Changes); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("audit event %d: %w", event.ID, err)
+		}
+		if err := decodeJSON(salts, &event.Salts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("audit event %d: %w", event.ID, err)
+		}
+		if event.Redact(fields) {
+			redacted = append(redacted, &event)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	update := fmt.Sprintf("UPDATE audit_events SET changes = %s, salts = %s WHERE id = %s", s.ph(1), s.ph(2), s.ph(3))
+	for _, event := range redacted {
+		changes, err := encodeJSON(event.Changes)
+		if err != nil {
+			return 0, err
+		}
+		salts, err := encodeJSON(event.Salts)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, update, changes, salts, event.ID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(redacted), nil
+}
+
+// encodeJSON encodes a map as a JSON column, NULL if it is empty.
+func encodeJSON[M ~map[string]V, V any](m M) (sql.NullString, error) {
+	if len(m) == 0 {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func decodeJSON(column sql.NullString, v any) error {
+	if !column.Valid {
+		return nil
+	}
+	return json.Unmarshal([]byte(column.String), v)
+}
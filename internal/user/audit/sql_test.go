@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSQLSink_Append(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := NewSQLSink(db, Dollar)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"INSERT INTO audit_events (time, actor, action, target, request_id, changes, change_hashes, salts, prev_hash, hash, signature) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id")).
+		WithArgs(testNow, "user:1", "delete", "user:42", "r1", `{"deleted_at":{"before":null,"after":"2024-01-02T03:04:05Z"}}`,
+			`{"deleted_at":"c"}`, `{"deleted_at":"s"}`, "p", "h", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	event := &domain.AuditEvent{
+		Time: testNow, Actor: "user:1", Action: domain.ActionDelete, Target: "user:42", RequestID: "r1",
+		Changes:      map[string]domain.Change{"deleted_at": {After: "2024-01-02T03:04:05Z"}},
+		ChangeHashes: map[string]string{"deleted_at": "c"},
+		Salts:        map[string]string{"deleted_at": "s"},
+		PrevHash:     "p", Hash: "h",
+	}
+	if err := s.Append(context.Background(), event); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if event.ID != 7 {
+		t.Errorf("Append() assigned ID %d, want 7", event.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSQLSink_Query(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := NewSQLSink(db, Question)
+
+	columns := []string{"id", "time", "actor", "action", "target", "request_id", "changes", "change_hashes", "salts", "prev_hash", "hash", "signature"}
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT id, time, actor, action, target, request_id, changes, change_hashes, salts, prev_hash, hash, signature FROM audit_events WHERE id > ? AND target = ? ORDER BY id LIMIT ?")).
+		WithArgs(int64(2), "user:42", 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(3, testNow, "user:1", "update", "user:42", "r1", `{"email":{"before":"a@example.com","after":"b@example.com"}}`,
+				`{"email":"c3"}`, `{"email":"s3"}`, "", "h3", "").
+			AddRow(5, testNow, "", "delete", "user:42", "", nil, nil, nil, "h4", "h5", "sig"))
+
+	got, err := s.Query(context.Background(), domain.AuditFilter{Target: "user:42", After: 2, Limit: 10})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 3 || got[1].Action != domain.ActionDelete || got[1].Changes != nil ||
+		got[0].Hash != "h3" || got[0].ChangeHashes["email"] != "c3" || got[0].Salts["email"] != "s3" || got[1].PrevHash != "h4" || got[1].Signature != "sig" {
+		t.Fatalf("Query() = %+v", got)
+	}
+	if change := got[0].Changes["email"]; change.Before != "a@example.com" || change.After != "b@example.com" {
+		t.Errorf("Query() changes = %+v", got[0].Changes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSQLSink_Erase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := NewSQLSink(db, Dollar)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT id, changes, salts FROM audit_events WHERE target = $1 AND changes IS NOT NULL ORDER BY id")).
+		WithArgs("user:42").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "changes", "salts"}).
+			AddRow(3, `{"email":{"before":"a@example.com","after":"b@example.com"},"role":{"before":"user","after":"admin"}}`,
+				`{"email":"s3e","role":"s3r"}`).
+			AddRow(4, `{"role":{"before":"admin","after":"user"}}`, `{"role":"s4"}`))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE audit_events SET changes = $1, salts = $2 WHERE id = $3")).
+		WithArgs(`{"email":{"before":"[REDACTED]","after":"[REDACTED]"},"role":{"before":"user","after":"admin"}}`,
+			`{"role":"s3r"}`, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := s.(domain.AuditEraser).Erase(context.Background(), "user:42", domain.PersonalFields)
+	if err != nil || n != 1 {
+		t.Fatalf("Erase() = %d, %v, want 1 event", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSQLSink_CheckHealth(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := NewSQLSink(db, Dollar)
+
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(sql.ErrConnDone)
+	checker := s.(domain.HealthChecker)
+	if err := checker.CheckHealth(context.Background()); err != nil {
+		t.Errorf("CheckHealth() error = %v", err)
+	}
+	if err := checker.CheckHealth(context.Background()); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("CheckHealth() error = %v, want %v", err, sql.ErrConnDone)
+	}
+	mock.ExpectClose()
+	if err := s.(io.Closer).Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"fmt"
+	"slices"
+
+	"repo-guardian/internal/domain"
+)
+
+// Rule allows Actions to principals holding one of Roles. Empty Roles match
+// every caller, including anonymous ones, and empty Actions match every
+// action. When, if set, must also hold for the rule to apply.
+type Rule struct {
+	Roles   []domain.Role
+	Actions []domain.Action
+	When    func(p domain.Principal, target *domain.User) bool
+}
+
+func (r Rule) allows(p domain.Principal, action domain.Action, target *domain.User) bool {
+	if len(r.Roles) > 0 && !slices.Contains(r.Roles, p.Role) {
+		return false
+	}
+	if len(r.Actions) > 0 && !slices.Contains(r.Actions, action) {
+		return false
+	}
+	return r.When == nil || r.When(p, target)
+}
+
+// Self holds when the principal is the target user.
+func Self(p domain.Principal, target *domain.User) bool {
+	id, ok := p.UserID()
+	return ok && target != nil && target.ID == id
+}
+
+// DefaultRules let anyone register, users manage, export and erase only
+// themselves, services read, and admins do anything.
+var DefaultRules = []Rule{
+	{Roles: []domain.Role{domain.RoleAdmin}},
+	{Actions: []domain.Action{domain.ActionCreate}},
+	{
+		Roles:   []domain.Role{domain.RoleUser},
+		Actions: []domain.Action{domain.ActionRead, domain.ActionUpdate, domain.ActionDelete, domain.ActionExport, domain.ActionErase},
+		When:    Self,
+	},
+	{
+		Roles:   []domain.Role{domain.RoleService},
+		Actions: []domain.Action{domain.ActionRead, domain.ActionList},
+	},
+}
+
+// Policy allows an action if any of its rules does and the principal holds
+// the scope the action requires.
+type Policy struct {
+	rules []Rule
+}
+
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+func (p *Policy) Authorize(principal domain.Principal, action domain.Action, target *domain.User) error {
+	if scope := action.Scope(); !principal.HasScope(scope) {
+		return fmt.Errorf("%w: %s lacks scope %s", domain.ErrForbidden, principal.Subject, scope)
+	}
+	for _, rule := range p.rules {
+		if rule.allows(principal, action, target) {
+			return nil
+		}
+	}
+	if principal.Subject == "" {
+		return domain.ErrUnauthenticated
+	}
+	if target != nil {
+		return fmt.Errorf("%w: %s may not %s user %d", domain.ErrForbidden, principal.Subject, action, target.ID)
+	}
+	return fmt.Errorf("%w: %s may not %s users", domain.ErrForbidden, principal.Subject, action)
+}
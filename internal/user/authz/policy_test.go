@@ -0,0 +1,82 @@
+package authz
+
+import (
+	"errors"
+	"testing"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestPolicy_DefaultRules(t *testing.T) {
+	p := NewPolicy(DefaultRules...)
+	self := &domain.User{ID: 1}
+	other := &domain.User{ID: 2}
+
+	admin := domain.UserPrincipal(9, domain.RoleAdmin)
+	user := domain.UserPrincipal(1, domain.RoleUser)
+	service := domain.Principal{Subject: "service:billing", Role: domain.RoleService}
+	anonymous := domain.Principal{}
+	readKey := domain.Principal{Subject: "apikey:r", Role: domain.RoleService, Scopes: []domain.Scope{domain.ScopeUsersRead}}
+	adminKey := domain.Principal{Subject: "apikey:a", Role: domain.RoleAdmin, Scopes: []domain.Scope{domain.ScopeUsersRead, domain.ScopeUsersWrite}}
+
+	tests := []struct {
+		name      string
+		principal domain.Principal
+		action    domain.Action
+		target    *domain.User
+		wantErr   error
+	}{
+		{"admin deletes anyone", admin, domain.ActionDelete, other, nil},
+		{"admin restores", admin, domain.ActionRestore, nil, nil},
+		{"admin reads deleted", admin, domain.ActionReadDeleted, nil, nil},
+		{"admin assigns roles", admin, domain.ActionAssignRole, other, nil},
+		{"anyone registers", anonymous, domain.ActionCreate, other, nil},
+		{"user reads self", user, domain.ActionRead, self, nil},
+		{"user updates self", user, domain.ActionUpdate, self, nil},
+		{"user deletes self", user, domain.ActionDelete, self, nil},
+		{"user reads other", user, domain.ActionRead, other, domain.ErrForbidden},
+		{"user deletes other", user, domain.ActionDelete, other, domain.ErrForbidden},
+		{"user exports self", user, domain.ActionExport, self, nil},
+		{"user erases self", user, domain.ActionErase, self, nil},
+		{"user exports other", user, domain.ActionExport, other, domain.ErrForbidden},
+		{"service exports", service, domain.ActionExport, other, domain.ErrForbidden},
+		{"read-only key erases", readKey, domain.ActionErase, other, domain.ErrForbidden},
+		{"user lists", user, domain.ActionList, nil, domain.ErrForbidden},
+		{"user assigns own role", user, domain.ActionAssignRole, self, domain.ErrForbidden},
+		{"user reads deleted", user, domain.ActionReadDeleted, nil, domain.ErrForbidden},
+		{"service reads", service, domain.ActionRead, other, nil},
+		{"service lists", service, domain.ActionList, nil, nil},
+		{"service updates", service, domain.ActionUpdate, other, domain.ErrForbidden},
+		{"service reads deleted", service, domain.ActionReadDeleted, nil, domain.ErrForbidden},
+		{"anonymous reads", anonymous, domain.ActionRead, other, domain.ErrUnauthenticated},
+		{"read-only key lists", readKey, domain.ActionList, nil, nil},
+		{"read-only key updates", readKey, domain.ActionUpdate, other, domain.ErrForbidden},
+		{"admin key without admin scope restores", adminKey, domain.ActionRestore, nil, domain.ErrForbidden},
+		{"admin key deletes", adminKey, domain.ActionDelete, other, nil},
+		{"roleless user reads self", domain.UserPrincipal(1, ""), domain.ActionRead, self, domain.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Authorize(tt.principal, tt.action, tt.target)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_CustomRule(t *testing.T) {
+	p := NewPolicy(Rule{
+		Actions: []domain.Action{domain.ActionRead},
+		When: func(p domain.Principal, target *domain.User) bool {
+			return target != nil && target.Username == "public"
+		},
+	})
+
+	if err := p.Authorize(domain.Principal{}, domain.ActionRead, &domain.User{Username: "public"}); err != nil {
+		t.Errorf("Authorize() error = %v", err)
+	}
+	if err := p.Authorize(domain.Principal{}, domain.ActionRead, &domain.User{Username: "private"}); err == nil {
+		t.Errorf("Authorize() allowed a read the rule does not cover")
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"container/list"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// lru holds up to size users by ID, evicting the least recently used one
+// when full. A nil user records that the ID was not found. lru is not safe
+// for concurrent use.
+type lru struct {
+	size    int
+	order   *list.List // of *entry, most recently used first
+	entries map[int64]*list.Element
+}
+
+type entry struct {
+	id        int64
+	user      *domain.User
+	expiresAt time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), entries: make(map[int64]*list.Element)}
+}
+
+// get returns the entry for id unless it is missing or expired at now.
+func (c *lru) get(id int64, now time.Time) (*entry, bool) {
+	el, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !now.Before(e.expiresAt) {
+		c.remove(id)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e, true
+}
+
+// add stores user under id until expiresAt and reports whether another
+// entry was evicted to make room.
+func (c *lru) add(id int64, user *domain.User, expiresAt time.Time) (evicted bool) {
+	if el, ok := c.entries[id]; ok {
+		el.Value = &entry{id: id, user: user, expiresAt: expiresAt}
+		c.order.MoveToFront(el)
+		return false
+	}
+	c.entries[id] = c.order.PushFront(&entry{id: id, user: user, expiresAt: expiresAt})
+	if c.order.Len() <= c.size {
+		return false
+	}
+	c.remove(c.order.Back().Value.(*entry).id)
+	return true
+}
+
+func (c *lru) remove(id int64) {
+	if el, ok := c.entries[id]; ok {
+		c.order.Remove(el)
+		delete(c.entries, id)
+	}
+}
+
+func (c *lru) len() int {
+	return c.order.Len()
+}
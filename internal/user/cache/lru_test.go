@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newLRU(2)
+
+	assert.False(t, c.add(1, &domain.User{ID: 1}, now.Add(time.Minute)))
+	assert.False(t, c.add(2, nil, now.Add(time.Second)))
+	_, ok := c.get(1, now)
+	assert.True(t, ok)
+
+	// 2 is now the least recently used.
+	assert.True(t, c.add(3, &domain.User{ID: 3}, now.Add(time.Minute)))
+	_, ok = c.get(2, now)
+	assert.False(t, ok, "evicted")
+	e, ok := c.get(1, now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), e.user.ID)
+
+	assert.False(t, c.add(3, nil, now.Add(time.Second)), "replacing does not evict")
+	e, ok = c.get(3, now)
+	assert.True(t, ok)
+	assert.Nil(t, e.user)
+	_, ok = c.get(3, now.Add(time.Second))
+	assert.False(t, ok, "expired")
+	assert.Equal(t, 1, c.len(), "expired entries are dropped")
+
+	c.remove(1)
+	assert.Equal(t, 0, c.len())
+}
@@ -0,0 +1,226 @@
+// Package cache provides a read-through cache for a domain.UserRepository.
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultSize        = 10000
+	DefaultTTL         = time.Minute
+	DefaultNegativeTTL = 5 * time.Second
+)
+
+type Option func(*userRepository)
+
+// WithSize bounds the number of users cached; n must be positive.
+func WithSize(n int) Option {
+	return func(r *userRepository) {
+		r.entries = newLRU(n)
+	}
+}
+
+// WithTTL sets how long a user is served from the cache, and so how stale
+// it can be when another process changed it.
+func WithTTL(ttl time.Duration) Option {
+	return func(r *userRepository) {
+		r.ttl = ttl
+	}
+}
+
+// WithNegativeTTL sets how long an ID that was not found is remembered.
+// Zero disables negative caching.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(r *userRepository) {
+		r.negativeTTL = ttl
+	}
+}
+
+func WithClock(clock domain.Clock) Option {
+	return func(r *userRepository) {
+		r.clock = clock
+	}
+}
+
+type userRepository struct {
+	next        domain.UserRepository
+	clock       domain.Clock
+	ttl         time.Duration
+	negativeTTL time.Duration
+	loads       singleflight.Group
+
+	mu      sync.Mutex
+	entries *lru
+	// epoch counts invalidations. A load only stores what it read if no
+	// write invalidated the cache since it started, since the write may
+	// have changed what it read.
+	epoch uint64
+
+	lookups   *prometheus.CounterVec
+	evictions prometheus.Counter
+}
+
+// NewUserRepository caches the users next returns from GetByID, and IDs it
+// does not find, until they expire, are evicted or are written through the
+// returned repository. Other methods go straight to next. Writes made to
+// the backend by other processes are only seen once entries expire.
+func NewUserRepository(next domain.UserRepository, reg prometheus.Registerer, opts ...Option) domain.UserRepository {
+	r := &userRepository{
+		next:        next,
+		clock:       domain.SystemClock{},
+		ttl:         DefaultTTL,
+		negativeTTL: DefaultNegativeTTL,
+		entries:     newLRU(DefaultSize),
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "user_cache_lookups_total",
+			Help: "Lookups of users by ID in the cache, by result.",
+		}, []string{"result"}),
+		evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "user_cache_evictions_total",
+			Help: "Users evicted from the cache to make room for others.",
+		}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	entries := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "user_cache_entries",
+		Help: "Users and missing IDs in the cache.",
+	}, func() float64 {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return float64(r.entries.len())
+	})
+	reg.MustRegister(r.lookups, r.evictions, entries)
+	return r
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	// Deleted users are only looked up by admins, rarely, and are not cached.
+	if domain.IncludeDeleted(ctx) {
+		return r.next.GetByID(ctx, id)
+	}
+
+	r.mu.Lock()
+	e, ok := r.entries.get(id, r.clock.Now())
+	epoch := r.epoch
+	r.mu.Unlock()
+	if ok {
+		r.lookups.WithLabelValues("hit").Inc()
+		if e.user == nil {
+			return nil, domain.ErrNotFound
+		}
+		return e.user.Clone(), nil
+	}
+	r.lookups.WithLabelValues("miss").Inc()
+
+	// Concurrent misses of the same ID share one load, which must not fail
+	// because the caller that started it went away. It still keeps to that
+	// caller's deadline.
+	key := strconv.FormatInt(id, 10) + "@" + strconv.FormatUint(epoch, 10)
+	loaded := r.loads.DoChan(key, func() (any, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			loadCtx, cancel = context.WithDeadline(loadCtx, deadline)
+			defer cancel()
+		}
+		return r.load(loadCtx, id, epoch)
+	})
+	select {
+	case res := <-loaded:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*domain.User).Clone(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load reads the user from next and caches the result, if it is still
+// current.
+func (r *userRepository) load(ctx context.Context, id int64, epoch uint64) (*domain.User, error) {
+	user, err := r.next.GetByID(ctx, id)
+	ttl := r.ttl
+	switch {
+	case err == nil:
+		user = user.Clone()
+	case errors.Is(err, domain.ErrNotFound):
+		user, ttl = nil, r.negativeTTL
+	default:
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.epoch == epoch && ttl > 0 && r.entries.add(id, user, r.clock.Now().Add(ttl)) {
+		r.evictions.Inc()
+	}
+	return user, err
+}
+
+// invalidate drops the cached entries of ids. Writes invalidate whether or
+// not they succeed, since a write that failed, for example by timing out,
+// may still have been applied.
+func (r *userRepository) invalidate(ids ...int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.epoch++
+	for _, id := range ids {
+		r.entries.remove(id)
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	defer r.invalidate(user.ID)
+	return r.next.Create(ctx, user)
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return r.next.GetByUsername(ctx, username)
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	defer r.invalidate(user.ID)
+	return r.next.Update(ctx, user)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int64, at time.Time) error {
+	defer r.invalidate(id)
+	return r.next.Delete(ctx, id, at)
+}
+
+func (r *userRepository) List(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	return r.next.List(ctx, opts)
+}
+
+func (r *userRepository) Restore(ctx context.Context, id int64) (*domain.User, error) {
+	defer r.invalidate(id)
+	return r.next.Restore(ctx, id)
+}
+
+func (r *userRepository) VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error {
+	defer r.invalidate(id)
+	return r.next.VerifyEmail(ctx, id, email, at)
+}
+
+// Purge needs no invalidation: purged users were deleted, so GetByID
+// already found them missing.
+func (r *userRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	return r.next.Purge(ctx, cutoff)
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, id int64, at time.Time) (*domain.User, error) {
+	defer r.invalidate(id)
+	return r.next.Anonymize(ctx, id, at)
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/repository"
+	"repo-guardian/internal/user/repository/repotest"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type manualClock struct{ now time.Time }
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+// countingRepository counts the calls to GetByID and, while gate is set,
+// blocks them until it is closed.
+type countingRepository struct {
+	domain.UserRepository
+	gets atomic.Int32
+	gate chan struct{}
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	r.gets.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func newTestRepository(t *testing.T, opts ...Option) (domain.UserRepository, *countingRepository, *prometheus.Registry, *manualClock) {
+	t.Helper()
+	backend := &countingRepository{UserRepository: repository.NewMemoryUserRepository()}
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	reg := prometheus.NewRegistry()
+	repo := NewUserRepository(backend, reg, append([]Option{WithClock(clock)}, opts...)...)
+	assert.NoError(t, backend.Create(context.Background(), &domain.User{ID: 1, Username: "john", Email: "john@example.com"}))
+	return repo, backend, reg, clock
+}
+
+func TestUserRepository_GetByID(t *testing.T) {
+	repo, backend, reg, clock := newTestRepository(t, WithTTL(time.Minute), WithNegativeTTL(time.Second))
+	ctx := context.Background()
+
+	user, err := repo.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	user.Username = "changed"
+	user, err = repo.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "john", user.Username, "callers get copies")
+	assert.EqualValues(t, 1, backend.gets.Load())
+
+	for range 2 {
+		_, err = repo.GetByID(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	}
+	assert.EqualValues(t, 2, backend.gets.Load(), "not found is cached")
+
+	clock.now = clock.now.Add(time.Second)
+	_, err = repo.GetByID(ctx, 2)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	clock.now = clock.now.Add(time.Minute)
+	_, err = repo.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, backend.gets.Load(), "entries expire")
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP user_cache_entries Users and missing IDs in the cache.
+# TYPE user_cache_entries gauge
+user_cache_entries 2
+# HELP user_cache_lookups_total Lookups of users by ID in the cache, by result.
+# TYPE user_cache_lookups_total counter
+user_cache_lookups_total{result="hit"} 2
+user_cache_lookups_total{result="miss"} 4
+`), "user_cache_entries", "user_cache_lookups_total")
+	assert.NoError(t, err)
+}
+
+func TestUserRepository_Invalidation(t *testing.T) {
+	repo, backend, _, clock := newTestRepository(t)
+	ctx := context.Background()
+	get := func() (*domain.User, error) {
+		t.Helper()
+		// A second read must come from the cache.
+		before := backend.gets.Load()
+		_, _ = repo.GetByID(ctx, 1)
+		user, err := repo.GetByID(ctx, 1)
+		assert.Equal(t, before+1, backend.gets.Load())
+		return user, err
+	}
+
+	_, err := get()
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Update(ctx, &domain.User{ID: 1, Username: "jack", Email: "john@example.com"}))
+	user, err := get()
+	assert.NoError(t, err)
+	assert.Equal(t, "jack", user.Username)
+
+	assert.NoError(t, repo.VerifyEmail(ctx, 1, "john@example.com", clock.now))
+	user, err = get()
+	assert.NoError(t, err)
+	assert.True(t, user.EmailVerified())
+
+	assert.NoError(t, repo.Delete(ctx, 1, clock.now))
+	_, err = get()
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repo.Restore(ctx, 1)
+	assert.NoError(t, err)
+	_, err = get()
+	assert.NoError(t, err)
+
+	_, err = repo.Anonymize(ctx, 1, clock.now)
+	assert.NoError(t, err)
+	_, err = get()
+	assert.ErrorIs(t, err, domain.ErrNotFound, "anonymized users are deleted")
+
+	_, err = repo.GetByID(ctx, 3)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.NoError(t, repo.Create(ctx, &domain.User{ID: 3, Username: "jane", Email: "jane@example.com"}))
+	_, err = repo.GetByID(ctx, 3)
+	assert.NoError(t, err, "creating drops the cached miss")
+}
+
+func TestUserRepository_Eviction(t *testing.T) {
+	repo, backend, reg, _ := newTestRepository(t, WithSize(1))
+	ctx := context.Background()
+	assert.NoError(t, repo.Create(ctx, &domain.User{ID: 2, Username: "jane", Email: "jane@example.com"}))
+
+	for _, id := range []int64{1, 2, 1} {
+		_, err := repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 3, backend.gets.Load())
+	err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP user_cache_evictions_total Users evicted from the cache to make room for others.
+# TYPE user_cache_evictions_total counter
+user_cache_evictions_total 2
+`), "user_cache_evictions_total")
+	assert.NoError(t, err)
+}
+
+func TestUserRepository_IncludeDeleted(t *testing.T) {
+	repo, backend, _, clock := newTestRepository(t)
+	ctx := context.Background()
+	assert.NoError(t, repo.Delete(ctx, 1, clock.now))
+
+	_, err := repo.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	user, err := repo.GetByID(domain.WithDeleted(ctx), 1)
+	assert.NoError(t, err)
+	assert.NotNil(t, user.DeletedAt)
+	_, err = repo.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.EqualValues(t, 2, backend.gets.Load(), "lookups of deleted users bypass the cache")
+}
+
+func TestUserRepository_ConcurrentMisses(t *testing.T) {
+	repo, backend, _, _ := newTestRepository(t)
+	backend.gate = make(chan struct{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := repo.GetByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "john", user.Username)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(repo.(*userRepository).lookups.WithLabelValues("miss")) == callers
+	}, time.Second, time.Millisecond)
+	// Give the last caller time to join the load after counting its miss.
+	time.Sleep(10 * time.Millisecond)
+	close(backend.gate)
+	wg.Wait()
+	assert.EqualValues(t, 1, backend.gets.Load())
+}
+
+func TestUserRepository_StaleLoad(t *testing.T) {
+	repo, backend, _, _ := newTestRepository(t)
+	ctx := context.Background()
+	backend.gate = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := repo.GetByID(ctx, 1)
+		assert.NoError(t, err)
+	}()
+	assert.Eventually(t, func() bool { return backend.gets.Load() == 1 }, time.Second, time.Millisecond)
+	// The load in flight may have read the user before this update.
+	assert.NoError(t, repo.Update(ctx, &domain.User{ID: 1, Username: "jack", Email: "john@example.com"}))
+	close(backend.gate)
+	<-done
+
+	user, err := repo.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "jack", user.Username)
+	assert.EqualValues(t, 2, backend.gets.Load())
+}
+
+func TestUserRepository_CallerCanceled(t *testing.T) {
+	repo, backend, _, _ := newTestRepository(t)
+	backend.gate = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := repo.GetByID(ctx, 1)
+		canceled <- err
+	}()
+	assert.Eventually(t, func() bool { return backend.gets.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+
+	close(backend.gate)
+	_, err := repo.GetByID(context.Background(), 1)
+	assert.NoError(t, err, "the load outlives the caller that started it")
+	assert.EqualValues(t, 1, backend.gets.Load())
+}
+
+func TestUserRepository_Contract(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) domain.UserRepository {
+		return NewUserRepository(repository.NewMemoryUserRepository(), prometheus.NewRegistry())
+	})
+}
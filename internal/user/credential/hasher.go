@@ -0,0 +1,147 @@
+package credential
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"repo-guardian/internal/domain"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Argon2idParams are the tunable argon2id cost parameters. Changing them
+// makes existing hashes report needsRehash on their next successful login.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams follow the OWASP recommendation for argon2id.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher returns a hasher producing PHC-formatted argon2id hashes.
+// It still verifies bcrypt hashes so that users can be migrated on login.
+func NewArgon2idHasher(params Argon2idParams) domain.PasswordHasher {
+	return &argon2idHasher{params: params}
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *argon2idHasher) Verify(password, encoded string) (bool, bool, error) {
+	if isBcrypt(encoded) {
+		ok, _, err := verifyBcrypt(password, encoded, 0)
+		return ok, true, err
+	}
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, false, err
+	}
+	got := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(got, key) != 1 {
+		return false, false, nil
+	}
+	params.SaltLength = uint32(len(salt))
+	return true, params != h.params, nil
+}
+
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a bcrypt hasher with the given cost. Note that
+// bcrypt only accepts passwords up to 72 bytes.
+func NewBcryptHasher(cost int) domain.PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) Verify(password, encoded string) (bool, bool, error) {
+	if !isBcrypt(encoded) {
+		// Hashes from the argon2id hasher are still accepted, and moved over
+		// to bcrypt once verified.
+		ok, _, err := (&argon2idHasher{}).Verify(password, encoded)
+		return ok, true, err
+	}
+	return verifyBcrypt(password, encoded, h.cost)
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func verifyBcrypt(password, encoded string, cost int) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	stored, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, false, err
+	}
+	return true, stored != cost, nil
+}
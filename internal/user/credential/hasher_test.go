@@ -0,0 +1,91 @@
+package credential
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keep argon2id cheap enough for unit tests.
+var testParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	h := NewArgon2idHasher(testParams)
+
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash() = %q, want a PHC argon2id string", hash)
+	}
+	if other, _ := h.Hash("correct horse"); other == hash {
+		t.Errorf("Hash() returned the same hash twice; salts are not random")
+	}
+
+	ok, rehash, err := h.Verify("correct horse", hash)
+	if err != nil || !ok || rehash {
+		t.Errorf("Verify() = %v, %v, %v, want true, false, nil", ok, rehash, err)
+	}
+	ok, _, err = h.Verify("wrong", hash)
+	if err != nil || ok {
+		t.Errorf("Verify() with wrong password = %v, %v, want false, nil", ok, err)
+	}
+
+	stronger := testParams
+	stronger.Iterations = 2
+	ok, rehash, err = NewArgon2idHasher(stronger).Verify("correct horse", hash)
+	if err != nil || !ok || !rehash {
+		t.Errorf("Verify() with new params = %v, %v, %v, want true, true, nil", ok, rehash, err)
+	}
+}
+
+func TestArgon2idHasher_VerifiesBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, rehash, err := NewArgon2idHasher(testParams).Verify("correct horse", string(legacy))
+	if err != nil || !ok || !rehash {
+		t.Errorf("Verify() = %v, %v, %v, want true, true, nil", ok, rehash, err)
+	}
+}
+
+func TestBcryptHasher(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	ok, rehash, err := h.Verify("correct horse", hash)
+	if err != nil || !ok || rehash {
+		t.Errorf("Verify() = %v, %v, %v, want true, false, nil", ok, rehash, err)
+	}
+	ok, _, err = h.Verify("wrong", hash)
+	if err != nil || ok {
+		t.Errorf("Verify() with wrong password = %v, %v, want false, nil", ok, err)
+	}
+
+	ok, rehash, err = NewBcryptHasher(bcrypt.MinCost+1).Verify("correct horse", hash)
+	if err != nil || !ok || !rehash {
+		t.Errorf("Verify() with new cost = %v, %v, %v, want true, true, nil", ok, rehash, err)
+	}
+
+	argon, _ := NewArgon2idHasher(testParams).Hash("correct horse")
+	ok, rehash, err = h.Verify("correct horse", argon)
+	if err != nil || !ok || !rehash {
+		t.Errorf("Verify() of argon2id hash = %v, %v, %v, want true, true, nil", ok, rehash, err)
+	}
+}
+
+func TestVerify_UnknownFormat(t *testing.T) {
+	for _, encoded := range []string{"", "plaintext", "$argon2i$v=19$m=64,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=64,t=1,p=1$!!$a2V5"} {
+		if _, _, err := NewArgon2idHasher(testParams).Verify("pw", encoded); !errors.Is(err, ErrUnknownHashFormat) {
+			t.Errorf("Verify(%q) error = %v, want %v", encoded, err, ErrUnknownHashFormat)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package credential
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"repo-guardian/internal/domain"
+)
+
+type Policy struct {
+	MinLength int
+	MaxLength int
+	breached  map[string]struct{}
+}
+
+// NewPolicy returns a policy enforcing length limits in characters. Use
+// LoadBreachedPasswords to also reject known breached passwords.
+func NewPolicy(minLength, maxLength int) *Policy {
+	return &Policy{
+		MinLength: minLength,
+		MaxLength: maxLength,
+		breached:  make(map[string]struct{}),
+	}
+}
+
+// LoadBreachedPasswords reads a list of breached passwords, one per line.
+// Blank lines and lines starting with '#' are ignored.
+func (p *Policy) LoadBreachedPasswords(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return p.ReadBreachedPasswords(f)
+}
+
+func (p *Policy) ReadBreachedPasswords(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[line] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+func (p *Policy) Check(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", domain.ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && n > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", domain.ErrWeakPassword, p.MaxLength)
+	}
+	if _, breached := p.breached[password]; breached {
+		return fmt.Errorf("%w: appears in a list of breached passwords", domain.ErrWeakPassword)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package credential
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := NewPolicy(8, 16)
+	if err := p.ReadBreachedPasswords(strings.NewReader("# common\npassword123\n\nqwertyuiop\r\n")); err != nil {
+		t.Fatalf("ReadBreachedPasswords() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"ok", "correct horse", false},
+		{"too short", "short", true},
+		{"counts characters not bytes", "пароль12", false},
+		{"too long", strings.Repeat("a", 17), true},
+		{"breached", "password123", true},
+		{"breached with CRLF", "qwertyuiop", true},
+		{"comment is not a password", "# common", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check(%q) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrWeakPassword) {
+				t.Errorf("Check(%q) error = %v, want %v", tt.password, err, domain.ErrWeakPassword)
+			}
+		})
+	}
+}
+
+func TestPolicy_LoadBreachedPasswords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte("letmein!letmein\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPolicy(8, 0)
+	if err := p.LoadBreachedPasswords(path); err != nil {
+		t.Fatalf("LoadBreachedPasswords() error = %v", err)
+	}
+	if err := p.Check("letmein!letmein"); !errors.Is(err, domain.ErrWeakPassword) {
+		t.Errorf("Check() error = %v, want %v", err, domain.ErrWeakPassword)
+	}
+	if err := p.LoadBreachedPasswords(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("LoadBreachedPasswords() of a missing file returned no error")
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccessLog logs each request once it is handled, with the context of the
+// request so that the record carries its request ID and trace. Server
+// errors are logged at error level.
+func AccessLog(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		self := c.Route()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(c.UserContext(), level, "request",
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", routePattern(c, self)),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", len(c.Response().Body())),
+			slog.String("ip", c.IP()),
+		)
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"repo-guardian/internal/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	app := fiber.New()
+	app.Use(RequestID(), AccessLog(slog.New(logging.NewHandler(&buf, "json", slog.LevelInfo))))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "0" {
+			return fiber.NewError(http.StatusInternalServerError, "boom")
+		}
+		return c.SendString("hello")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	for _, req := range []*http.Request{req, httptest.NewRequest(http.MethodGet, "/users/0", nil)} {
+		_, err := app.Test(req)
+		assert.NoError(t, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	var ok, failed map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &ok))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &failed))
+
+	assert.Equal(t, "INFO", ok["level"])
+	assert.Equal(t, "request", ok["msg"])
+	assert.Equal(t, "req-1", ok["request_id"])
+	assert.Equal(t, "GET", ok["method"])
+	assert.Equal(t, "/users/1", ok["path"])
+	assert.Equal(t, "/users/:id", ok["route"])
+	assert.Equal(t, 200.0, ok["status"])
+	assert.Equal(t, 5.0, ok["bytes"])
+	assert.Contains(t, ok, "latency")
+
+	assert.Equal(t, "ERROR", failed["level"])
+	assert.Equal(t, 500.0, failed["status"])
+	assert.NotEmpty(t, failed["request_id"])
+}
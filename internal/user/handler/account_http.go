@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountHandler struct {
+	AccountUsecase domain.AccountUsecase
+}
+
+func NewAccountHandler(f *fiber.App, us domain.AccountUsecase) {
+	handler := &AccountHandler{
+		AccountUsecase: us,
+	}
+	f.Post("/users/:id/verification-email", handler.RequestEmailVerification)
+	f.Post("/auth/verify-email", handler.VerifyEmail)
+	f.Post("/auth/password-reset", handler.RequestPasswordReset)
+	f.Post("/auth/password-reset/confirm", handler.ResetPassword)
+}
+
+type tokenRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (h *AccountHandler) RequestEmailVerification(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := h.AccountUsecase.RequestEmailVerification(c.UserContext(), id); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusAccepted)
+}
+
+func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req tokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Token == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "token is required"})
+	}
+
+	if err := h.AccountUsecase.VerifyEmail(c.UserContext(), req.Token); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *AccountHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	var req loginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Username == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "username is required"})
+	}
+
+	if err := h.AccountUsecase.RequestPasswordReset(c.UserContext(), req.Username); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusAccepted)
+}
+
+func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req tokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Token == "" || req.Password == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "token and password are required"})
+	}
+
+	if err := h.AccountUsecase.ResetPassword(c.UserContext(), req.Token, req.Password); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
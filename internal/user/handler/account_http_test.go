@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAccountUsecase) RequestEmailVerification(ctx context.Context, userID int64) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockAccountUsecase) VerifyEmail(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+func (m *MockAccountUsecase) RequestPasswordReset(ctx context.Context, username string) error {
+	return m.Called(ctx, username).Error(0)
+}
+
+func (m *MockAccountUsecase) ResetPassword(ctx context.Context, token, password string) error {
+	return m.Called(ctx, token, password).Error(0)
+}
+
+func TestAccountHandler(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockAccountUsecase)
+	NewAccountHandler(app, mockUsecase)
+
+	t.Run("RequestEmailVerification", func(t *testing.T) {
+		mockUsecase.On("RequestEmailVerification", mock.Anything, int64(1)).Return(nil).Once()
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/users/1/verification-email", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/users/abc/verification-email", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("VerifyEmail", func(t *testing.T) {
+		mockUsecase.On("VerifyEmail", mock.Anything, "good").Return(nil).Once()
+		mockUsecase.On("VerifyEmail", mock.Anything, "used").Return(domain.ErrTokenReused).Once()
+
+		assert.Equal(t, http.StatusNoContent, postJSON(t, app, "/auth/verify-email", `{"token":"good"}`).StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, postJSON(t, app, "/auth/verify-email", `{"token":"used"}`).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/auth/verify-email", `{}`).StatusCode)
+	})
+
+	t.Run("RequestPasswordReset", func(t *testing.T) {
+		mockUsecase.On("RequestPasswordReset", mock.Anything, "john").Return(nil).Once()
+
+		assert.Equal(t, http.StatusAccepted, postJSON(t, app, "/auth/password-reset", `{"username":"john"}`).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/auth/password-reset", `{}`).StatusCode)
+	})
+
+	t.Run("ResetPassword", func(t *testing.T) {
+		mockUsecase.On("ResetPassword", mock.Anything, "good", "correct horse").Return(nil).Once()
+		mockUsecase.On("ResetPassword", mock.Anything, "good", "short").Return(domain.ErrWeakPassword).Once()
+
+		assert.Equal(t, http.StatusNoContent, postJSON(t, app, "/auth/password-reset/confirm", `{"token":"good","password":"correct horse"}`).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/auth/password-reset/confirm", `{"token":"good","password":"short"}`).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/auth/password-reset/confirm", `{"token":"good"}`).StatusCode)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler struct {
+	APIKeyUsecase domain.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(f *fiber.App, us domain.APIKeyUsecase) {
+	handler := &APIKeyHandler{
+		APIKeyUsecase: us,
+	}
+	f.Post("/api-keys", handler.Create)
+	f.Get("/api-keys", handler.List)
+	f.Delete("/api-keys/:id", handler.Revoke)
+}
+
+type createAPIKeyRequest struct {
+	Name   string         `json:"name"`
+	Role   domain.Role    `json:"role"`
+	Scopes []domain.Scope `json:"scopes"`
+}
+
+// createAPIKeyResponse is the only response that includes the key itself.
+type createAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	var req createAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	key := &domain.APIKey{Name: req.Name, Role: req.Role, Scopes: req.Scopes}
+	secret, err := h.APIKeyUsecase.Create(c.UserContext(), key)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusCreated).JSON(createAPIKeyResponse{APIKey: key, Key: secret})
+}
+
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	keys, err := h.APIKeyUsecase.List(c.UserContext())
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.JSON(fiber.Map{"data": keys})
+}
+
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	if err := h.APIKeyUsecase.Revoke(c.UserContext(), c.Params("id")); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyUsecase) Create(ctx context.Context, key *domain.APIKey) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) List(ctx context.Context) ([]*domain.APIKey, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).([]*domain.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) Revoke(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyUsecase) Authenticate(ctx context.Context, secret string) (domain.Principal, error) {
+	args := m.Called(ctx, secret)
+	return args.Get(0).(domain.Principal), args.Error(1)
+}
+
+func TestAPIKeyHandler_Create(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockAPIKeyUsecase)
+	NewAPIKeyHandler(app, mockUsecase)
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(k *domain.APIKey) bool {
+			return k.Name == "billing" && len(k.Scopes) == 1 && k.Scopes[0] == domain.ScopeUsersRead
+		})).Run(func(args mock.Arguments) {
+			key := args.Get(1).(*domain.APIKey)
+			key.ID, key.Role, key.Hash = "abc123", domain.RoleService, "stored-hash"
+		}).Return("rg_abc123_secret", nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(`{"name":"billing","scopes":["users:read"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "rg_abc123_secret", body["key"])
+		assert.Equal(t, "abc123", body["id"])
+		assert.NotContains(t, body, "hash")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("Create", mock.Anything, mock.Anything).Return("", domain.ErrForbidden).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(`{"name":"billing","scopes":["users:read"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAPIKeyHandler_ListAndRevoke(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockAPIKeyUsecase)
+	NewAPIKeyHandler(app, mockUsecase)
+
+	mockUsecase.On("List", mock.Anything).Return([]*domain.APIKey{{ID: "abc123", Name: "billing", Hash: "stored-hash"}}, nil).Once()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api-keys", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	assert.Len(t, list.Data, 1)
+	assert.NotContains(t, list.Data[0], "hash")
+
+	mockUsecase.On("Revoke", mock.Anything, "abc123").Return(nil).Once()
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/api-keys/abc123", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	mockUsecase.On("Revoke", mock.Anything, "missing").Return(domain.ErrAPIKeyNotFound).Once()
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/api-keys/missing", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	AuditUsecase domain.AuditUsecase
+}
+
+func NewAuditHandler(f *fiber.App, us domain.AuditUsecase) {
+	handler := &AuditHandler{
+		AuditUsecase: us,
+	}
+	f.Get("/audit", handler.Query)
+}
+
+// Query lists audit events oldest first. The target parameter takes the form
+// "user:42"; a bare user ID is accepted too.
+func (h *AuditHandler) Query(c *fiber.Ctx) error {
+	filter := domain.AuditFilter{Target: c.Query("target")}
+	if id, err := strconv.ParseInt(filter.Target, 10, 64); err == nil {
+		filter.Target = domain.UserTarget(id)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+		}
+		filter.Limit = n
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
+		filter.After = after
+	}
+
+	events, err := h.AuditUsecase.Query(c.UserContext(), filter)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	if events == nil {
+		events = []*domain.AuditEvent{}
+	}
+	resp := fiber.Map{"data": events}
+	// A full page may be followed by more events.
+	if limit := filter.Limit; len(events) > 0 && (len(events) == limit || limit == 0 && len(events) == domain.DefaultListLimit) {
+		cursor := strconv.FormatInt(events[len(events)-1].ID, 10)
+		resp["next_cursor"] = cursor
+		c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="next"`, nextPageURL(c, cursor)))
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuditUsecase) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	args := m.Called(ctx, filter)
+	events, _ := args.Get(0).([]*domain.AuditEvent)
+	return events, args.Error(1)
+}
+
+func TestAuditHandler_Query(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockAuditUsecase)
+	NewAuditHandler(app, mockUsecase)
+
+	get := func(target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		events := []*domain.AuditEvent{
+			{ID: 3, Action: domain.ActionUpdate, Target: "user:42"},
+			{ID: 5, Action: domain.ActionDelete, Target: "user:42", Actor: "user:1"},
+		}
+		mockUsecase.On("Query", mock.Anything, domain.AuditFilter{Target: "user:42", After: 2, Limit: 2}).Return(events, nil).Once()
+
+		resp := get("/audit?target=42&cursor=2&limit=2")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var body struct {
+			Data       []*domain.AuditEvent `json:"data"`
+			NextCursor string               `json:"next_cursor"`
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(raw, &body))
+		assert.Equal(t, events, body.Data)
+		assert.Equal(t, "5", body.NextCursor)
+		assert.Contains(t, resp.Header.Get("Link"), "cursor=5")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		mockUsecase.On("Query", mock.Anything, domain.AuditFilter{Target: "user:7"}).Return(nil, nil).Once()
+
+		resp := get("/audit?target=user:7")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		raw, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"data":[]}`, string(raw))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("Query", mock.Anything, domain.AuditFilter{}).Return(nil, domain.ErrForbidden).Once()
+
+		resp := get("/audit")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/audit?limit=abc").StatusCode)
+		assert.Equal(t, http.StatusBadRequest, get("/audit?cursor=abc").StatusCode)
+	})
+}
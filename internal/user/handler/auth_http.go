@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthHandler struct {
+	AuthUsecase  domain.AuthUsecase
+	TokenUsecase domain.TokenUsecase
+}
+
+func NewAuthHandler(f *fiber.App, us domain.AuthUsecase, ts domain.TokenUsecase) {
+	handler := &AuthHandler{
+		AuthUsecase:  us,
+		TokenUsecase: ts,
+	}
+	f.Post("/auth/login", handler.Login)
+	f.Post("/auth/login/mfa", handler.LoginMFA)
+	f.Post("/auth/refresh", handler.Refresh)
+	f.Post("/auth/logout", handler.Logout)
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type loginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req loginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Username == "" || req.Password == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "username and password are required"})
+	}
+
+	result, err := h.AuthUsecase.Login(c.UserContext(), req.Username, req.Password)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+	if result.MFAToken != "" {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(fiber.Map{"mfa_required": true, "mfa_token": result.MFAToken})
+	}
+	return h.issue(c, result.User)
+}
+
+func (h *AuthHandler) LoginMFA(c *fiber.Ctx) error {
+	var req loginMFARequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "mfa_token and code are required"})
+	}
+
+	user, err := h.AuthUsecase.CompleteLogin(c.UserContext(), req.MFAToken, req.Code)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+	return h.issue(c, user)
+}
+
+func (h *AuthHandler) issue(c *fiber.Ctx, user *domain.User) error {
+	tokens, err := h.TokenUsecase.Issue(c.UserContext(), user)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(tokens)
+}
+
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	req, err := parseRefreshRequest(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tokens, err := h.TokenUsecase.Refresh(c.UserContext(), req.RefreshToken)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(tokens)
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	req, err := parseRefreshRequest(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.TokenUsecase.Revoke(c.UserContext(), req.RefreshToken); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func parseRefreshRequest(c *fiber.Ctx) (refreshRequest, error) {
+	var req refreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return req, err
+	}
+	if req.RefreshToken == "" {
+		return req, fiber.NewError(http.StatusBadRequest, "refresh_token is required")
+	}
+	return req, nil
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUsecase) Login(ctx context.Context, username, password string) (*domain.LoginResult, error) {
+	args := m.Called(ctx, username, password)
+	result, _ := args.Get(0).(*domain.LoginResult)
+	return result, args.Error(1)
+}
+
+func (m *MockAuthUsecase) CompleteLogin(ctx context.Context, mfaToken, code string) (*domain.User, error) {
+	args := m.Called(ctx, mfaToken, code)
+	user, _ := args.Get(0).(*domain.User)
+	return user, args.Error(1)
+}
+
+type MockTokenUsecase struct {
+	mock.Mock
+}
+
+func (m *MockTokenUsecase) Issue(ctx context.Context, user *domain.User) (*domain.TokenPair, error) {
+	args := m.Called(ctx, user)
+	pair, _ := args.Get(0).(*domain.TokenPair)
+	return pair, args.Error(1)
+}
+
+func (m *MockTokenUsecase) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	pair, _ := args.Get(0).(*domain.TokenPair)
+	return pair, args.Error(1)
+}
+
+func (m *MockTokenUsecase) Revoke(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockTokenUsecase) Authenticate(ctx context.Context, accessToken string) (domain.Principal, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(domain.Principal), args.Error(1)
+}
+
+func postJSON(t *testing.T, app *fiber.App, path, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockAuthUsecase)
+	mockTokens := new(MockTokenUsecase)
+	NewAuthHandler(app, mockUsecase, mockTokens)
+
+	login := func(body string) *http.Response {
+		return postJSON(t, app, "/auth/login", body)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		user := &domain.User{ID: 1, Username: "john", Email: "john@example.com"}
+		pair := &domain.TokenPair{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh"}
+		mockUsecase.On("Login", mock.Anything, "john", "correct horse").Return(&domain.LoginResult{User: user}, nil).Once()
+		mockTokens.On("Issue", mock.Anything, user).Return(pair, nil).Once()
+
+		resp := login(`{"username":"john","password":"correct horse"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+
+		var got domain.TokenPair
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, *pair, got)
+		mockUsecase.AssertExpectations(t)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("MFARequired", func(t *testing.T) {
+		mockUsecase.On("Login", mock.Anything, "john", "correct horse").Return(&domain.LoginResult{MFAToken: "mfa"}, nil).Once()
+
+		resp := login(`{"username":"john","password":"correct horse"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"mfa_required":true,"mfa_token":"mfa"}`, string(body))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		mockUsecase.On("Login", mock.Anything, "john", "wrong").Return(nil, domain.ErrInvalidCredentials).Once()
+
+		resp := login(`{"username":"john","password":"wrong"}`)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("MissingFields", func(t *testing.T) {
+		resp := login(`{"username":"john"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		resp := login(`invalid json`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAuthHandler_LoginMFA(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockAuthUsecase)
+	mockTokens := new(MockTokenUsecase)
+	NewAuthHandler(app, mockUsecase, mockTokens)
+
+	t.Run("Success", func(t *testing.T) {
+		user := &domain.User{ID: 1, Username: "john", Email: "john@example.com"}
+		pair := &domain.TokenPair{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh"}
+		mockUsecase.On("CompleteLogin", mock.Anything, "mfa", "123456").Return(user, nil).Once()
+		mockTokens.On("Issue", mock.Anything, user).Return(pair, nil).Once()
+
+		resp := postJSON(t, app, "/auth/login/mfa", `{"mfa_token":"mfa","code":"123456"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+		mockUsecase.AssertExpectations(t)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCode", func(t *testing.T) {
+		mockUsecase.On("CompleteLogin", mock.Anything, "mfa", "000000").Return(nil, domain.ErrInvalidMFACode).Once()
+
+		resp := postJSON(t, app, "/auth/login/mfa", `{"mfa_token":"mfa","code":"000000"}`)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("MissingFields", func(t *testing.T) {
+		resp := postJSON(t, app, "/auth/login/mfa", `{"mfa_token":"mfa"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	app := fiber.New()
+	mockTokens := new(MockTokenUsecase)
+	NewAuthHandler(app, new(MockAuthUsecase), mockTokens)
+
+	t.Run("Success", func(t *testing.T) {
+		pair := &domain.TokenPair{AccessToken: "access2", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh2"}
+		mockTokens.On("Refresh", mock.Anything, "refresh").Return(pair, nil).Once()
+
+		resp := postJSON(t, app, "/auth/refresh", `{"refresh_token":"refresh"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got domain.TokenPair
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, *pair, got)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		mockTokens.On("Refresh", mock.Anything, "stolen").Return(nil, domain.ErrTokenReused).Once()
+
+		resp := postJSON(t, app, "/auth/refresh", `{"refresh_token":"stolen"}`)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		resp := postJSON(t, app, "/auth/refresh", `{}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	app := fiber.New()
+	mockTokens := new(MockTokenUsecase)
+	NewAuthHandler(app, new(MockAuthUsecase), mockTokens)
+
+	mockTokens.On("Revoke", mock.Anything, "refresh").Return(nil).Once()
+
+	resp := postJSON(t, app, "/auth/logout", `{"refresh_token":"refresh"}`)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	mockTokens.AssertExpectations(t)
+
+	resp = postJSON(t, app, "/auth/logout", `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// CORS lets browsers on the allowed origins call the API. Its settings can
+// be replaced with Update while it serves requests.
+type CORS struct {
+	handler atomic.Pointer[fiber.Handler]
+}
+
+func NewCORS(origins []string, allowCredentials bool, maxAge time.Duration) *CORS {
+	c := &CORS{}
+	c.Update(origins, allowCredentials, maxAge)
+	return c
+}
+
+// Update replaces the settings. No origins turns CORS off. Allowing any
+// origin, "*", with credentials is rejected by browsers and panics.
+func (c *CORS) Update(origins []string, allowCredentials bool, maxAge time.Duration) {
+	h := func(ctx *fiber.Ctx) error { return ctx.Next() }
+	if len(origins) > 0 {
+		h = cors.New(cors.Config{
+			AllowOrigins:     strings.Join(origins, ","),
+			AllowMethods:     "GET,POST,PUT,PATCH,DELETE,HEAD",
+			AllowHeaders:     "Authorization,Content-Type,If-Match,X-Request-ID",
+			AllowCredentials: allowCredentials,
+			ExposeHeaders:    "ETag,Location,Link,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,X-Request-ID",
+			MaxAge:           int(maxAge / time.Second),
+		})
+	}
+	c.handler.Store(&h)
+}
+
+func (c *CORS) Handle(ctx *fiber.Ctx) error {
+	return (*c.handler.Load())(ctx)
+}
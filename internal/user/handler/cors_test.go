@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	c := NewCORS(nil, false, 0)
+	app := fiber.New()
+	app.Use(c.Handle)
+	app.Get("/users", func(ctx *fiber.Ctx) error { return ctx.SendStatus(http.StatusOK) })
+
+	preflight := func(origin string) *http.Response {
+		req := httptest.NewRequest(http.MethodOptions, "/users", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	assert.Empty(t, preflight("https://app.example.com").Header.Get("Access-Control-Allow-Origin"))
+
+	c.Update([]string{"https://app.example.com"}, true, 10*time.Minute)
+	resp := preflight("https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", resp.Header.Get("Access-Control-Max-Age"))
+	assert.Empty(t, preflight("https://evil.example.com").Header.Get("Access-Control-Allow-Origin"))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Access-Control-Expose-Headers"), "ETag")
+
+	c.Update(nil, false, 0)
+	assert.Empty(t, preflight("https://app.example.com").Header.Get("Access-Control-Allow-Origin"))
+}
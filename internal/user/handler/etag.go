@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errInvalidPrecondition = errors.New("If-Match must be \"*\" or a single strong entity tag")
+
+func etag(user *domain.User) string {
+	return `"` + strconv.FormatInt(user.Version, 10) + `"`
+}
+
+func setETag(c *fiber.Ctx, user *domain.User) {
+	c.Set(fiber.HeaderETag, etag(user))
+}
+
+// withIfMatch turns the request's If-Match header into a version
+// precondition on ctx. A wildcard only requires the user to exist, which the
+// write already does.
+func withIfMatch(ctx context.Context, c *fiber.Ctx) (context.Context, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return ctx, nil
+	}
+	if strings.Contains(header, ",") {
+		return nil, errInvalidPrecondition
+	}
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		// Weak tags and tags we never issued cannot match under the strong
+		// comparison If-Match requires.
+		return domain.WithExpectedVersion(ctx, -1), nil
+	}
+	return domain.WithExpectedVersion(ctx, version), nil
+}
+
+// noneMatch reports whether If-None-Match lists the user's current entity
+// tag, using the weak comparison RFC 9110 prescribes for GET.
+func noneMatch(c *fiber.Ctx, user *domain.User) bool {
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+	current := etag(user)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	HealthUsecase domain.HealthUsecase
+}
+
+// NewHealthHandler registers the probes. Register it before any middleware
+// so that probes are neither authenticated nor rate limited.
+func NewHealthHandler(f *fiber.App, us domain.HealthUsecase) {
+	handler := &HealthHandler{
+		HealthUsecase: us,
+	}
+	f.Get("/livez", handler.Live)
+	f.Get("/readyz", handler.Ready)
+}
+
+// Live reports that the process is up and serving. It checks no
+// dependencies, so that their failures do not get the server restarted.
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"status": domain.HealthOK})
+}
+
+// Ready reports whether the server can serve requests, with 503 if not. The
+// verbose parameter adds the result of every check.
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	report := h.HealthUsecase.Ready(c.UserContext())
+	status := http.StatusOK
+	if report.Status != domain.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if _, verbose := c.Queries()["verbose"]; verbose {
+		return c.Status(status).JSON(report)
+	}
+	return c.Status(status).JSON(fiber.Map{"status": report.Status})
+}
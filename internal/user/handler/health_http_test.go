@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHealthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockHealthUsecase) Ready(ctx context.Context) *domain.HealthReport {
+	return m.Called(ctx).Get(0).(*domain.HealthReport)
+}
+
+func TestHealthHandler(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockHealthUsecase)
+	NewHealthHandler(app, mockUsecase)
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("Live", func(t *testing.T) {
+		resp, body := get("/livez")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+		assert.JSONEq(t, `{"status":"ok"}`, body)
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		checkedAt := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+		failing := &domain.HealthReport{Status: domain.HealthFail, Checks: []domain.CheckResult{
+			{Name: "database", Status: domain.HealthFail, Error: "connection refused", Duration: "1ms", CheckedAt: checkedAt},
+		}}
+		mockUsecase.On("Ready", mock.Anything).Return(&domain.HealthReport{Status: domain.HealthOK}).Once()
+		mockUsecase.On("Ready", mock.Anything).Return(failing).Twice()
+
+		resp, body := get("/readyz")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"status":"ok"}`, body)
+
+		resp, body = get("/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.JSONEq(t, `{"status":"fail"}`, body)
+
+		resp, body = get("/readyz?verbose")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.JSONEq(t, `{"status":"fail","checks":[{"name":"database","status":"fail","error":"connection refused",
+			"duration":"1ms","checked_at":"2024-02-03T04:05:06Z"}]}`, body)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
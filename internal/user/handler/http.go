@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"repo-guardian/internal/domain"
 
@@ -17,23 +21,35 @@ func NewUserHandler(f *fiber.App, us domain.UserUsecase) {
 	handler := &UserHandler{
 		UserUsecase: us,
 	}
-
 	f.Post("/users", handler.Register)
+	f.Get("/users", handler.ListUsers)
 	f.Get("/users/:id", handler.GetUser)
+	f.Put("/users/:id", handler.UpdateUser)
+	f.Patch("/users/:id", handler.PatchUser)
 	f.Delete("/users/:id", handler.DeleteUser)
+	f.Post("/users/:id\\:restore", handler.RestoreUser)
+}
+
+// registerRequest adds the write-only password to the user representation.
+type registerRequest struct {
+	domain.User
+	Password string `json:"password"`
 }
 
 func (h *UserHandler) Register(c *fiber.Ctx) error {
-	var user domain.User
-	if err := c.BodyParser(&user); err != nil {
+	var req registerRequest
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	user := req.User
+	user.Password = req.Password
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 	if err := h.UserUsecase.Register(ctx, &user); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return writeError(c, err, http.StatusInternalServerError)
 	}
 
+	setETag(c, &user)
 	return c.Status(http.StatusCreated).JSON(user)
 }
 
@@ -44,12 +60,124 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
+	if c.QueryBool("include_deleted") {
+		ctx = domain.WithDeleted(ctx)
+	}
 	user, err := h.UserUsecase.GetUser(ctx, id)
 	if err != nil {
-		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		return writeError(c, err, http.StatusNotFound)
+	}
+
+	setETag(c, user)
+	if noneMatch(c, user) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+	return c.JSON(user)
+}
+
+func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
+	opts := domain.ListOptions{
+		Cursor:         c.Query("cursor"),
+		UsernamePrefix: c.Query("username_prefix"),
+		EmailDomain:    c.Query("email_domain"),
+		IncludeDeleted: c.QueryBool("include_deleted"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+		}
+		opts.Limit = n
+	}
+	if sort := c.Query("sort"); sort != "" {
+		opts.Descending = strings.HasPrefix(sort, "-")
+		opts.SortBy = domain.SortField(strings.TrimPrefix(sort, "-"))
+	}
+
+	ctx := c.UserContext()
+	page, err := h.UserUsecase.ListUsers(ctx, opts)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	users := page.Users
+	if users == nil {
+		users = []*domain.User{}
+	}
+	resp := fiber.Map{"data": users}
+	if page.NextCursor != "" {
+		resp["next_cursor"] = page.NextCursor
+		c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="next"`, nextPageURL(c, page.NextCursor)))
+	}
+
+	return c.JSON(resp)
+}
+
+// nextPageURL repeats the current query with the cursor replaced.
+func nextPageURL(c *fiber.Ctx, cursor string) string {
+	query := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		query.Add(string(key), string(value))
+	})
+	query.Set("cursor", cursor)
+	return c.BaseURL() + c.Path() + "?" + query.Encode()
+}
+
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var user domain.User
+	if err := c.BodyParser(&user); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.ID != 0 && user.ID != id {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "id is immutable"})
+	}
+	user.ID = id
+
+	ctx, err := withIfMatch(c.UserContext(), c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.UserUsecase.UpdateUser(ctx, &user); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	setETag(c, &user)
+	return c.JSON(user)
+}
+
+func (h *UserHandler) PatchUser(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
+	patch, err := newUserPatch(c.Get(fiber.HeaderContentType), c.Body())
+	if errors.Is(err, errUnsupportedPatch) {
+		c.Set("Accept-Patch", mergePatchContentType+", "+jsonPatchContentType)
+		return c.Status(http.StatusUnsupportedMediaType).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, err := withIfMatch(c.UserContext(), c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	user, err := h.UserUsecase.PatchUser(ctx, id, patch)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	setETag(c, user)
 	return c.JSON(user)
 }
 
@@ -60,10 +188,61 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	ctx := c.Context()
+	ctx, err := withIfMatch(c.UserContext(), c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err := h.UserUsecase.DeleteUser(ctx, id); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return writeError(c, err, http.StatusInternalServerError)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
+
+func (h *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	ctx, err := withIfMatch(c.UserContext(), c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	user, err := h.UserUsecase.RestoreUser(ctx, id)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	setETag(c, user)
+	return c.JSON(user)
+}
+
+// getStatusCode maps domain errors to HTTP statuses, falling back to the
+// handler's default for errors the domain does not define.
+func getStatusCode(err error, fallback int) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrAPIKeyNotFound), errors.Is(err, domain.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict), errors.Is(err, domain.ErrNotDeleted),
+		errors.Is(err, domain.ErrMFANotEnrolled), errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrBadParamInput), errors.Is(err, domain.ErrWeakPassword):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrInvalidToken),
+		errors.Is(err, domain.ErrTokenReused), errors.Is(err, domain.ErrUnauthenticated),
+		errors.Is(err, domain.ErrInvalidAPIKey), errors.Is(err, domain.ErrInvalidMFACode):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden), errors.Is(err, domain.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrImmutableField):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	default:
+		return fallback
+	}
+}
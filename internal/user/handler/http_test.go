@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"repo-guardian/internal/domain"
 
@@ -38,11 +39,43 @@ func (m *MockUserUsecase) GetUser(ctx context.Context, id int64) (*domain.User,
 	return user, args.Error(1)
 }
 
+func (m *MockUserUsecase) UpdateUser(ctx context.Context, user *domain.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserUsecase) PatchUser(ctx context.Context, id int64, patch domain.UserPatch) (*domain.User, error) {
+	args := m.Called(ctx, id, patch)
+	user, ok := args.Get(0).(*domain.User)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return user, args.Error(1)
+}
+
 func (m *MockUserUsecase) DeleteUser(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockUserUsecase) ListUsers(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	args := m.Called(ctx, opts)
+	page, ok := args.Get(0).(*domain.UserPage)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return page, args.Error(1)
+}
+
+func (m *MockUserUsecase) RestoreUser(ctx context.Context, id int64) (*domain.User, error) {
+	args := m.Called(ctx, id)
+	user, ok := args.Get(0).(*domain.User)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return user, args.Error(1)
+}
+
 func TestNewUserHandler(t *testing.T) {
 	app := fiber.New()
 	mockUsecase := new(MockUserUsecase)
@@ -50,21 +83,33 @@ func TestNewUserHandler(t *testing.T) {
 	NewUserHandler(app, mockUsecase)
 
 	routes := app.GetRoutes()
-	var postUsers, getUsers, deleteUsers bool
+	var postUsers, listUsers, getUsers, putUsers, patchUsers, deleteUsers, restoreUsers bool
 	for _, r := range routes {
 		switch {
 		case r.Method == http.MethodPost && r.Path == "/users":
 			postUsers = true
+		case r.Method == http.MethodPost && strings.HasSuffix(r.Path, ":restore"):
+			restoreUsers = true
+		case r.Method == http.MethodGet && r.Path == "/users":
+			listUsers = true
 		case r.Method == http.MethodGet && strings.HasPrefix(r.Path, "/users/:id"):
 			getUsers = true
+		case r.Method == http.MethodPut && strings.HasPrefix(r.Path, "/users/:id"):
+			putUsers = true
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.Path, "/users/:id"):
+			patchUsers = true
 		case r.Method == http.MethodDelete && strings.HasPrefix(r.Path, "/users/:id"):
 			deleteUsers = true
 		}
 	}
 
 	assert.True(t, postUsers, "POST /users route not registered")
+	assert.True(t, listUsers, "GET /users route not registered")
 	assert.True(t, getUsers, "GET /users/:id route not registered")
+	assert.True(t, putUsers, "PUT /users/:id route not registered")
+	assert.True(t, patchUsers, "PATCH /users/:id route not registered")
 	assert.True(t, deleteUsers, "DELETE /users/:id route not registered")
+	assert.True(t, restoreUsers, "POST /users/:id:restore route not registered")
 }
 
 func TestUserHandler_Register(t *testing.T) {
@@ -74,7 +119,7 @@ func TestUserHandler_Register(t *testing.T) {
 	app.Post("/users", handler.Register)
 
 	t.Run("Success", func(t *testing.T) {
-		user := domain.User{Name: "Test User", Email: "test@example.com"}
+		user := domain.User{Username: "Test User", Email: "test@example.com"}
 		userJSON, _ := json.Marshal(user)
 
 		mockUsecase.On("Register", mock.Anything, &user).Return(nil).Once()
@@ -91,11 +136,43 @@ func TestUserHandler_Register(t *testing.T) {
 		err = json.Unmarshal(body, &registeredUser)
 		assert.NoError(t, err)
 
-		assert.Equal(t, user.Name, registeredUser.Name)
+		assert.Equal(t, user.Username, registeredUser.Username)
 		assert.Equal(t, user.Email, registeredUser.Email)
 		mockUsecase.AssertExpectations(t)
 	})
 
+	t.Run("Success_WithPassword", func(t *testing.T) {
+		mockUsecase.On("Register", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+			return u.Username == "john" && u.Password == "correct horse"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.User).Password = ""
+		}).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBufferString(`{"username":"john","email":"john@example.com","password":"correct horse"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.NotContains(t, string(body), "password")
+		assert.NotContains(t, string(body), "correct horse")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("BadRequest_WeakPassword", func(t *testing.T) {
+		mockUsecase.On("Register", mock.Anything, mock.Anything).Return(domain.ErrWeakPassword).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBufferString(`{"username":"john","email":"john@example.com","password":"short"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+
 	t.Run("BadRequest_InvalidBody", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBuffer([]byte("invalid json")))
 		req.Header.Set("Content-Type", "application/json")
@@ -106,7 +183,7 @@ func TestUserHandler_Register(t *testing.T) {
 	})
 
 	t.Run("InternalServerError_UsecaseError", func(t *testing.T) {
-		user := domain.User{Name: "Test User", Email: "test@example.com"}
+		user := domain.User{Username: "Test User", Email: "test@example.com"}
 		userJSON, _ := json.Marshal(user)
 
 		mockUsecase.On("Register", mock.Anything, &user).Return(errors.New("usecase error")).Once()
@@ -130,7 +207,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		id := int64(1)
-		user := &domain.User{ID: id, Name: "Test User", Email: "test@example.com"}
+		user := &domain.User{ID: id, Username: "Test User", Email: "test@example.com"}
 		mockUsecase.On("GetUser", mock.Anything, id).Return(user, nil).Once()
 
 		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
@@ -145,7 +222,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, user.ID, retrievedUser.ID)
-		assert.Equal(t, user.Name, retrievedUser.Name)
+		assert.Equal(t, user.Username, retrievedUser.Username)
 		assert.Equal(t, user.Email, retrievedUser.Email)
 		mockUsecase.AssertExpectations(t)
 	})
@@ -177,6 +254,355 @@ func TestUserHandler_GetUser(t *testing.T) {
 	})
 }
 
+func TestUserHandler_ListUsers(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockUserUsecase)
+	handler := &UserHandler{UserUsecase: mockUsecase}
+	app.Get("/users", handler.ListUsers)
+
+	t.Run("Success_WithNextPage", func(t *testing.T) {
+		opts := domain.ListOptions{
+			Limit:          1,
+			SortBy:         domain.SortByUsername,
+			Descending:     true,
+			UsernamePrefix: "jo",
+			EmailDomain:    "example.com",
+		}
+		page := &domain.UserPage{
+			Users:      []*domain.User{{ID: 1, Username: "john", Email: "john@example.com"}},
+			NextCursor: "abc",
+		}
+		mockUsecase.On("ListUsers", mock.Anything, opts).Return(page, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/users?limit=1&sort=-username&username_prefix=jo&email_domain=example.com", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data       []domain.User `json:"data"`
+			NextCursor string        `json:"next_cursor"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Len(t, body.Data, 1)
+		assert.Equal(t, "abc", body.NextCursor)
+		link := resp.Header.Get("Link")
+		assert.Contains(t, link, "cursor=abc")
+		assert.Contains(t, link, "username_prefix=jo")
+		assert.Contains(t, link, `rel="next"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success_LastPage", func(t *testing.T) {
+		mockUsecase.On("ListUsers", mock.Anything, domain.ListOptions{}).Return(&domain.UserPage{}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Link"))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"data":[]}`, string(body))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("BadRequest_InvalidLimit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users?limit=ten", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("BadRequest_UsecaseError", func(t *testing.T) {
+		mockUsecase.On("ListUsers", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: malformed cursor", domain.ErrBadParamInput)).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/users?cursor=bogus", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockUserUsecase)
+	handler := &UserHandler{UserUsecase: mockUsecase}
+	app.Put("/users/:id", handler.UpdateUser)
+
+	t.Run("Success", func(t *testing.T) {
+		user := &domain.User{ID: 1, Username: "test", Email: "test@example.com"}
+		mockUsecase.On("UpdateUser", mock.Anything, user).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"username":"test","email":"test@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var updated domain.User
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+		assert.Equal(t, *user, updated)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("BadRequest_IDMismatch", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"id":2,"username":"test","email":"test@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("BadRequest_Validation", func(t *testing.T) {
+		mockUsecase.On("UpdateUser", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: email is required", domain.ErrBadParamInput)).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"username":"test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockUsecase.On("UpdateUser", mock.Anything, mock.Anything).Return(domain.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"username":"test","email":"test@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_PatchUser(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockUserUsecase)
+	handler := &UserHandler{UserUsecase: mockUsecase}
+	app.Patch("/users/:id", handler.PatchUser)
+
+	t.Run("MergePatch", func(t *testing.T) {
+		patched := &domain.User{ID: 1, Username: "test", Email: "new@example.com"}
+		mockUsecase.On("PatchUser", mock.Anything, int64(1), mock.AnythingOfType("handler.mergePatch")).Return(patched, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"email":"new@example.com"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got domain.User
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, *patched, got)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("JSONPatch", func(t *testing.T) {
+		patched := &domain.User{ID: 1, Username: "renamed", Email: "test@example.com"}
+		mockUsecase.On("PatchUser", mock.Anything, int64(1), mock.AnythingOfType("handler.jsonPatch")).Return(patched, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`[{"op":"replace","path":"/username","value":"renamed"}]`))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("UnsupportedMediaType", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"email":"new@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Accept-Patch"), "application/merge-patch+json")
+	})
+
+	t.Run("BadRequest_MalformedPatch", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"op":`))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Unprocessable_ImmutableField", func(t *testing.T) {
+		mockUsecase.On("PatchUser", mock.Anything, int64(1), mock.Anything).Return(nil, fmt.Errorf("%w: id", domain.ErrImmutableField)).Once()
+
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"id":2}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_ETags(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockUserUsecase)
+	handler := &UserHandler{UserUsecase: mockUsecase}
+	app.Get("/users/:id", handler.GetUser)
+	app.Put("/users/:id", handler.UpdateUser)
+	app.Patch("/users/:id", handler.PatchUser)
+	app.Delete("/users/:id", handler.DeleteUser)
+
+	user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Version: 3}
+	expectsVersion := func(want int64) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			v, ok := domain.ExpectedVersion(ctx)
+			return ok && v == want
+		})
+	}
+
+	t.Run("GetReturnsETag", func(t *testing.T) {
+		mockUsecase.On("GetUser", mock.Anything, int64(1)).Return(user, nil).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("GetNotModified", func(t *testing.T) {
+		mockUsecase.On("GetUser", mock.Anything, int64(1)).Return(user, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("If-None-Match", `"2", W/"3"`)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("GetModified", func(t *testing.T) {
+		mockUsecase.On("GetUser", mock.Anything, int64(1)).Return(user, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("If-None-Match", `"2"`)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("PutIfMatch", func(t *testing.T) {
+		mockUsecase.On("UpdateUser", expectsVersion(3), mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.User).Version = 4
+		}).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"username":"test","email":"test@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"3"`)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"4"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("PatchPreconditionFailed", func(t *testing.T) {
+		mockUsecase.On("PatchUser", expectsVersion(2), int64(1), mock.Anything).Return(nil, domain.ErrVersionConflict).Once()
+
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"email":"new@example.com"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	t.Run("DeleteWeakTagNeverMatches", func(t *testing.T) {
+		mockUsecase.On("DeleteUser", expectsVersion(-1), int64(1)).Return(domain.ErrVersionConflict).Once()
+
+		req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+		req.Header.Set("If-Match", `W/"3"`)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	t.Run("DeleteWildcard", func(t *testing.T) {
+		mockUsecase.On("DeleteUser", mock.MatchedBy(func(ctx context.Context) bool {
+			_, ok := domain.ExpectedVersion(ctx)
+			return !ok
+		}), int64(1)).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+		req.Header.Set("If-Match", "*")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("DeleteMultipleTags", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+		req.Header.Set("If-Match", `"2", "3"`)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserHandler_SoftDelete(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockUserUsecase)
+	NewUserHandler(app, mockUsecase)
+
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deleted := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Version: 2, DeletedAt: &deletedAt}
+	includesDeleted := mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.IncludeDeleted(ctx)
+	})
+
+	t.Run("GetIncludeDeleted", func(t *testing.T) {
+		mockUsecase.On("GetUser", includesDeleted, int64(1)).Return(deleted, nil).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users/1?include_deleted=true", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `"deleted_at":"2024-01-01T00:00:00Z"`)
+	})
+
+	t.Run("ListIncludeDeleted", func(t *testing.T) {
+		mockUsecase.On("ListUsers", mock.Anything, domain.ListOptions{IncludeDeleted: true}).Return(&domain.UserPage{}, nil).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users?include_deleted=true", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		restored := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Version: 3}
+		mockUsecase.On("RestoreUser", mock.Anything, int64(1)).Return(restored, nil).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/users/1:restore", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("RestoreNotDeleted", func(t *testing.T) {
+		mockUsecase.On("RestoreUser", mock.Anything, int64(2)).Return(nil, domain.ErrNotDeleted).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/users/2:restore", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("RestoreInvalidID", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/users/abc:restore", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
+
 func TestUserHandler_DeleteUser(t *testing.T) {
 	app := fiber.New()
 	mockUsecase := new(MockUserUsecase)
@@ -230,13 +656,13 @@ func TestUserHandler_Register_Integration(t *testing.T) {
 
 	t.Run("Register User - Valid Input", func(t *testing.T) {
 		// Define a valid user
-		user := domain.User{Name: "John Doe", Email: "john.doe@example.com"}
+		user := domain.User{Username: "John Doe", Email: "john.doe@example.com"}
 		userJSON, err := json.Marshal(user)
 		assert.NoError(t, err)
 
 		// Mock the Usecase to return nil (no error)
 		mockUsecase.On("Register", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
-			return u.Name == user.Name && u.Email == user.Email
+			return u.Username == user.Username && u.Email == user.Email
 		})).Return(nil).Once()
 
 		// Create a request to the endpoint
@@ -258,7 +684,7 @@ func TestUserHandler_Register_Integration(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Assert the response matches the expected user
-		assert.Equal(t, user.Name, responseUser.Name)
+		assert.Equal(t, user.Username, responseUser.Username)
 		assert.Equal(t, user.Email, responseUser.Email)
 
 		mockUsecase.AssertExpectations(t)
@@ -276,7 +702,7 @@ func TestUserHandler_GetUser_Integration(t *testing.T) {
 		userID := int64(123)
 
 		// Define the user that the Usecase will return
-		expectedUser := &domain.User{ID: userID, Name: "Jane Doe", Email: "jane.doe@example.com"}
+		expectedUser := &domain.User{ID: userID, Username: "Jane Doe", Email: "jane.doe@example.com"}
 
 		// Mock the Usecase to return the expected user
 		mockUsecase.On("GetUser", mock.Anything, userID).Return(expectedUser, nil).Once()
@@ -300,7 +726,7 @@ func TestUserHandler_GetUser_Integration(t *testing.T) {
 
 		// Assert the response matches the expected user
 		assert.Equal(t, expectedUser.ID, responseUser.ID)
-		assert.Equal(t, expectedUser.Name, responseUser.Name)
+		assert.Equal(t, expectedUser.Username, responseUser.Username)
 		assert.Equal(t, expectedUser.Email, responseUser.Email)
 
 		mockUsecase.AssertExpectations(t)
@@ -503,12 +929,13 @@ func TestParseIntError(t *testing.T) {
 	_, err = strconv.ParseInt("9223372036854775808", 10, 64) // One more than max int64
 	assert.Error(t, err)
 
-	// Test Case 4: Negative number (not applicable for uint64, but tests the function)
-	_, err = strconv.ParseInt("-1", 10, 64)
-	assert.Error(t, err)
+	// Test Case 4: Negative number parses as a signed int64
+	num, err := strconv.ParseInt("-1", 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), num)
 
 	// Test Case 5: Valid number
-	num, err := strconv.ParseInt("12345", 10, 64)
+	num, err = strconv.ParseInt("12345", 10, 64)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(12345), num)
 }
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests no route matched, so that scans of random
+// paths cannot create unbounded label values.
+const unmatchedRoute = "unmatched"
+
+// Metrics records the rate, errors and duration of requests by route
+// pattern, such as /users/:id, rather than by path.
+func Metrics(reg prometheus.Registerer, buckets []float64) fiber.Handler {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by route and status class.",
+	}, []string{"method", "route", "status"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests, by route.",
+		Buckets: buckets,
+	}, []string{"method", "route"})
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests being handled.",
+	})
+	reg.MustRegister(requests, duration, inFlight)
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		self := c.Route()
+		err := c.Next()
+
+		route := routePattern(c, self)
+		status := responseStatus(c, err)
+		// Fiber reuses the method's memory once the request is done.
+		method := strings.Clone(c.Method())
+		requests.WithLabelValues(method, route, strconv.Itoa(status/100)+"xx").Inc()
+		duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// routePattern returns the pattern of the route that handled the request,
+// given the route of the middleware asking.
+func routePattern(c *fiber.Ctx, self *fiber.Route) string {
+	if c.Route() == self {
+		return unmatchedRoute
+	}
+	return c.Route().Path
+}
+
+// responseStatus returns the status of the response to the request, which
+// the error handler only writes after the middleware returns err.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// NewMetricsHandler serves the metrics gathered by g at /metrics.
+func NewMetricsHandler(f *fiber.App, g prometheus.Gatherer) {
+	f.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(g, promhttp.HandlerOpts{})))
+}
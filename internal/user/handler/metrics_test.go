@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	app := fiber.New()
+	NewMetricsHandler(app, reg)
+	app.Use(Metrics(reg, []float64{1}))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "0" {
+			return fiber.NewError(http.StatusBadRequest, "Invalid ID")
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+	app.Post("/users", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusCreated) })
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodGet, "/users/0", nil),
+		httptest.NewRequest(http.MethodPost, "/users", nil),
+		httptest.NewRequest(http.MethodGet, "/wp-admin.php", nil),
+	} {
+		_, err := app.Test(req)
+		assert.NoError(t, err)
+	}
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP http_requests_total HTTP requests handled, by route and status class.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/users/:id",status="2xx"} 2
+http_requests_total{method="GET",route="/users/:id",status="4xx"} 1
+http_requests_total{method="GET",route="unmatched",status="4xx"} 1
+http_requests_total{method="POST",route="/users",status="2xx"} 1
+# HELP http_requests_in_flight HTTP requests being handled.
+# TYPE http_requests_in_flight gauge
+http_requests_in_flight 0
+`), "http_requests_total", "http_requests_in_flight")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, testutil.CollectAndCount(reg, "http_request_duration_seconds"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `http_requests_total{method="POST",route="/users",status="2xx"} 1`)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAHandler struct {
+	MFAUsecase domain.MFAUsecase
+}
+
+func NewMFAHandler(f *fiber.App, us domain.MFAUsecase) {
+	handler := &MFAHandler{
+		MFAUsecase: us,
+	}
+	f.Post("/users/:id/mfa/totp", handler.Enroll)
+	f.Post("/users/:id/mfa/totp/confirm", handler.Confirm)
+	f.Post("/users/:id/mfa/totp/disable", handler.Disable)
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	setup, err := h.MFAUsecase.Enroll(c.UserContext(), id)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusCreated).JSON(setup)
+}
+
+func (h *MFAHandler) Confirm(c *fiber.Ctx) error {
+	id, req, err := parseMFACodeRequest(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	codes, err := h.MFAUsecase.Confirm(c.UserContext(), id, req.Code)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	id, req, err := parseMFACodeRequest(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.MFAUsecase.Disable(c.UserContext(), id, req.Code); err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func parseMFACodeRequest(c *fiber.Ctx) (int64, mfaCodeRequest, error) {
+	var req mfaCodeRequest
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, req, fiber.NewError(http.StatusBadRequest, "Invalid ID")
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return 0, req, err
+	}
+	if req.Code == "" {
+		return 0, req, fiber.NewError(http.StatusBadRequest, "code is required")
+	}
+	return id, req, nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMFAUsecase struct {
+	mock.Mock
+}
+
+func (m *MockMFAUsecase) Enroll(ctx context.Context, userID int64) (*domain.MFASetup, error) {
+	args := m.Called(ctx, userID)
+	setup, _ := args.Get(0).(*domain.MFASetup)
+	return setup, args.Error(1)
+}
+
+func (m *MockMFAUsecase) Confirm(ctx context.Context, userID int64, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	codes, _ := args.Get(0).([]string)
+	return codes, args.Error(1)
+}
+
+func (m *MockMFAUsecase) Disable(ctx context.Context, userID int64, code string) error {
+	return m.Called(ctx, userID, code).Error(0)
+}
+
+func (m *MockMFAUsecase) Enabled(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFAUsecase) Verify(ctx context.Context, userID int64, code string) error {
+	return m.Called(ctx, userID, code).Error(0)
+}
+
+func TestMFAHandler(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockMFAUsecase)
+	NewMFAHandler(app, mockUsecase)
+
+	t.Run("Enroll", func(t *testing.T) {
+		setup := &domain.MFASetup{Secret: "SECRET", URI: "otpauth://totp/x"}
+		mockUsecase.On("Enroll", mock.Anything, int64(1)).Return(setup, nil).Once()
+		mockUsecase.On("Enroll", mock.Anything, int64(2)).Return(nil, domain.ErrMFAAlreadyEnabled).Once()
+
+		resp := postJSON(t, app, "/users/1/mfa/totp", ``)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+		var got domain.MFASetup
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, *setup, got)
+
+		assert.Equal(t, http.StatusConflict, postJSON(t, app, "/users/2/mfa/totp", ``).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/users/abc/mfa/totp", ``).StatusCode)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		mockUsecase.On("Confirm", mock.Anything, int64(1), "123456").Return([]string{"aaaa-bbbb-cccc-dddd"}, nil).Once()
+		mockUsecase.On("Confirm", mock.Anything, int64(1), "000000").Return(nil, domain.ErrInvalidMFACode).Once()
+
+		resp := postJSON(t, app, "/users/1/mfa/totp/confirm", `{"code":"123456"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"recovery_codes":["aaaa-bbbb-cccc-dddd"]}`, string(body))
+
+		assert.Equal(t, http.StatusUnauthorized, postJSON(t, app, "/users/1/mfa/totp/confirm", `{"code":"000000"}`).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/users/1/mfa/totp/confirm", `{}`).StatusCode)
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		mockUsecase.On("Disable", mock.Anything, int64(1), "123456").Return(nil).Once()
+		mockUsecase.On("Disable", mock.Anything, int64(2), "123456").Return(domain.ErrMFANotEnrolled).Once()
+
+		assert.Equal(t, http.StatusNoContent, postJSON(t, app, "/users/1/mfa/totp/disable", `{"code":"123456"}`).StatusCode)
+		assert.Equal(t, http.StatusConflict, postJSON(t, app, "/users/2/mfa/totp/disable", `{"code":"123456"}`).StatusCode)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
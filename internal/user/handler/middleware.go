@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type authenticator interface {
+	Authenticate(ctx context.Context, credential string) (domain.Principal, error)
+}
+
+// Authenticate verifies the request's credentials and attaches the caller's
+// principal to the request context. It accepts bearer access tokens and
+// "ApiKey" keys. Requests without credentials continue anonymously; it is up
+// to the usecases whether that is allowed.
+func Authenticate(tokens domain.TokenUsecase, apiKeys domain.APIKeyUsecase) fiber.Handler {
+	schemes := map[string]authenticator{
+		"bearer": tokens,
+		"apikey": apiKeys,
+	}
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return c.Next()
+		}
+		scheme, credential, _ := strings.Cut(header, " ")
+		auth, ok := schemes[strings.ToLower(scheme)]
+		credential = strings.TrimSpace(credential)
+		if !ok || credential == "" {
+			return unauthorized(c, domain.ErrUnauthenticated)
+		}
+
+		principal, err := auth.Authenticate(c.UserContext(), credential)
+		if err != nil {
+			return unauthorized(c, err)
+		}
+		c.SetUserContext(domain.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
+
+func unauthorized(c *fiber.Ctx, err error) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token", ApiKey`)
+	return writeProblem(c, http.StatusUnauthorized, err)
+}
+
+// maxRequestIDLength bounds the request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// RequestID tags each request with an ID, taken from the X-Request-ID header
+// if the client or a proxy sent a usable one, and echoes it in the response.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// The header's bytes are reused after the request, and the ID may
+		// outlive it in the context.
+		id := strings.Clone(c.Get(fiber.HeaderXRequestID))
+		if !validRequestID(id) {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			id = hex.EncodeToString(b)
+		}
+		c.Set(fiber.HeaderXRequestID, id)
+		c.SetUserContext(domain.WithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticate(t *testing.T) {
+	mockTokens := new(MockTokenUsecase)
+	mockKeys := new(MockAPIKeyUsecase)
+	app := fiber.New()
+	app.Use(Authenticate(mockTokens, mockKeys))
+	app.Get("/whoami", func(c *fiber.Ctx) error {
+		return c.SendString(domain.Actor(c.UserContext()))
+	})
+
+	request := func(authorization string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		return resp, string(body[:n])
+	}
+
+	t.Run("Anonymous", func(t *testing.T) {
+		resp, body := request("")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, body)
+	})
+
+	t.Run("ValidToken", func(t *testing.T) {
+		mockTokens.On("Authenticate", mock.Anything, "good").Return(domain.UserPrincipal(7, domain.RoleUser), nil).Once()
+
+		resp, body := request("Bearer good")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "user:7", body)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		mockTokens.On("Authenticate", mock.Anything, "bad").Return(domain.Principal{}, domain.ErrInvalidToken).Once()
+
+		resp, _ := request("Bearer bad")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, `Bearer error="invalid_token", ApiKey`, resp.Header.Get("WWW-Authenticate"))
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("ValidAPIKey", func(t *testing.T) {
+		principal := domain.Principal{Subject: "apikey:abc", Role: domain.RoleService, Scopes: []domain.Scope{domain.ScopeUsersRead}}
+		mockKeys.On("Authenticate", mock.Anything, "rg_abc_secret").Return(principal, nil).Once()
+
+		resp, body := request("ApiKey rg_abc_secret")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "apikey:abc", body)
+		mockKeys.AssertExpectations(t)
+	})
+
+	t.Run("RevokedAPIKey", func(t *testing.T) {
+		mockKeys.On("Authenticate", mock.Anything, "rg_abc_revoked").Return(domain.Principal{}, domain.ErrInvalidAPIKey).Once()
+
+		resp, _ := request("ApiKey rg_abc_revoked")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		mockKeys.AssertExpectations(t)
+	})
+
+	t.Run("OtherScheme", func(t *testing.T) {
+		resp, _ := request("Basic am9objpwdw==")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(domain.RequestID(c.UserContext()))
+	})
+
+	request := func(id string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		body := make([]byte, 256)
+		n, _ := resp.Body.Read(body)
+		return resp, string(body[:n])
+	}
+
+	resp, body := request("abc-123")
+	assert.Equal(t, "abc-123", body)
+	assert.Equal(t, "abc-123", resp.Header.Get("X-Request-ID"))
+
+	for _, id := range []string{"", "has space", strings.Repeat("x", 129)} {
+		resp, body := request(id)
+		assert.Len(t, body, 32, "generated for %q", id)
+		assert.Equal(t, body, resp.Header.Get("X-Request-ID"))
+	}
+}
@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"repo-guardian/internal/domain"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+var errUnsupportedPatch = errors.New("unsupported patch content type")
+
+// newUserPatch builds the patch implementation matching the request's
+// Content-Type.
+func newUserPatch(contentType string, body []byte) (domain.UserPatch, error) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case mergePatchContentType:
+		var doc interface{}
+		if err := decodeJSON(body, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+		return mergePatch{doc: doc}, nil
+	case jsonPatchContentType:
+		var ops []jsonPatchOperation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+		return jsonPatch(ops), nil
+	default:
+		return nil, errUnsupportedPatch
+	}
+}
+
+// mergePatch is an RFC 7396 JSON Merge Patch document.
+type mergePatch struct {
+	doc interface{}
+}
+
+func (p mergePatch) Apply(user *domain.User) error {
+	return applyToUser(user, func(target interface{}) (interface{}, error) {
+		return mergeValue(target, p.doc), nil
+	})
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergeValue(targetObj[name], value)
+	}
+	return targetObj
+}
+
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// jsonPatch is an RFC 6902 JSON Patch document.
+type jsonPatch []jsonPatchOperation
+
+func (p jsonPatch) Apply(user *domain.User) error {
+	return applyToUser(user, func(doc interface{}) (interface{}, error) {
+		var err error
+		for i, op := range p {
+			if doc, err = op.apply(doc); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", domain.ErrBadParamInput, i, err)
+			}
+		}
+		return doc, nil
+	})
+}
+
+func (op jsonPatchOperation) value() (interface{}, error) {
+	if op.Value == nil {
+		return nil, errors.New("missing value")
+	}
+	var v interface{}
+	if err := decodeJSON(*op.Value, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (op jsonPatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+	case "replace":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if doc, _, err = removeValue(doc, path); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "move":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		doc, v, err := removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, deepCopy(v))
+	case "test":
+		want, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		got, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(got, want) {
+			return nil, fmt.Errorf("test failed at %q", op.Path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx > length || (idx == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path member %q not found", token)
+			}
+			doc = v
+		case []interface{}:
+			idx, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[idx]
+		default:
+			return nil, fmt.Errorf("cannot traverse into %q", token)
+		}
+	}
+	return doc, nil
+}
+
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		idx, err := arrayIndex(last, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node[:idx], append([]interface{}{value}, node[idx:]...)...)
+		return setValue(doc, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("cannot add to %q", last)
+	}
+}
+
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("path member %q not found", last)
+		}
+		delete(node, last)
+		return doc, v, nil
+	case []interface{}:
+		idx, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := node[idx]
+		node = append(node[:idx:idx], node[idx+1:]...)
+		doc, err = setValue(doc, path[:len(path)-1], node)
+		return doc, v, err
+	default:
+		return nil, nil, fmt.Errorf("cannot remove from %q", last)
+	}
+}
+
+// setValue replaces the value at path, which must already exist. It is used
+// to store arrays back after they have been resized.
+func setValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		idx, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = value
+	}
+	return doc, nil
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			out[k] = deepCopy(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(node))
+		for i, child := range node {
+			out[i] = deepCopy(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// applyToUser round-trips the user through its JSON representation so that
+// patches address the same field names clients see.
+func applyToUser(user *domain.User, fn func(doc interface{}) (interface{}, error)) error {
+	raw, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := decodeJSON(raw, &doc); err != nil {
+		return err
+	}
+
+	doc, err = fn(doc)
+	if err != nil {
+		return err
+	}
+
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var patched domain.User
+	if err := dec.Decode(&patched); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+	*user = patched
+	return nil
+}
+
+// decodeJSON keeps numbers as json.Number so int64 IDs survive the round trip.
+func decodeJSON(raw []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
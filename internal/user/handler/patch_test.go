@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUserPatch(t *testing.T) {
+	t.Run("MergePatch", func(t *testing.T) {
+		patch, err := newUserPatch("application/merge-patch+json; charset=utf-8", []byte(`{"email":"a@example.com"}`))
+		assert.NoError(t, err)
+		assert.IsType(t, mergePatch{}, patch)
+	})
+
+	t.Run("JSONPatch", func(t *testing.T) {
+		patch, err := newUserPatch("application/json-patch+json", []byte(`[]`))
+		assert.NoError(t, err)
+		assert.IsType(t, jsonPatch{}, patch)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := newUserPatch("application/json", []byte(`{}`))
+		assert.ErrorIs(t, err, errUnsupportedPatch)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := newUserPatch("application/merge-patch+json", []byte(`{`))
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+}
+
+func TestMergePatch_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    domain.User
+		wantErr error
+	}{
+		{
+			name:  "replace field",
+			patch: `{"email":"new@example.com"}`,
+			want:  domain.User{ID: 1, Username: "test", Email: "new@example.com"},
+		},
+		{
+			name:  "null removes field",
+			patch: `{"email":null}`,
+			want:  domain.User{ID: 1, Username: "test"},
+		},
+		{
+			name:  "large id is preserved",
+			patch: `{"id":9007199254740993}`,
+			want:  domain.User{ID: 9007199254740993, Username: "test", Email: "test@example.com"},
+		},
+		{
+			name:    "unknown field",
+			patch:   `{"nickname":"t"}`,
+			wantErr: domain.ErrBadParamInput,
+		},
+		{
+			name:    "non-object document",
+			patch:   `"test"`,
+			wantErr: domain.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := newUserPatch(mergePatchContentType, []byte(tt.patch))
+			assert.NoError(t, err)
+
+			user := domain.User{ID: 1, Username: "test", Email: "test@example.com"}
+			err = patch.Apply(&user)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, user)
+		})
+	}
+}
+
+func TestJSONPatch_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    domain.User
+		wantErr bool
+	}{
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/username","value":"renamed"}]`,
+			want:  domain.User{ID: 1, Username: "renamed", Email: "test@example.com"},
+		},
+		{
+			name:  "add overwrites member",
+			patch: `[{"op":"add","path":"/email","value":"new@example.com"}]`,
+			want:  domain.User{ID: 1, Username: "test", Email: "new@example.com"},
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/email"}]`,
+			want:  domain.User{ID: 1, Username: "test"},
+		},
+		{
+			name:  "copy",
+			patch: `[{"op":"copy","from":"/username","path":"/email"}]`,
+			want:  domain.User{ID: 1, Username: "test", Email: "test"},
+		},
+		{
+			name:  "move",
+			patch: `[{"op":"move","from":"/username","path":"/email"}]`,
+			want:  domain.User{ID: 1, Email: "test"},
+		},
+		{
+			name:  "test then replace",
+			patch: `[{"op":"test","path":"/username","value":"test"},{"op":"replace","path":"/username","value":"renamed"}]`,
+			want:  domain.User{ID: 1, Username: "renamed", Email: "test@example.com"},
+		},
+		{
+			name:    "failed test aborts patch",
+			patch:   `[{"op":"test","path":"/username","value":"other"},{"op":"replace","path":"/username","value":"renamed"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "replace missing member",
+			patch:   `[{"op":"replace","path":"/nickname","value":"t"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			patch:   `[{"op":"add","path":"/email"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown op",
+			patch:   `[{"op":"frobnicate","path":"/email"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid pointer",
+			patch:   `[{"op":"remove","path":"email"}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := newUserPatch(jsonPatchContentType, []byte(tt.patch))
+			assert.NoError(t, err)
+
+			user := domain.User{ID: 1, Username: "test", Email: "test@example.com"}
+			err = patch.Apply(&user)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, domain.ErrBadParamInput), "got %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, user)
+		})
+	}
+}
+
+func TestJSONPatch_Arrays(t *testing.T) {
+	doc := []interface{}{"a", "b"}
+
+	path, err := parsePointer("/-")
+	assert.NoError(t, err)
+	out, err := addValue(doc, path, "c")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, out)
+
+	path, _ = parsePointer("/0")
+	out, removed, err := removeValue(out, path)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", removed)
+	assert.Equal(t, []interface{}{"b", "c"}, out)
+
+	path, _ = parsePointer("/01")
+	_, err = getValue(out, path)
+	assert.Error(t, err)
+}
+
+func TestParsePointer(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/m~0n")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "m~n"}, tokens)
+
+	tokens, err = parsePointer("")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PrivacyHandler struct {
+	PrivacyUsecase domain.PrivacyUsecase
+}
+
+func NewPrivacyHandler(f *fiber.App, us domain.PrivacyUsecase) {
+	handler := &PrivacyHandler{
+		PrivacyUsecase: us,
+	}
+	f.Get("/users/:id/export", handler.Export)
+	f.Post("/users/:id/erasure", handler.RequestErasure)
+	f.Get("/erasures/:id", handler.GetErasure)
+}
+
+// Export returns everything held about the user as a JSON download.
+func (h *PrivacyHandler) Export(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	export, err := h.PrivacyUsecase.Export(c.UserContext(), id)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment(fmt.Sprintf("user-%d-export.json", id))
+	return c.JSON(export)
+}
+
+// RequestErasure starts erasing the user's personal data. The job runs in the
+// background; its status is at the returned Location.
+func (h *PrivacyHandler) RequestErasure(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	job, err := h.PrivacyUsecase.RequestErasure(c.UserContext(), id)
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	c.Location("/erasures/" + job.ID)
+	return c.Status(http.StatusAccepted).JSON(job)
+}
+
+func (h *PrivacyHandler) GetErasure(c *fiber.Ctx) error {
+	job, err := h.PrivacyUsecase.GetErasure(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return writeError(c, err, http.StatusInternalServerError)
+	}
+
+	return c.JSON(job)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPrivacyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockPrivacyUsecase) Export(ctx context.Context, userID int64) (*domain.UserExport, error) {
+	args := m.Called(ctx, userID)
+	export, _ := args.Get(0).(*domain.UserExport)
+	return export, args.Error(1)
+}
+
+func (m *MockPrivacyUsecase) RequestErasure(ctx context.Context, userID int64) (*domain.ErasureJob, error) {
+	args := m.Called(ctx, userID)
+	job, _ := args.Get(0).(*domain.ErasureJob)
+	return job, args.Error(1)
+}
+
+func (m *MockPrivacyUsecase) GetErasure(ctx context.Context, jobID string) (*domain.ErasureJob, error) {
+	args := m.Called(ctx, jobID)
+	job, _ := args.Get(0).(*domain.ErasureJob)
+	return job, args.Error(1)
+}
+
+func TestPrivacyHandler(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockPrivacyUsecase)
+	NewPrivacyHandler(app, mockUsecase)
+	get := func(path string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NoError(t, err)
+		return resp
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Export", func(t *testing.T) {
+		export := &domain.UserExport{
+			ExportedAt:  now,
+			User:        &domain.User{ID: 1, Username: "john", Email: "john@example.com"},
+			Sessions:    []domain.SessionInfo{},
+			APIKeys:     []*domain.APIKey{},
+			AuditEvents: []*domain.AuditEvent{},
+		}
+		mockUsecase.On("Export", mock.Anything, int64(1)).Return(export, nil).Once()
+		mockUsecase.On("Export", mock.Anything, int64(2)).Return(nil, domain.ErrForbidden).Once()
+
+		resp := get("/users/1/export")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `attachment; filename="user-1-export.json"`, resp.Header.Get("Content-Disposition"))
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+		var got map[string]any
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, "john@example.com", got["user"].(map[string]any)["email"])
+		assert.Equal(t, []any{}, got["sessions"])
+
+		assert.Equal(t, http.StatusForbidden, get("/users/2/export").StatusCode)
+		assert.Equal(t, http.StatusBadRequest, get("/users/abc/export").StatusCode)
+	})
+
+	t.Run("RequestErasure", func(t *testing.T) {
+		job := &domain.ErasureJob{ID: "j1", UserID: 1, Status: domain.ErasurePending, CreatedAt: now, UpdatedAt: now}
+		mockUsecase.On("RequestErasure", mock.Anything, int64(1)).Return(job, nil).Once()
+		mockUsecase.On("RequestErasure", mock.Anything, int64(3)).Return(nil, domain.ErrNotFound).Once()
+
+		resp := postJSON(t, app, "/users/1/erasure", ``)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "/erasures/j1", resp.Header.Get("Location"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"id":"j1","user_id":1,"status":"pending","created_at":"2024-01-02T03:04:05Z","updated_at":"2024-01-02T03:04:05Z"}`, string(body))
+
+		assert.Equal(t, http.StatusNotFound, postJSON(t, app, "/users/3/erasure", ``).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, app, "/users/abc/erasure", ``).StatusCode)
+	})
+
+	t.Run("GetErasure", func(t *testing.T) {
+		job := &domain.ErasureJob{ID: "j1", UserID: 1, Status: domain.ErasureFailed, Error: "boom", CreatedAt: now, UpdatedAt: now, CompletedAt: &now}
+		mockUsecase.On("GetErasure", mock.Anything, "j1").Return(job, nil).Once()
+		mockUsecase.On("GetErasure", mock.Anything, "j2").Return(nil, domain.ErrJobNotFound).Once()
+
+		resp := get("/erasures/j1")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var got domain.ErasureJob
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, domain.ErasureFailed, got.Status)
+		assert.Equal(t, "boom", got.Error)
+
+		assert.Equal(t, http.StatusNotFound, get("/erasures/j2").StatusCode)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const mimeProblemJSON = "application/problem+json"
+
+// problem is an RFC 9457 problem details object.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// writeError responds with err's status. Authentication, authorization and
+// rate limiting failures are reported as problem details; other errors keep
+// the plain {"error": ...} body.
+func writeError(c *fiber.Ctx, err error, fallback int) error {
+	status := getStatusCode(err, fallback)
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+	default:
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, domain.ErrUnauthenticated) {
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	}
+	var retry *domain.RetryAfterError
+	if errors.As(err, &retry) {
+		c.Set(fiber.HeaderRetryAfter, seconds(retry.RetryAfter))
+	}
+	return writeProblem(c, status, err)
+}
+
+func writeProblem(c *fiber.Ctx, status int, err error) error {
+	return c.Status(status).JSON(problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+	}, mimeProblemJSON)
+}
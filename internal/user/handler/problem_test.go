@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserHandler_AuthorizationErrors(t *testing.T) {
+	app := fiber.New()
+	mockUsecase := new(MockUserUsecase)
+	NewUserHandler(app, mockUsecase)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		err := fmt.Errorf("%w: user:1 may not delete user 2", domain.ErrForbidden)
+		mockUsecase.On("DeleteUser", mock.Anything, int64(2)).Return(err).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodDelete, "/users/2", nil))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, mimeProblemJSON, resp.Header.Get("Content-Type"))
+
+		var got problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, problem{
+			Type:   "about:blank",
+			Title:  "Forbidden",
+			Status: http.StatusForbidden,
+			Detail: "permission denied: user:1 may not delete user 2",
+		}, got)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockUsecase.On("GetUser", mock.Anything, int64(2)).Return(nil, domain.ErrUnauthenticated).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/users/2", nil))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+		assert.Equal(t, mimeProblemJSON, resp.Header.Get("Content-Type"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("OtherErrorsKeepPlainBody", func(t *testing.T) {
+		mockUsecase.On("GetUser", mock.Anything, int64(3)).Return(nil, domain.ErrNotFound).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/users/3", nil))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get("Content-Type"))
+		mockUsecase.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit counts each request against the limit for its client, refusing
+// requests over the limit with 429. The RateLimit-* headers follow the IETF
+// draft for rate limit header fields. Should the limiter fail, requests are
+// let through rather than taking the API down with it. Requests key returns
+// an empty string for are not limited.
+func RateLimit(limiter domain.RateLimiter, key func(c *fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		k := key(c)
+		if k == "" {
+			return c.Next()
+		}
+		limit, err := limiter.Allow(c.UserContext(), k)
+		if err != nil {
+			slog.WarnContext(c.UserContext(), "rate limiter failed; letting request through", "key", k, "error", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+		c.Set("RateLimit-Reset", seconds(limit.Reset))
+		if !limit.Allowed {
+			return writeError(c, &domain.RetryAfterError{RetryAfter: limit.RetryAfter}, fiber.StatusTooManyRequests)
+		}
+		return c.Next()
+	}
+}
+
+// ClientKey identifies the client by user or API key if the request is
+// authenticated, and by IP address otherwise. It relies on Authenticate
+// having run first.
+func ClientKey(c *fiber.Ctx) string {
+	if p, ok := domain.PrincipalFrom(c.UserContext()); ok {
+		return p.Subject
+	}
+	return "ip:" + c.IP()
+}
+
+// CredentialKey identifies requests that carry credentials by IP address,
+// so that they can be limited before Authenticate spends time checking them.
+// Requests without credentials are left to ClientKey.
+func CredentialKey(c *fiber.Ctx) string {
+	if c.Get(fiber.HeaderAuthorization) == "" {
+		return ""
+	}
+	return "credentials:ip:" + c.IP()
+}
+
+// seconds formats d as whole seconds, rounded up so that clients do not
+// retry too early.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
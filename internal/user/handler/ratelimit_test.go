@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// limiterFunc adapts a function to domain.RateLimiter.
+type limiterFunc func(ctx context.Context, key string) (domain.RateLimit, error)
+
+func (f limiterFunc) Allow(ctx context.Context, key string) (domain.RateLimit, error) {
+	return f(ctx, key)
+}
+
+func TestRateLimit(t *testing.T) {
+	var keys []string
+	results := map[string]domain.RateLimit{
+		"ip:0.0.0.0": {Allowed: true, Limit: 10, Remaining: 9, Reset: 1500 * time.Millisecond},
+		"user:7":     {Limit: 10, Reset: time.Minute, RetryAfter: 2500 * time.Millisecond},
+	}
+	limiter := limiterFunc(func(ctx context.Context, key string) (domain.RateLimit, error) {
+		keys = append(keys, key)
+		if limit, ok := results[key]; ok {
+			return limit, nil
+		}
+		return domain.RateLimit{}, errors.New("store unavailable")
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if subject := c.Get("X-Test-Subject"); subject != "" {
+			c.SetUserContext(domain.WithPrincipal(c.UserContext(), domain.Principal{Subject: subject}))
+		}
+		return c.Next()
+	})
+	app.Use(RateLimit(limiter, ClientKey))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+
+	request := func(subject string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if subject != "" {
+			req.Header.Set("X-Test-Subject", subject)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		resp := request("")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "10", resp.Header.Get("RateLimit-Limit"))
+		assert.Equal(t, "9", resp.Header.Get("RateLimit-Remaining"))
+		assert.Equal(t, "2", resp.Header.Get("RateLimit-Reset"))
+		assert.Empty(t, resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		resp := request("user:7")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, mimeProblemJSON, resp.Header.Get("Content-Type"))
+		assert.Equal(t, "3", resp.Header.Get("Retry-After"))
+		assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+	})
+
+	t.Run("LimiterFailure", func(t *testing.T) {
+		resp := request("apikey:abc")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	assert.Equal(t, []string{"ip:0.0.0.0", "user:7", "apikey:abc"}, keys)
+}
+
+func TestCredentialKey(t *testing.T) {
+	var keys []string
+	limiter := limiterFunc(func(ctx context.Context, key string) (domain.RateLimit, error) {
+		keys = append(keys, key)
+		return domain.RateLimit{Limit: 10, RetryAfter: time.Second}, nil
+	})
+	var authenticated int
+
+	app := fiber.New()
+	app.Use(RateLimit(limiter, CredentialKey))
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) != "" {
+			authenticated++
+		}
+		return c.Next()
+	})
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "a request without credentials is not limited")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer forged")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Zero(t, authenticated, "a refused request reached authentication")
+	assert.Equal(t, []string{"credentials:ip:0.0.0.0"}, keys)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for each request, continuing the trace of
+// the caller if it sent a W3C traceparent header. The span is carried by
+// the request's user context, so usecases called with it create child spans.
+func Tracing(tp trace.TracerProvider, propagator propagation.TextMapPropagator) fiber.Handler {
+	tracer := tp.Tracer("repo-guardian/internal/user/handler")
+	return func(c *fiber.Ctx) error {
+		ctx := propagator.Extract(c.UserContext(), headerCarrier{c})
+		// Fiber reuses the memory of these strings once the request is done,
+		// before the span is exported.
+		method := strings.Clone(c.Method())
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(strings.Clone(c.Path())),
+			))
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		self := c.Route()
+		err := c.Next()
+
+		route := routePattern(c, self)
+		status := responseStatus(c, err)
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		// Client errors are the client's; only server errors fail the span.
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return err
+	}
+}
+
+// headerCarrier reads propagation headers from the request.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return strings.Clone(h.c.Get(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	for key := range h.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
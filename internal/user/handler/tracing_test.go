@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	app := fiber.New()
+	app.Use(Tracing(tp, propagation.TraceContext{}))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		if !trace.SpanContextFromContext(c.UserContext()).IsValid() {
+			t.Error("handler context carries no span")
+		}
+		if c.Params("id") == "0" {
+			return c.SendStatus(http.StatusInternalServerError)
+		}
+		return c.SendStatus(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+	_, err = app.Test(httptest.NewRequest(http.MethodGet, "/users/0", nil))
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "GET /users/:id", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.True(t, span.Parent.IsRemote())
+	assert.Subset(t, span.Attributes, []attribute.KeyValue{
+		attribute.String("http.request.method", "GET"),
+		attribute.String("url.path", "/users/42"),
+		attribute.String("http.route", "/users/:id"),
+		attribute.Int("http.response.status_code", http.StatusNotFound),
+	})
+	assert.Equal(t, codes.Unset, span.Status.Code, "client errors do not fail the span")
+
+	assert.False(t, spans[1].Parent.IsValid(), "a request without traceparent starts a trace")
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
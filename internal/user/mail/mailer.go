@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type SMTPConfig struct {
+	// Addr is the host:port of the SMTP server.
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer returns a mailer that delivers through an SMTP server,
+// authenticating with PLAIN auth when a username is set. net/smtp only
+// permits PLAIN auth over TLS or to localhost.
+func NewSMTPMailer(cfg SMTPConfig) domain.Mailer {
+	return &smtpMailer{cfg: cfg}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg domain.Message) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(m.cfg.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- smtp.SendMail(m.cfg.Addr, auth, m.cfg.From, []string{msg.To}, format(m.cfg.From, msg, time.Now()))
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type writerMailer struct {
+	mu   sync.Mutex
+	from string
+	w    io.Writer
+}
+
+// NewWriterMailer returns a mailer that writes each message to w instead of
+// delivering it, for local development and tests.
+func NewWriterMailer(from string, w io.Writer) domain.Mailer {
+	return &writerMailer{from: from, w: w}
+}
+
+// NewFileMailer appends messages to the file at path.
+func NewFileMailer(from, path string) (domain.Mailer, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterMailer(from, f), nil
+}
+
+// NewLogMailer logs messages with the standard logger.
+func NewLogMailer(from string) domain.Mailer {
+	return NewWriterMailer(from, log.Writer())
+}
+
+func (m *writerMailer) Send(ctx context.Context, msg domain.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.w, "%s\r\n", format(m.from, msg, time.Now()))
+	return err
+}
+
+// format renders msg as an RFC 5322 message. Header values are stripped of
+// line breaks so that they cannot inject headers.
+func format(from string, msg domain.Message, date time.Time) []byte {
+	clean := strings.NewReplacer("\r", "", "\n", "")
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", clean.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", clean.Replace(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", clean.Replace(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,73 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestWriterMailer(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewWriterMailer("no-reply@example.com", &buf)
+
+	err := m.Send(context.Background(), domain.Message{
+		To:      "john@example.com",
+		Subject: "Hello",
+		Body:    "line one\nline two\n",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"From: no-reply@example.com\r\n",
+		"To: john@example.com\r\n",
+		"Subject: Hello\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Send() wrote %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestFormat_HeaderInjection(t *testing.T) {
+	msg := format("a@example.com", domain.Message{
+		To:      "john@example.com\r\nBcc: victim@example.com",
+		Subject: "Hi\nX-Injected: yes",
+	}, time.Unix(0, 0))
+
+	headers, _, _ := strings.Cut(string(msg), "\r\n\r\n")
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") || strings.HasPrefix(line, "X-Injected:") {
+			t.Errorf("format() let a header through: %q", line)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.txt")
+	m, err := NewFileMailer("no-reply@example.com", path)
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+	for _, subject := range []string{"first", "second"} {
+		if err := m.Send(context.Background(), domain.Message{To: "john@example.com", Subject: subject}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "Subject: first") || !strings.Contains(string(raw), "Subject: second") {
+		t.Errorf("file contains %q, want both messages", raw)
+	}
+}
@@ -0,0 +1,47 @@
+// Package metrics instruments the user service for Prometheus.
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"repo-guardian/internal/domain"
+)
+
+// errorKinds label failures by the domain error they wrap, most specific
+// first.
+var errorKinds = []struct {
+	err  error
+	kind string
+}{
+	{domain.ErrNotFound, "not_found"},
+	{domain.ErrConflict, "conflict"},
+	{domain.ErrVersionConflict, "version_conflict"},
+	{domain.ErrBadParamInput, "bad_param"},
+	{domain.ErrImmutableField, "immutable_field"},
+	{domain.ErrNotDeleted, "not_deleted"},
+	{domain.ErrWeakPassword, "weak_password"},
+	{domain.ErrUnauthenticated, "unauthenticated"},
+	{domain.ErrForbidden, "forbidden"},
+	{context.DeadlineExceeded, "timeout"},
+	{context.Canceled, "canceled"},
+}
+
+// errorKind returns the label of err, "internal" for errors that are not
+// domain errors.
+func errorKind(err error) string {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return "internal"
+}
+
+// outcome labels an operation "ok", or by the kind of its error.
+func outcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return errorKind(err)
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type userRepository struct {
+	next     domain.UserRepository
+	duration *prometheus.HistogramVec
+}
+
+// NewUserRepository records the latency of every call to next, by operation
+// and outcome, in user_repository_duration_seconds.
+func NewUserRepository(next domain.UserRepository, reg prometheus.Registerer, buckets []float64) domain.UserRepository {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "user_repository_duration_seconds",
+		Help:    "Latency of user repository operations.",
+		Buckets: buckets,
+	}, []string{"operation", "outcome"})
+	reg.MustRegister(duration)
+	return &userRepository{next: next, duration: duration}
+}
+
+func (r *userRepository) observe(operation string, start time.Time, err error) {
+	r.duration.WithLabelValues(operation, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	start := time.Now()
+	err := r.next.Create(ctx, user)
+	r.observe("create", start, err)
+	return err
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	start := time.Now()
+	user, err := r.next.GetByID(ctx, id)
+	r.observe("get_by_id", start, err)
+	return user, err
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	start := time.Now()
+	user, err := r.next.GetByUsername(ctx, username)
+	r.observe("get_by_username", start, err)
+	return user, err
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	start := time.Now()
+	err := r.next.Update(ctx, user)
+	r.observe("update", start, err)
+	return err
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int64, at time.Time) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id, at)
+	r.observe("delete", start, err)
+	return err
+}
+
+func (r *userRepository) List(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	start := time.Now()
+	page, err := r.next.List(ctx, opts)
+	r.observe("list", start, err)
+	return page, err
+}
+
+func (r *userRepository) Restore(ctx context.Context, id int64) (*domain.User, error) {
+	start := time.Now()
+	user, err := r.next.Restore(ctx, id)
+	r.observe("restore", start, err)
+	return user, err
+}
+
+func (r *userRepository) VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error {
+	start := time.Now()
+	err := r.next.VerifyEmail(ctx, id, email, at)
+	r.observe("verify_email", start, err)
+	return err
+}
+
+func (r *userRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	start := time.Now()
+	n, err := r.next.Purge(ctx, cutoff)
+	r.observe("purge", start, err)
+	return n, err
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, id int64, at time.Time) (*domain.User, error) {
+	start := time.Now()
+	user, err := r.next.Anonymize(ctx, id, at)
+	r.observe("anonymize", start, err)
+	return user, err
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	repo := NewUserRepository(repository.NewMemoryUserRepository(), reg, []float64{1})
+	ctx := context.Background()
+
+	assert.NoError(t, repo.Create(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com"}))
+	_, err := repo.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	_, err = repo.GetByID(ctx, 2)
+	assert.ErrorIs(t, err, domain.ErrNotFound, "errors pass through")
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP user_repository_duration_seconds Latency of user repository operations.
+# TYPE user_repository_duration_seconds histogram
+user_repository_duration_seconds_bucket{operation="create",outcome="ok",le="1"} 1
+user_repository_duration_seconds_bucket{operation="create",outcome="ok",le="+Inf"} 1
+user_repository_duration_seconds_count{operation="create",outcome="ok"} 1
+user_repository_duration_seconds_bucket{operation="get_by_id",outcome="not_found",le="1"} 1
+user_repository_duration_seconds_bucket{operation="get_by_id",outcome="not_found",le="+Inf"} 1
+user_repository_duration_seconds_count{operation="get_by_id",outcome="not_found"} 1
+user_repository_duration_seconds_bucket{operation="get_by_id",outcome="ok",le="1"} 1
+user_repository_duration_seconds_bucket{operation="get_by_id",outcome="ok",le="+Inf"} 1
+user_repository_duration_seconds_count{operation="get_by_id",outcome="ok"} 1
+`), "user_repository_duration_seconds_bucket", "user_repository_duration_seconds_count")
+	assert.NoError(t, err)
+}
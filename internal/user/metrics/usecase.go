@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type userUsecase struct {
+	next          domain.UserUsecase
+	registrations prometheus.Counter
+	deletions     prometheus.Counter
+	failures      *prometheus.CounterVec
+}
+
+// NewUserUsecase counts the users registered and deleted through next, and
+// its failures by operation and kind of domain error.
+func NewUserUsecase(next domain.UserUsecase, reg prometheus.Registerer) domain.UserUsecase {
+	u := &userUsecase{
+		next: next,
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "user_registrations_total",
+			Help: "Users registered.",
+		}),
+		deletions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "user_deletions_total",
+			Help: "Users deleted.",
+		}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "user_usecase_failures_total",
+			Help: "Failed user operations, by operation and error.",
+		}, []string{"operation", "error"}),
+	}
+	reg.MustRegister(u.registrations, u.deletions, u.failures)
+	return u
+}
+
+func (u *userUsecase) count(operation string, err error, success prometheus.Counter) {
+	if err != nil {
+		u.failures.WithLabelValues(operation, errorKind(err)).Inc()
+	} else if success != nil {
+		success.Inc()
+	}
+}
+
+func (u *userUsecase) Register(ctx context.Context, user *domain.User) error {
+	err := u.next.Register(ctx, user)
+	u.count("register", err, u.registrations)
+	return err
+}
+
+func (u *userUsecase) GetUser(ctx context.Context, id int64) (*domain.User, error) {
+	user, err := u.next.GetUser(ctx, id)
+	u.count("get", err, nil)
+	return user, err
+}
+
+func (u *userUsecase) UpdateUser(ctx context.Context, user *domain.User) error {
+	err := u.next.UpdateUser(ctx, user)
+	u.count("update", err, nil)
+	return err
+}
+
+func (u *userUsecase) PatchUser(ctx context.Context, id int64, patch domain.UserPatch) (*domain.User, error) {
+	user, err := u.next.PatchUser(ctx, id, patch)
+	u.count("patch", err, nil)
+	return user, err
+}
+
+func (u *userUsecase) DeleteUser(ctx context.Context, id int64) error {
+	err := u.next.DeleteUser(ctx, id)
+	u.count("delete", err, u.deletions)
+	return err
+}
+
+func (u *userUsecase) ListUsers(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	page, err := u.next.ListUsers(ctx, opts)
+	u.count("list", err, nil)
+	return page, err
+}
+
+func (u *userUsecase) RestoreUser(ctx context.Context, id int64) (*domain.User, error) {
+	user, err := u.next.RestoreUser(ctx, id)
+	u.count("restore", err, nil)
+	return user, err
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubUsecase fails every call with err.
+type stubUsecase struct {
+	domain.UserUsecase
+	err error
+}
+
+func (s *stubUsecase) Register(ctx context.Context, user *domain.User) error { return s.err }
+func (s *stubUsecase) DeleteUser(ctx context.Context, id int64) error        { return s.err }
+
+func TestUserUsecase(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	stub := &stubUsecase{}
+	u := NewUserUsecase(stub, reg).(*userUsecase)
+	ctx := context.Background()
+
+	assert.NoError(t, u.Register(ctx, &domain.User{}))
+	assert.NoError(t, u.Register(ctx, &domain.User{}))
+	assert.NoError(t, u.DeleteUser(ctx, 1))
+
+	for _, err := range []error{
+		fmt.Errorf("%w: username is required", domain.ErrBadParamInput),
+		domain.ErrConflict,
+		errors.New("disk full"),
+		context.DeadlineExceeded,
+	} {
+		stub.err = err
+		assert.ErrorIs(t, u.Register(ctx, &domain.User{}), err)
+	}
+	stub.err = domain.ErrForbidden
+	assert.ErrorIs(t, u.DeleteUser(ctx, 1), domain.ErrForbidden)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(u.registrations))
+	assert.Equal(t, 1.0, testutil.ToFloat64(u.deletions))
+	for labels, want := range map[[2]string]float64{
+		{"register", "bad_param"}: 1,
+		{"register", "conflict"}:  1,
+		{"register", "internal"}:  1,
+		{"register", "timeout"}:   1,
+		{"delete", "forbidden"}:   1,
+	} {
+		assert.Equal(t, want, testutil.ToFloat64(u.failures.WithLabelValues(labels[0], labels[1])), labels)
+	}
+	assert.Equal(t, 5, testutil.CollectAndCount(u.failures))
+}
@@ -0,0 +1,89 @@
+package mfa
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key from the RFC 6238 test vectors.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTP_Code_RFC6238(t *testing.T) {
+	totp := NewTOTP("repo-guardian").(*totp)
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		if got := totp.code(rfc6238Secret, totp.step(time.Unix(tt.unix, 0))); got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	totp := NewTOTP("repo-guardian")
+	at := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name     string
+		code     string
+		at       time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: "050471", at: at, wantStep: 37037037, wantOK: true},
+		{name: "surrounding whitespace", code: " 050471 ", at: at, wantStep: 37037037, wantOK: true},
+		{name: "one step behind", code: "050471", at: at.Add(30 * time.Second), wantStep: 37037037, wantOK: true},
+		{name: "one step ahead", code: "050471", at: at.Add(-30 * time.Second), wantStep: 37037037, wantOK: true},
+		{name: "too old", code: "050471", at: at.Add(90 * time.Second)},
+		{name: "wrong code", code: "123456", at: at},
+		{name: "wrong length", code: "05047", at: at},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := totp.Validate(rfc6238Secret, tt.code, tt.at)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTP_GenerateSecret(t *testing.T) {
+	totp := NewTOTP("repo-guardian")
+	a, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	b, _ := totp.GenerateSecret()
+	if len(a) != 20 || string(a) == string(b) {
+		t.Errorf("GenerateSecret() = %x, %x, want distinct 20 byte secrets", a, b)
+	}
+}
+
+func TestTOTP_URI(t *testing.T) {
+	uri := NewTOTP("repo-guardian").URI("john", rfc6238Secret)
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("URI() = %q: %v", uri, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/repo-guardian:john" {
+		t.Errorf("URI() = %q, want otpauth://totp/repo-guardian:john", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" || strings.Contains(q.Get("secret"), "=") {
+		t.Errorf("secret = %q", q.Get("secret"))
+	}
+	if q.Get("issuer") != "repo-guardian" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("URI() parameters = %v", q)
+	}
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// maxAttempts bounds how often the token bucket retries a compare-and-swap
+// that lost against a concurrent request.
+const maxAttempts = 8
+
+type tokenBucket struct {
+	store    domain.RateLimitStore
+	capacity int
+	interval time.Duration
+	clock    domain.Clock
+}
+
+// NewTokenBucket allows bursts of up to capacity requests per key and
+// refills one token every interval. It is implemented as GCRA, which keeps a
+// single timestamp per key instead of a token count.
+func NewTokenBucket(store domain.RateLimitStore, capacity int, interval time.Duration, clock domain.Clock) domain.RateLimiter {
+	return &tokenBucket{store: store, capacity: capacity, interval: interval, clock: clock}
+}
+
+func (b *tokenBucket) Allow(ctx context.Context, key string) (domain.RateLimit, error) {
+	key = "bucket:" + key
+	burst := time.Duration(b.capacity) * b.interval
+	for range maxAttempts {
+		now := b.clock.Now()
+		stored, err := b.store.Get(ctx, key)
+		if err != nil {
+			return domain.RateLimit{}, err
+		}
+		// tat is the theoretical arrival time: when the bucket will be
+		// full again.
+		tat := time.Unix(0, stored)
+		if tat.Before(now) {
+			tat = now
+		}
+		next := tat.Add(b.interval)
+		if allowAt := next.Add(-burst); now.Before(allowAt) {
+			return domain.RateLimit{
+				Limit:      b.capacity,
+				Reset:      tat.Sub(now),
+				RetryAfter: allowAt.Sub(now),
+			}, nil
+		}
+
+		ok, err := b.store.CompareAndSwap(ctx, key, stored, next.UnixNano(), next.Sub(now))
+		if err != nil {
+			return domain.RateLimit{}, err
+		}
+		if ok {
+			return domain.RateLimit{
+				Allowed:   true,
+				Limit:     b.capacity,
+				Remaining: int((burst - next.Sub(now)) / b.interval),
+				Reset:     next.Sub(now),
+			}, nil
+		}
+	}
+	return domain.RateLimit{}, fmt.Errorf("ratelimit: too many concurrent updates of %s", key)
+}
+
+type slidingWindow struct {
+	store  domain.RateLimitStore
+	limit  int
+	window time.Duration
+	clock  domain.Clock
+}
+
+// NewSlidingWindow allows limit requests per key in any window of the given
+// length. It estimates the count from the current and previous fixed
+// windows, weighting the previous one by how much of it still overlaps.
+func NewSlidingWindow(store domain.RateLimitStore, limit int, window time.Duration, clock domain.Clock) domain.RateLimiter {
+	return &slidingWindow{store: store, limit: limit, window: window, clock: clock}
+}
+
+func (w *slidingWindow) Allow(ctx context.Context, key string) (domain.RateLimit, error) {
+	now := w.clock.Now()
+	current := now.UnixNano() / int64(w.window)
+	elapsed := time.Duration(now.UnixNano() - current*int64(w.window))
+	prefix := "window:" + key + ":"
+	currentKey := prefix + strconv.FormatInt(current, 10)
+
+	previous, err := w.store.Get(ctx, prefix+strconv.FormatInt(current-1, 10))
+	if err != nil {
+		return domain.RateLimit{}, err
+	}
+	count, err := w.store.Incr(ctx, currentKey, 1, 2*w.window)
+	if err != nil {
+		return domain.RateLimit{}, err
+	}
+
+	weight := 1 - float64(elapsed)/float64(w.window)
+	estimate := float64(previous)*weight + float64(count)
+	result := domain.RateLimit{Limit: w.limit, Reset: w.window - elapsed}
+	if estimate > float64(w.limit) {
+		// Refused requests do not count.
+		if _, err := w.store.Incr(ctx, currentKey, -1, 2*w.window); err != nil {
+			return domain.RateLimit{}, err
+		}
+		result.RetryAfter = w.retryAfter(previous, count-1, elapsed)
+		return result, nil
+	}
+	result.Allowed = true
+	result.Remaining = int(math.Floor(float64(w.limit) - estimate))
+	return result, nil
+}
+
+// retryAfter returns how long until the estimate leaves room for another
+// request, given the counts of the previous and current window.
+func (w *slidingWindow) retryAfter(previous, count int64, elapsed time.Duration) time.Duration {
+	room := float64(int64(w.limit) - 1 - count)
+	if room >= 0 && previous > 0 {
+		at := time.Duration(math.Ceil(float64(w.window) * (1 - room/float64(previous))))
+		return max(at-elapsed, 0)
+	}
+	// The current window alone is full, so its count has to decay in the
+	// next one.
+	at := time.Duration(math.Ceil(float64(w.window) * (1 - float64(w.limit-1)/float64(count))))
+	return w.window - elapsed + at
+}
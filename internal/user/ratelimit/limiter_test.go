@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	clock := newManualClock()
+	b := NewTokenBucket(NewMemoryStore(clock), 3, time.Second, clock)
+	ctx := context.Background()
+
+	for want := 2; want >= 0; want-- {
+		got, err := b.Allow(ctx, "client")
+		if err != nil || !got.Allowed || got.Remaining != want {
+			t.Fatalf("Allow() = %+v, %v, want allowed with %d remaining", got, err, want)
+		}
+	}
+	got, _ := b.Allow(ctx, "client")
+	if got.Allowed || got.RetryAfter != time.Second || got.Reset != 3*time.Second {
+		t.Errorf("Allow() over capacity = %+v, want refused for 1s", got)
+	}
+	if other, _ := b.Allow(ctx, "other"); !other.Allowed {
+		t.Errorf("Allow() for another key = %+v, want allowed", other)
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	if got, _ := b.Allow(ctx, "client"); !got.Allowed || got.Remaining != 0 {
+		t.Errorf("Allow() after refilling one token = %+v", got)
+	}
+	clock.Advance(time.Hour)
+	if got, _ := b.Allow(ctx, "client"); !got.Allowed || got.Remaining != 2 {
+		t.Errorf("Allow() after a long pause = %+v, want a full bucket", got)
+	}
+}
+
+func TestTokenBucket_Concurrent(t *testing.T) {
+	clock := newManualClock()
+	b := NewTokenBucket(NewMemoryStore(clock), 10, time.Hour, clock)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	allowed := 0
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				got, err := b.Allow(context.Background(), "client")
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				if got.Allowed {
+					allowed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 10 {
+		t.Errorf("allowed %d requests, want the capacity of 10", allowed)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := newManualClock()
+	w := NewSlidingWindow(NewMemoryStore(clock), 4, time.Minute, clock)
+	ctx := context.Background()
+
+	for want := 3; want >= 0; want-- {
+		got, err := w.Allow(ctx, "client")
+		if err != nil || !got.Allowed || got.Remaining != want {
+			t.Fatalf("Allow() = %+v, %v, want allowed with %d remaining", got, err, want)
+		}
+	}
+	got, _ := w.Allow(ctx, "client")
+	if got.Allowed || got.Reset != time.Minute {
+		t.Errorf("Allow() over the limit = %+v, want refused", got)
+	}
+	// The four requests decay through the next window: one slot frees up
+	// once a quarter of it has passed.
+	if want := time.Minute + 15*time.Second; got.RetryAfter != want {
+		t.Errorf("RetryAfter = %v, want %v", got.RetryAfter, want)
+	}
+
+	clock.Advance(time.Minute + 10*time.Second)
+	if got, _ := w.Allow(ctx, "client"); got.Allowed {
+		t.Errorf("Allow() before RetryAfter = %+v, want refused", got)
+	} else if got.RetryAfter != 5*time.Second {
+		t.Errorf("RetryAfter = %v, want 5s", got.RetryAfter)
+	}
+	clock.Advance(5 * time.Second)
+	if got, _ := w.Allow(ctx, "client"); !got.Allowed {
+		t.Errorf("Allow() after RetryAfter = %+v, want allowed", got)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if got, _ := w.Allow(ctx, "client"); !got.Allowed || got.Remaining != 3 {
+		t.Errorf("Allow() after two idle windows = %+v, want a fresh limit", got)
+	}
+}
+
+func TestSlidingWindow_RefusedRequestsDoNotCount(t *testing.T) {
+	clock := newManualClock()
+	w := NewSlidingWindow(NewMemoryStore(clock), 1, time.Minute, clock)
+	ctx := context.Background()
+
+	w.Allow(ctx, "client")
+	for range 5 {
+		w.Allow(ctx, "client")
+	}
+	clock.Advance(2 * time.Minute)
+	if got, _ := w.Allow(ctx, "client"); !got.Allowed {
+		t.Errorf("Allow() = %+v, want refused requests to have been discounted", got)
+	}
+}
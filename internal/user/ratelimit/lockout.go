@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// failureMemory is how long failed attempts count towards a lockout.
+const failureMemory = 24 * time.Hour
+
+type lockout struct {
+	store      domain.RateLimitStore
+	threshold  int
+	base       time.Duration
+	maxLockout time.Duration
+	clock      domain.Clock
+}
+
+// NewLockout locks a key out for base once it has threshold failed attempts,
+// doubling the lockout with every further failure up to maxLockout.
+func NewLockout(store domain.RateLimitStore, threshold int, base, maxLockout time.Duration, clock domain.Clock) domain.LoginThrottle {
+	return &lockout{store: store, threshold: threshold, base: base, maxLockout: maxLockout, clock: clock}
+}
+
+func (l *lockout) Check(ctx context.Context, key string) (time.Duration, error) {
+	until, err := l.store.Get(ctx, "lockout:"+key)
+	if err != nil {
+		return 0, err
+	}
+	return max(time.Unix(0, until).Sub(l.clock.Now()), 0), nil
+}
+
+func (l *lockout) Fail(ctx context.Context, key string) (time.Duration, error) {
+	failures, err := l.store.Incr(ctx, "failures:"+key, 1, failureMemory)
+	if err != nil {
+		return 0, err
+	}
+	if failures < int64(l.threshold) {
+		return 0, nil
+	}
+
+	d := l.base
+	for i := int64(l.threshold); i < failures && d < l.maxLockout; i++ {
+		d *= 2
+	}
+	d = min(d, l.maxLockout)
+	until := l.clock.Now().Add(d)
+	if err := l.store.Set(ctx, "lockout:"+key, until.UnixNano(), d); err != nil {
+		return 0, err
+	}
+	return d, nil
+}
+
+func (l *lockout) Reset(ctx context.Context, key string) error {
+	return l.store.Delete(ctx, "failures:"+key)
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLockout(t *testing.T) {
+	clock := newManualClock()
+	l := NewLockout(NewMemoryStore(clock), 3, time.Minute, 5*time.Minute, clock)
+	ctx := context.Background()
+
+	for range 2 {
+		if d, err := l.Fail(ctx, "john"); err != nil || d != 0 {
+			t.Fatalf("Fail() below the threshold = %v, %v, want no lockout", d, err)
+		}
+	}
+	if d, _ := l.Check(ctx, "john"); d != 0 {
+		t.Errorf("Check() below the threshold = %v, want 0", d)
+	}
+
+	wants := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for _, want := range wants {
+		if d, _ := l.Fail(ctx, "john"); d != want {
+			t.Errorf("Fail() = %v, want %v", d, want)
+		}
+	}
+	clock.Advance(time.Minute)
+	if d, _ := l.Check(ctx, "john"); d != 4*time.Minute {
+		t.Errorf("Check() = %v, want 4m", d)
+	}
+	if d, _ := l.Check(ctx, "jane"); d != 0 {
+		t.Errorf("Check() for another key = %v, want 0", d)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if d, _ := l.Check(ctx, "john"); d != 0 {
+		t.Errorf("Check() after the lockout = %v, want 0", d)
+	}
+	if err := l.Reset(ctx, "john"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if d, _ := l.Fail(ctx, "john"); d != 0 {
+		t.Errorf("Fail() after Reset() = %v, want the count to start over", d)
+	}
+}
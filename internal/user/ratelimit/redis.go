@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// casScript sets KEYS[1] to ARGV[2] with a ttl of ARGV[3] milliseconds if it
+// holds ARGV[1]. Values are compared as the decimal strings they are stored
+// as, since Lua numbers cannot hold every int64.
+var casScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1]) or '0'
+if current ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore returns a store shared by every instance using the same
+// Redis. Keys are prefixed with prefix, so that other data can live in the
+// same database. Expiry follows the Redis server's clock.
+func NewRedisStore(client redis.UniversalClient, prefix string) domain.RateLimitStore {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	key = s.prefix + key
+	var incr *redis.IntCmd
+	// Creating the key with its ttl first keeps the ttl from being extended
+	// by later increments, and from going missing should INCRBY create it.
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, millis(ttl))
+		incr = pipe.IncrBy(ctx, key, delta)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (int64, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return value, err
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, millis(ttl)).Err()
+}
+
+func (s *redisStore) CompareAndSwap(ctx context.Context, key string, old, new int64, ttl time.Duration) (bool, error) {
+	swapped, err := casScript.Run(ctx, s.client, []string{s.prefix + key},
+		strconv.FormatInt(old, 10), strconv.FormatInt(new, 10), millis(ttl).Milliseconds()).Int()
+	return swapped == 1, err
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// CheckHealth pings Redis.
+func (s *redisStore) CheckHealth(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// Close closes the client the store was made with.
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+// millis rounds ttl up to whole milliseconds, which Redis expires keys in,
+// so that a shorter ttl does not turn into no expiry at all.
+func millis(ttl time.Duration) time.Duration {
+	return max((ttl + time.Millisecond - 1).Truncate(time.Millisecond), time.Millisecond)
+}
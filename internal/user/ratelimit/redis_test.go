@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T) (*redisStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, "ratelimit:").(*redisStore), mr
+}
+
+func TestRedisStore(t *testing.T) {
+	s, mr := newTestRedisStore(t)
+	testStore(t, s, mr.FastForward)
+
+	ctx := context.Background()
+	s.Incr(ctx, "f", 1, time.Minute)
+	if !mr.Exists("ratelimit:f") {
+		t.Errorf("keys = %v, want them prefixed", mr.Keys())
+	}
+	if ttl := mr.TTL("ratelimit:f"); ttl != time.Minute {
+		t.Errorf("TTL() after Incr() = %v, want 1m", ttl)
+	}
+	s.Set(ctx, "g", 1, time.Microsecond)
+	if ttl := mr.TTL("ratelimit:g"); ttl != time.Millisecond {
+		t.Errorf("TTL() after Set() with 1µs = %v, want 1ms rather than none", ttl)
+	}
+
+	if err := s.CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth() error = %v", err)
+	}
+	mr.Close()
+	if err := s.CheckHealth(ctx); err == nil {
+		t.Errorf("CheckHealth() with Redis down succeeded")
+	}
+	if _, err := s.Incr(ctx, "a", 1, time.Minute); err == nil {
+		t.Errorf("Incr() with Redis down succeeded")
+	}
+}
+
+func TestRedisStore_Concurrent(t *testing.T) {
+	s, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var swapped atomic.Int32
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				if _, err := s.Incr(ctx, "counter", 1, time.Minute); err != nil {
+					t.Error(err)
+				}
+			}
+			if ok, err := s.CompareAndSwap(ctx, "cas", 0, 1, time.Minute); err != nil {
+				t.Error(err)
+			} else if ok {
+				swapped.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, _ := s.Get(ctx, "counter"); got != 80 {
+		t.Errorf("Get() after 80 concurrent increments = %d", got)
+	}
+	if n := swapped.Load(); n != 1 {
+		t.Errorf("%d concurrent CompareAndSwap() calls from 0 succeeded, want 1", n)
+	}
+}
+
+func TestRedisStore_Lockout(t *testing.T) {
+	s, _ := newTestRedisStore(t)
+	clock := newManualClock()
+	l := NewLockout(s, 2, time.Minute, time.Hour, clock)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := l.Fail(ctx, "john"); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+	}
+	if d, err := l.Check(ctx, "john"); err != nil || d != time.Minute {
+		t.Errorf("Check() = %v, %v, want locked out for 1m", d, err)
+	}
+	if d, err := l.Fail(ctx, "john"); err != nil || d != 2*time.Minute {
+		t.Errorf("Fail() while locked out = %v, %v, want the lockout doubled", d, err)
+	}
+	if d, _ := l.Check(ctx, "jane"); d != 0 {
+		t.Errorf("Check() for another key = %v, want 0", d)
+	}
+}
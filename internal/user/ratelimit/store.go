@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// pruneInterval is how often the memory store drops expired keys.
+const pruneInterval = time.Minute
+
+type entry struct {
+	value   int64
+	expires time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	clock     domain.Clock
+	lastPrune time.Time
+}
+
+// NewMemoryStore returns a store local to this process. It suits a single
+// instance and tests; instances behind a load balancer need a shared store.
+func NewMemoryStore(clock domain.Clock) domain.RateLimitStore {
+	return &memoryStore{
+		entries: make(map[string]entry),
+		clock:   clock,
+	}
+}
+
+func (s *memoryStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.prune()
+	e, ok := s.get(key, now)
+	if !ok {
+		e.expires = now.Add(ttl)
+	}
+	e.value += delta
+	s.entries[key] = e
+	return e.value, nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, _ := s.get(key, s.clock.Now())
+	return e.value, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.prune()
+	s.entries[key] = entry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) CompareAndSwap(ctx context.Context, key string, old, new int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.prune()
+	if e, _ := s.get(key, now); e.value != old {
+		return false, nil
+	}
+	s.entries[key] = entry{value: new, expires: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *memoryStore) get(key string, now time.Time) (entry, bool) {
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// prune drops expired keys at most once per pruneInterval and returns the
+// current time.
+func (s *memoryStore) prune() time.Time {
+	now := s.clock.Now()
+	if now.Sub(s.lastPrune) < pruneInterval {
+		return now
+	}
+	for key, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastPrune = now
+	return now
+}
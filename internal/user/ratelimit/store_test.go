@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// manualClock is a clock tests move forward by hand.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)}
+}
+
+func TestMemoryStore(t *testing.T) {
+	clock := newManualClock()
+	s := NewMemoryStore(clock)
+	testStore(t, s, clock.Advance)
+
+	clock.Advance(2 * time.Minute)
+	s.Set(context.Background(), "d", 1, time.Minute)
+	if n := len(s.(*memoryStore).entries); n != 1 {
+		t.Errorf("store kept %d entries, want expired ones pruned", n)
+	}
+}
+
+// testStore checks the behaviour every RateLimitStore shares. advance moves
+// the time the store expires keys by forward.
+func testStore(t *testing.T, s domain.RateLimitStore, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+
+	if got, _ := s.Incr(ctx, "a", 2, time.Minute); got != 2 {
+		t.Errorf("Incr() = %d, want 2", got)
+	}
+	advance(30 * time.Second)
+	if got, _ := s.Incr(ctx, "a", 1, time.Hour); got != 3 {
+		t.Errorf("Incr() = %d, want 3", got)
+	}
+	advance(30 * time.Second)
+	if got, _ := s.Get(ctx, "a"); got != 0 {
+		t.Errorf("Get() after the first ttl = %d, want 0", got)
+	}
+
+	if ok, _ := s.CompareAndSwap(ctx, "b", 1, 2, time.Minute); ok {
+		t.Errorf("CompareAndSwap() of a missing key against 1 succeeded")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "b", 0, 2, time.Minute); !ok {
+		t.Errorf("CompareAndSwap() of a missing key against 0 failed")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "b", 0, 3, time.Minute); ok {
+		t.Errorf("CompareAndSwap() against a stale value succeeded")
+	}
+
+	if err := s.Set(ctx, "c", 7, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := s.Delete(ctx, "c"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, _ := s.Get(ctx, "c"); got != 0 {
+		t.Errorf("Get() after Delete() = %d, want 0", got)
+	}
+
+	// Limiters keep timestamps in nanoseconds.
+	big := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC).UnixNano()
+	if err := s.Set(ctx, "e", big, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "e", big+1, 0, time.Minute); ok {
+		t.Errorf("CompareAndSwap() against a value differing in the last digit succeeded")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "e", big, big+1, time.Minute); !ok {
+		t.Errorf("CompareAndSwap() of a large value failed")
+	}
+	if got, _ := s.Get(ctx, "e"); got != big+1 {
+		t.Errorf("Get() = %d, want %d", got, big+1)
+	}
+	advance(time.Minute)
+	if got, _ := s.Get(ctx, "e"); got != 0 {
+		t.Errorf("Get() after the ttl of a swap = %d, want 0", got)
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type memoryAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]*domain.APIKey
+}
+
+func NewMemoryAPIKeyRepository() domain.APIKeyRepository {
+	return &memoryAPIKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; exists {
+		return domain.ErrConflict
+	}
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *memoryAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	keys := make([]*domain.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		copied := *key
+		keys = append(keys, &copied)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(keys, func(a, b *domain.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &at
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestMemoryAPIKeyRepository(t *testing.T) {
+	r := NewMemoryAPIKeyRepository()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := &domain.APIKey{ID: "a", Name: "first", Hash: "h1", CreatedAt: now}
+	if err := r.Create(ctx, first); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := r.Create(ctx, first); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create() duplicate error = %v, want %v", err, domain.ErrConflict)
+	}
+	if err := r.Create(ctx, &domain.APIKey{ID: "b", Name: "second", CreatedAt: now.Add(time.Second)}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	first.Name = "mutated"
+	got, err := r.GetByID(ctx, "a")
+	if err != nil || got.Name != "first" {
+		t.Errorf("GetByID() = %+v, %v, want the key as created", got, err)
+	}
+	if _, err := r.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("GetByID() error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+
+	if err := r.Touch(ctx, "a", now); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if err := r.Revoke(ctx, "a", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := r.Revoke(ctx, "a", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke() twice error = %v", err)
+	}
+	if err := r.Revoke(ctx, "missing", now); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke() error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+
+	keys, err := r.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "a" || keys[1].ID != "b" {
+		t.Fatalf("List() = %+v, want keys a and b in creation order", keys)
+	}
+	if keys[0].LastUsedAt == nil || !keys[0].LastUsedAt.Equal(now) {
+		t.Errorf("LastUsedAt = %v, want %v", keys[0].LastUsedAt, now)
+	}
+	if keys[0].RevokedAt == nil || !keys[0].RevokedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("RevokedAt = %v, want the first revocation time", keys[0].RevokedAt)
+	}
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"testing"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/repository/repotest"
+)
+
+func TestMemoryUserRepository_Contract(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) domain.UserRepository {
+		return NewMemoryUserRepository()
+	})
+}
+
+func TestShardedMemoryUserRepository_Contract(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) domain.UserRepository {
+		return NewShardedMemoryUserRepository(4)
+	})
+}
+
+func TestDurableUserRepository_Contract(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) domain.UserRepository {
+		return openTestRepository(t, t.TempDir(), WithFsync(FsyncNever, 0))
+	})
+}
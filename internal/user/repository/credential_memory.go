@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"repo-guardian/internal/domain"
+)
+
+type memoryCredentialRepository struct {
+	mu          sync.RWMutex
+	credentials map[int64]domain.Credential
+	// journal, if set, records every change before it is applied.
+	journal credentialJournal
+}
+
+// credentialJournal records each credential stored, or its deletion. A
+// change it fails to record is not applied.
+type credentialJournal interface {
+	putCredential(cred *domain.Credential) error
+	deleteCredential(userID int64) error
+}
+
+func NewMemoryCredentialRepository() domain.CredentialRepository {
+	return &memoryCredentialRepository{
+		credentials: make(map[int64]domain.Credential),
+	}
+}
+
+func (r *memoryCredentialRepository) Get(ctx context.Context, userID int64) (*domain.Credential, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cred, exists := r.credentials[userID]
+	if !exists {
+		return nil, domain.ErrCredentialNotFound
+	}
+
+	return &cred, nil
+}
+
+func (r *memoryCredentialRepository) Set(ctx context.Context, cred *domain.Credential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.journal != nil {
+		if err := r.journal.putCredential(cred); err != nil {
+			return err
+		}
+	}
+	r.credentials[cred.UserID] = *cred
+	return nil
+}
+
+func (r *memoryCredentialRepository) Delete(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.journal != nil {
+		if err := r.journal.deleteCredential(userID); err != nil {
+			return err
+		}
+	}
+	delete(r.credentials, userID)
+	return nil
+}
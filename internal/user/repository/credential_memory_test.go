@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestMemoryCredentialRepository(t *testing.T) {
+	r := NewMemoryCredentialRepository()
+	ctx := context.Background()
+
+	if _, err := r.Get(ctx, 1); !errors.Is(err, domain.ErrCredentialNotFound) {
+		t.Fatalf("Get() error = %v, want %v", err, domain.ErrCredentialNotFound)
+	}
+
+	cred := &domain.Credential{UserID: 1, PasswordHash: "hash", UpdatedAt: time.Unix(0, 0).UTC()}
+	if err := r.Set(ctx, cred); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	cred.PasswordHash = "mutated"
+
+	got, err := r.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.PasswordHash != "hash" {
+		t.Errorf("Get() hash = %q, want the value stored by Set", got.PasswordHash)
+	}
+
+	if err := r.Set(ctx, &domain.Credential{UserID: 1, PasswordHash: "rehashed"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := r.Get(ctx, 1); got.PasswordHash != "rehashed" {
+		t.Errorf("Get() hash = %q, want rehashed", got.PasswordHash)
+	}
+
+	if err := r.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.Get(ctx, 1); !errors.Is(err, domain.ErrCredentialNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, domain.ErrCredentialNotFound)
+	}
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// FsyncPolicy says when changes in the write-ahead log are synced to disk.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways syncs every change before it is acknowledged.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval syncs in the background, so a crash of the machine
+	// loses up to an interval of changes. A crash of the process alone
+	// loses nothing.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leaves syncing to the operating system.
+	FsyncNever FsyncPolicy = "never"
+)
+
+const (
+	snapshotFile = "users.snapshot"
+	walFile      = "users.wal"
+)
+
+type DurableOption func(*DurableUserRepository)
+
+// WithFsync sets the fsync policy and, for FsyncInterval, how often to sync.
+func WithFsync(policy FsyncPolicy, interval time.Duration) DurableOption {
+	return func(r *DurableUserRepository) {
+		r.fsync = policy
+		r.fsyncInterval = interval
+	}
+}
+
+// WithSnapshotInterval sets how often a snapshot compacts the write-ahead
+// log, if it holds any changes.
+func WithSnapshotInterval(interval time.Duration) DurableOption {
+	return func(r *DurableUserRepository) {
+		r.snapshotInterval = interval
+	}
+}
+
+// DurableUserRepository is the memory repository made to survive restarts,
+// together with the credentials and MFA enrollments users sign in with.
+// Each change is appended to a write-ahead log before it is applied, and
+// snapshots of all users periodically replace the log. On open, the last
+// snapshot and the log after it are replayed.
+type DurableUserRepository struct {
+	*memoryUserRepository
+	credentials      *memoryCredentialRepository
+	mfa              *memoryMFARepository
+	dir              string
+	fsync            FsyncPolicy
+	fsyncInterval    time.Duration
+	snapshotInterval time.Duration
+	log              *wal
+}
+
+// OpenDurableUserRepository loads the users kept in dir, creating it if
+// needed. A log cut short by a crash is truncated to its last complete
+// record; any other damage fails with ErrCorrupt rather than silently
+// losing users.
+func OpenDurableUserRepository(dir string, opts ...DurableOption) (*DurableUserRepository, error) {
+	r := &DurableUserRepository{
+		memoryUserRepository: &memoryUserRepository{
+			users:   make(map[int64]*domain.User),
+			deleted: make(map[int64]*domain.User),
+		},
+		credentials:      &memoryCredentialRepository{credentials: make(map[int64]domain.Credential)},
+		mfa:              &memoryMFARepository{enrollments: make(map[int64]*domain.MFAEnrollment)},
+		dir:              dir,
+		fsync:            FsyncAlways,
+		fsyncInterval:    time.Second,
+		snapshotInterval: 5 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := r.replay(); err != nil {
+		return nil, err
+	}
+
+	log, err := openWAL(filepath.Join(dir, walFile), r.fsync == FsyncAlways)
+	if err != nil {
+		return nil, err
+	}
+	r.log = log
+	r.journal = log
+	r.credentials.journal = log
+	r.mfa.journal = log
+	return r, nil
+}
+
+// Credentials returns the credential repository kept alongside the users.
+func (r *DurableUserRepository) Credentials() domain.CredentialRepository {
+	return r.credentials
+}
+
+// MFA returns the MFA repository kept alongside the users.
+func (r *DurableUserRepository) MFA() domain.MFARepository {
+	return r.mfa
+}
+
+func (r *DurableUserRepository) replay() error {
+	path := filepath.Join(r.dir, snapshotFile)
+	snapshot, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		// Snapshots are renamed into place once complete, so unlike the log
+		// they cannot be torn.
+		count := -1
+		_, err := decodeRecords(snapshot, func(rec walRecord) error {
+			if rec.Op == opEnd {
+				count = rec.Count
+				return nil
+			}
+			if count >= 0 {
+				return fmt.Errorf("%w: records after the end", ErrCorrupt)
+			}
+			return r.apply(rec)
+		})
+		if errors.Is(err, errTornTail) {
+			err = fmt.Errorf("%w: truncated", ErrCorrupt)
+		}
+		if err == nil && count != r.count() {
+			err = fmt.Errorf("%w: missing records", ErrCorrupt)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	path = filepath.Join(r.dir, walFile)
+	log, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	valid, err := decodeRecords(log, func(rec walRecord) error {
+		if rec.Op == opEnd {
+			return fmt.Errorf("%w: end record in log", ErrCorrupt)
+		}
+		return r.apply(rec)
+	})
+	if errors.Is(err, errTornTail) {
+		slog.Warn("truncating torn write-ahead log", "path", path, "offset", valid, "dropped_bytes", len(log)-valid)
+		return os.Truncate(path, int64(valid))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// apply replays rec. Records hold the state they leave users in, so replaying
+// one that a snapshot already holds changes nothing.
+func (r *DurableUserRepository) apply(rec walRecord) error {
+	switch rec.Op {
+	case opPut:
+		if rec.User == nil {
+			return fmt.Errorf("%w: put without user", ErrCorrupt)
+		}
+		delete(r.users, rec.User.ID)
+		delete(r.deleted, rec.User.ID)
+		if rec.User.DeletedAt != nil {
+			r.deleted[rec.User.ID] = rec.User
+		} else {
+			r.users[rec.User.ID] = rec.User
+		}
+	case opRemove:
+		for _, id := range rec.IDs {
+			delete(r.deleted, id)
+		}
+	case opPutCredential:
+		if rec.Credential == nil {
+			return fmt.Errorf("%w: put without credential", ErrCorrupt)
+		}
+		r.credentials.credentials[rec.Credential.UserID] = *rec.Credential
+	case opDeleteCredential:
+		delete(r.credentials.credentials, rec.UserID)
+	case opPutMFA:
+		if rec.MFA == nil {
+			return fmt.Errorf("%w: put without enrollment", ErrCorrupt)
+		}
+		r.mfa.enrollments[rec.MFA.UserID] = rec.MFA
+	case opDeleteMFA:
+		delete(r.mfa.enrollments, rec.UserID)
+	default:
+		return fmt.Errorf("%w: unknown record %q", ErrCorrupt, rec.Op)
+	}
+	return nil
+}
+
+// count returns the number of records a snapshot holds.
+func (r *DurableUserRepository) count() int {
+	return len(r.users) + len(r.deleted) + len(r.credentials.credentials) + len(r.mfa.enrollments)
+}
+
+// Snapshot writes every user, credential and MFA enrollment to a new
+// snapshot and empties the log. Changes wait for it, as do reads of MFA
+// enrollments; other reads do not.
+func (r *DurableUserRepository) Snapshot() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.credentials.mu.RLock()
+	defer r.credentials.mu.RUnlock()
+	r.mfa.mu.Lock()
+	defer r.mfa.mu.Unlock()
+
+	path := filepath.Join(r.dir, snapshotFile)
+	tmp, err := os.CreateTemp(r.dir, snapshotFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = r.writeSnapshot(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	if err := syncDir(r.dir); err != nil {
+		return err
+	}
+	// Should the process stop before the log is emptied, replaying it over
+	// the snapshot yields the same users.
+	return r.log.reset()
+}
+
+func (r *DurableUserRepository) writeSnapshot(f *os.File) error {
+	write := func(rec walRecord) error {
+		frame, err := encodeFrame(rec)
+		if err == nil {
+			_, err = f.Write(frame)
+		}
+		return err
+	}
+	for _, source := range []map[int64]*domain.User{r.users, r.deleted} {
+		for _, user := range source {
+			if err := write(walRecord{Op: opPut, User: user}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, cred := range r.credentials.credentials {
+		if err := write(walRecord{Op: opPutCredential, Credential: &cred}); err != nil {
+			return err
+		}
+	}
+	for _, enrollment := range r.mfa.enrollments {
+		if err := write(walRecord{Op: opPutMFA, MFA: enrollment}); err != nil {
+			return err
+		}
+	}
+	if err := write(walRecord{Op: opEnd, Count: r.count()}); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Run syncs the log according to the fsync policy and takes snapshots until
+// ctx is done.
+func (r *DurableUserRepository) Run(ctx context.Context) {
+	snapshots := time.NewTicker(r.snapshotInterval)
+	defer snapshots.Stop()
+	var syncs <-chan time.Time
+	if r.fsync == FsyncInterval {
+		ticker := time.NewTicker(r.fsyncInterval)
+		defer ticker.Stop()
+		syncs = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-syncs:
+			if err := r.log.sync(); err != nil {
+				slog.ErrorContext(ctx, "sync write-ahead log failed", "error", err)
+			}
+		case <-snapshots.C:
+			if r.log.len() == 0 {
+				continue
+			}
+			if err := r.Snapshot(); err != nil {
+				slog.ErrorContext(ctx, "snapshot users failed", "error", err)
+			}
+		}
+	}
+}
+
+// CheckHealth fails once the log refuses changes.
+func (r *DurableUserRepository) CheckHealth(ctx context.Context) error {
+	return r.log.health()
+}
+
+// Close syncs and closes the log. Changes fail afterwards.
+func (r *DurableUserRepository) Close() error {
+	return r.log.close()
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestRepository(t *testing.T, dir string, opts ...DurableOption) *DurableUserRepository {
+	t.Helper()
+	r, err := OpenDurableUserRepository(dir, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// populate makes changes of every kind and returns the users they leave.
+func populate(t *testing.T, r domain.UserRepository) []*domain.User {
+	t.Helper()
+	ctx := context.Background()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for id := int64(1); id <= 5; id++ {
+		assert.NoError(t, r.Create(ctx, newTestUser(id)))
+	}
+	assert.NoError(t, r.Update(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com", Role: domain.RoleAdmin}))
+	assert.NoError(t, r.VerifyEmail(ctx, 1, "john@example.com", at))
+	assert.NoError(t, r.Delete(ctx, 2, at.Add(time.Hour)))
+	assert.NoError(t, r.Delete(ctx, 3, at.Add(time.Hour)))
+	_, err := r.Restore(ctx, 3)
+	assert.NoError(t, err)
+	_, err = r.Anonymize(ctx, 4, at)
+	assert.NoError(t, err)
+	n, err := r.Purge(ctx, at.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "the anonymized user")
+
+	page, err := r.List(ctx, domain.ListOptions{IncludeDeleted: true})
+	assert.NoError(t, err)
+	return page.Users
+}
+
+func listAll(t *testing.T, r domain.UserRepository) []*domain.User {
+	t.Helper()
+	page, err := r.List(context.Background(), domain.ListOptions{IncludeDeleted: true})
+	assert.NoError(t, err)
+	return page.Users
+}
+
+func TestDurableUserRepository_Replay(t *testing.T) {
+	dir := t.TempDir()
+	r := openTestRepository(t, dir)
+	want := populate(t, r)
+	assert.Len(t, want, 4)
+	assert.NoError(t, r.Close())
+	assert.Error(t, r.Create(context.Background(), newTestUser(9)), "closed")
+
+	r = openTestRepository(t, dir)
+	assert.Equal(t, want, listAll(t, r), "replayed from the log")
+
+	assert.NoError(t, r.Snapshot())
+	info, err := os.Stat(filepath.Join(dir, walFile))
+	assert.NoError(t, err)
+	assert.Zero(t, info.Size(), "the snapshot replaces the log")
+	assert.NoError(t, r.Create(context.Background(), newTestUser(9)))
+	want = listAll(t, r)
+	assert.NoError(t, r.Close())
+
+	r = openTestRepository(t, dir)
+	assert.Equal(t, want, listAll(t, r), "replayed from snapshot and log")
+	user, err := r.GetByUsername(context.Background(), "john")
+	assert.NoError(t, err)
+	assert.True(t, user.EmailVerified())
+}
+
+func TestDurableUserRepository_Credentials(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := openTestRepository(t, dir)
+	assert.NoError(t, r.Credentials().Set(ctx, &domain.Credential{UserID: 1, PasswordHash: "hash-1", UpdatedAt: at}))
+	assert.NoError(t, r.Credentials().Set(ctx, &domain.Credential{UserID: 2, PasswordHash: "hash-2", UpdatedAt: at}))
+	assert.NoError(t, r.Credentials().Delete(ctx, 2))
+	assert.NoError(t, r.MFA().Set(ctx, &domain.MFAEnrollment{UserID: 1, Secret: []byte("secret"), ConfirmedAt: &at, RecoveryCodes: []string{"a", "b"}}))
+	assert.NoError(t, r.MFA().UseStep(ctx, 1, 7))
+	assert.NoError(t, r.MFA().UseRecoveryCode(ctx, 1, "a"))
+	assert.NoError(t, r.MFA().Set(ctx, &domain.MFAEnrollment{UserID: 2, Secret: []byte("other")}))
+	assert.NoError(t, r.MFA().Delete(ctx, 2))
+	assert.NoError(t, r.Close())
+	assert.Error(t, r.Credentials().Set(ctx, &domain.Credential{UserID: 3}), "closed")
+	assert.Error(t, r.MFA().UseStep(ctx, 1, 8), "closed")
+
+	check := func(r *DurableUserRepository, msg string) {
+		t.Helper()
+		cred, err := r.Credentials().Get(ctx, 1)
+		assert.NoError(t, err, msg)
+		assert.Equal(t, &domain.Credential{UserID: 1, PasswordHash: "hash-1", UpdatedAt: at}, cred, msg)
+		_, err = r.Credentials().Get(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrCredentialNotFound, msg)
+		enrollment, err := r.MFA().Get(ctx, 1)
+		assert.NoError(t, err, msg)
+		assert.Equal(t, &domain.MFAEnrollment{UserID: 1, Secret: []byte("secret"), ConfirmedAt: &at, LastStep: 7, RecoveryCodes: []string{"b"}}, enrollment, msg)
+		assert.ErrorIs(t, r.MFA().UseStep(ctx, 1, 7), domain.ErrInvalidMFACode, msg)
+		_, err = r.MFA().Get(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrMFANotEnrolled, msg)
+	}
+	r = openTestRepository(t, dir)
+	check(r, "replayed from the log")
+	assert.NoError(t, r.Snapshot())
+	assert.NoError(t, r.Close())
+
+	r = openTestRepository(t, dir)
+	check(r, "replayed from the snapshot")
+}
+
+func TestDurableUserRepository_SnapshotThenLog(t *testing.T) {
+	dir := t.TempDir()
+	r := openTestRepository(t, dir)
+	want := populate(t, r)
+	log, err := os.ReadFile(filepath.Join(dir, walFile))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Snapshot())
+	assert.NoError(t, r.Close())
+
+	// As if the process stopped between writing the snapshot and emptying
+	// the log.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, walFile), log, 0o600))
+	r = openTestRepository(t, dir)
+	assert.Equal(t, want, listAll(t, r))
+}
+
+func TestDurableUserRepository_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	r := openTestRepository(t, dir, WithFsync(FsyncNever, 0))
+	want := populate(t, r)
+	assert.NoError(t, r.Create(context.Background(), newTestUser(9)))
+	assert.NoError(t, r.Close())
+
+	path := filepath.Join(dir, walFile)
+	log, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, log[:len(log)-10], 0o600))
+
+	r = openTestRepository(t, dir)
+	assert.Equal(t, want, listAll(t, r), "the torn create is dropped")
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(len(log)-10), "the torn record is truncated")
+
+	assert.NoError(t, r.Create(context.Background(), newTestUser(9)))
+	assert.NoError(t, r.Close())
+	r = openTestRepository(t, dir)
+	_, err = r.GetByID(context.Background(), 9)
+	assert.NoError(t, err, "records after the truncation are readable")
+}
+
+func TestDurableUserRepository_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	r := openTestRepository(t, dir)
+	populate(t, r)
+	assert.NoError(t, r.Close())
+
+	path := filepath.Join(dir, walFile)
+	log, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, flip(log, 20), 0o600))
+	_, err = OpenDurableUserRepository(dir)
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	assert.NoError(t, os.WriteFile(path, log, 0o600))
+	r = openTestRepository(t, dir)
+	assert.NoError(t, r.Snapshot())
+	assert.NoError(t, r.Close())
+
+	path = filepath.Join(dir, snapshotFile)
+	snapshot, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, snapshot[:len(snapshot)-3], 0o600))
+	_, err = OpenDurableUserRepository(dir)
+	assert.ErrorIs(t, err, ErrCorrupt, "snapshots are never torn")
+
+	var dropped []byte
+	_, err = decodeRecords(snapshot, func(rec walRecord) error {
+		if rec.Op == opPut && rec.User.ID == 1 {
+			return nil
+		}
+		frame, err := encodeFrame(rec)
+		dropped = append(dropped, frame...)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, dropped, 0o600))
+	_, err = OpenDurableUserRepository(dir)
+	assert.ErrorIs(t, err, ErrCorrupt, "a user is missing")
+}
+
+func TestDurableUserRepository_Run(t *testing.T) {
+	dir := t.TempDir()
+	r := openTestRepository(t, dir, WithFsync(FsyncInterval, time.Millisecond), WithSnapshotInterval(5*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	assert.NoError(t, r.Create(context.Background(), newTestUser(1)))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, snapshotFile))
+		return err == nil && r.log.len() == 0
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+	assert.NoError(t, r.CheckHealth(context.Background()))
+}
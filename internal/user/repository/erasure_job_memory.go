@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"repo-guardian/internal/domain"
+)
+
+type memoryErasureJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]domain.ErasureJob
+}
+
+func NewMemoryErasureJobRepository() domain.ErasureJobRepository {
+	return &memoryErasureJobRepository{
+		jobs: make(map[string]domain.ErasureJob),
+	}
+}
+
+func (r *memoryErasureJobRepository) Create(ctx context.Context, job *domain.ErasureJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; exists {
+		return domain.ErrConflict
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryErasureJobRepository) Get(ctx context.Context, id string) (*domain.ErasureJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, domain.ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (r *memoryErasureJobRepository) Update(ctx context.Context, job *domain.ErasureJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; !exists {
+		return domain.ErrJobNotFound
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestMemoryErasureJobRepository(t *testing.T) {
+	r := NewMemoryErasureJobRepository()
+	ctx := context.Background()
+
+	if _, err := r.Get(ctx, "j1"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Fatalf("Get() error = %v, want %v", err, domain.ErrJobNotFound)
+	}
+	job := &domain.ErasureJob{ID: "j1", UserID: 1, Status: domain.ErasurePending}
+	if err := r.Create(ctx, job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := r.Create(ctx, job); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create() duplicate error = %v, want %v", err, domain.ErrConflict)
+	}
+	job.Status = domain.ErasureRunning
+	if got, _ := r.Get(ctx, "j1"); got.Status != domain.ErasurePending {
+		t.Errorf("Get() status = %q, want the value stored by Create", got.Status)
+	}
+
+	if err := r.Update(ctx, job); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := r.Get(ctx, "j1"); got.Status != domain.ErasureRunning {
+		t.Errorf("Get() status = %q, want %q", got.Status, domain.ErasureRunning)
+	}
+	if err := r.Update(ctx, &domain.ErasureJob{ID: "j2"}); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("Update() of an unknown job error = %v, want %v", err, domain.ErrJobNotFound)
+	}
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"cmp"
 	"context"
-	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"repo-guardian/internal/domain"
 )
@@ -11,11 +14,24 @@ import (
 type memoryUserRepository struct {
 	mu    sync.RWMutex
 	users map[int64]*domain.User
+	// deleted holds soft-deleted users until they are restored or purged.
+	// Their IDs stay reserved in the meantime.
+	deleted map[int64]*domain.User
+	// journal, if set, records every change before it is applied.
+	journal journal
+}
+
+// journal records the state each change leaves users in, or their removal.
+// A change it fails to record is not applied.
+type journal interface {
+	put(user *domain.User) error
+	remove(ids []int64) error
 }
 
 func NewMemoryUserRepository() domain.UserRepository {
 	return &memoryUserRepository{
-		users: make(map[int64]*domain.User),
+		users:   make(map[int64]*domain.User),
+		deleted: make(map[int64]*domain.User),
 	}
 }
 
@@ -24,10 +40,22 @@ func (r *memoryUserRepository) Create(ctx context.Context, user *domain.User) er
 	defer r.mu.Unlock()
 
 	if _, exists := r.users[user.ID]; exists {
-		return errors.New("user already exists")
+		return domain.ErrConflict
+	}
+	if _, exists := r.deleted[user.ID]; exists {
+		return domain.ErrConflict
+	}
+	if r.identityTaken(user) {
+		return domain.ErrConflict
 	}
 
-	r.users[user.ID] = user
+	user.Version = 1
+	user.DeletedAt = nil
+	stored := user.Clone()
+	if err := r.record(stored); err != nil {
+		return err
+	}
+	r.users[user.ID] = stored
 	return nil
 }
 
@@ -36,21 +64,265 @@ func (r *memoryUserRepository) GetByID(ctx context.Context, id int64) (*domain.U
 	defer r.mu.RUnlock()
 
 	user, exists := r.users[id]
+	if !exists && domain.IncludeDeleted(ctx) {
+		user, exists = r.deleted[id]
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+
+	return user.Clone(), nil
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return user.Clone(), nil
+		}
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.users[user.ID]
 	if !exists {
-		return nil, errors.New("user not found")
+		return domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(ctx, existing.Version); err != nil {
+		return err
+	}
+	if r.identityTaken(user) {
+		return domain.ErrConflict
 	}
 
-	return user, nil
+	if user.Role == "" {
+		user.Role = existing.Role
+	}
+	// Verification belongs to the address, so it survives only while the
+	// address stays the same.
+	user.EmailVerifiedAt = nil
+	if user.Email == existing.Email {
+		user.EmailVerifiedAt = existing.EmailVerifiedAt
+	}
+	user.CreatedAt = existing.CreatedAt
+	user.CreatedBy = existing.CreatedBy
+	user.Version = existing.Version + 1
+	user.DeletedAt = nil
+	stored := user.Clone()
+	if err := r.record(stored); err != nil {
+		return err
+	}
+	r.users[user.ID] = stored
+	return nil
 }
 
-func (r *memoryUserRepository) Delete(ctx context.Context, id int64) error {
+func (r *memoryUserRepository) Delete(ctx context.Context, id int64, at time.Time) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.users[id]; !exists {
-		return errors.New("user not found")
+	existing, exists := r.users[id]
+	if !exists {
+		return domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(ctx, existing.Version); err != nil {
+		return err
 	}
 
+	deleted := existing.Clone()
+	deleted.DeletedAt = &at
+	deleted.Version++
+	if err := r.record(deleted); err != nil {
+		return err
+	}
+	if r.deleted == nil {
+		r.deleted = make(map[int64]*domain.User)
+	}
+	r.deleted[id] = deleted
 	delete(r.users, id)
 	return nil
 }
+
+func (r *memoryUserRepository) Restore(ctx context.Context, id int64) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.deleted[id]
+	if !exists {
+		if _, live := r.users[id]; live {
+			return nil, domain.ErrNotDeleted
+		}
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(ctx, existing.Version); err != nil {
+		return nil, err
+	}
+
+	restored := existing.Clone()
+	restored.DeletedAt = nil
+	restored.Version++
+	if err := r.record(restored); err != nil {
+		return nil, err
+	}
+	r.users[id] = restored
+	delete(r.deleted, id)
+	return restored.Clone(), nil
+}
+
+func (r *memoryUserRepository) VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.users[id]
+	if !exists {
+		return domain.ErrNotFound
+	}
+	if existing.Email != email {
+		return domain.ErrConflict
+	}
+
+	verified := existing.Clone()
+	verified.EmailVerifiedAt = &at
+	verified.Version++
+	if err := r.record(verified); err != nil {
+		return err
+	}
+	r.users[id] = verified
+	return nil
+}
+
+func (r *memoryUserRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged []int64
+	for id, user := range r.deleted {
+		if user.DeletedAt.Before(cutoff) {
+			purged = append(purged, id)
+		}
+	}
+	if len(purged) > 0 && r.journal != nil {
+		if err := r.journal.remove(purged); err != nil {
+			return 0, err
+		}
+	}
+	for _, id := range purged {
+		delete(r.deleted, id)
+	}
+	return len(purged), nil
+}
+
+func (r *memoryUserRepository) Anonymize(ctx context.Context, id int64, at time.Time) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.users[id]
+	if !exists {
+		if existing, exists = r.deleted[id]; !exists {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	anonymized := existing.Clone()
+	anonymized.Anonymize(at)
+	anonymized.Version++
+	if err := r.record(anonymized); err != nil {
+		return nil, err
+	}
+	r.deleted[id] = anonymized
+	delete(r.users, id)
+	return anonymized.Clone(), nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+
+	var pivot *domain.User
+	if opts.Cursor != "" {
+		cursor, err := domain.DecodeCursor(opts.Cursor, opts)
+		if err != nil {
+			return nil, err
+		}
+		if pivot, err = cursor.Pivot(); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	users := make([]*domain.User, 0, len(r.users))
+	collect := func(source map[int64]*domain.User) {
+		for _, user := range source {
+			if opts.Matches(user) && (pivot == nil || compareUsers(user, pivot, opts) > 0) {
+				users = append(users, user.Clone())
+			}
+		}
+	}
+	collect(r.users)
+	if opts.IncludeDeleted {
+		collect(r.deleted)
+	}
+	r.mu.RUnlock()
+
+	return paginate(users, opts), nil
+}
+
+// identityTaken reports whether another user, live or soft-deleted, already
+// has user's username or email. Callers must hold r.mu.
+func (r *memoryUserRepository) identityTaken(user *domain.User) bool {
+	for _, source := range []map[int64]*domain.User{r.users, r.deleted} {
+		for id, other := range source {
+			if id != user.ID && (other.Username == user.Username || other.Email == user.Email) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *memoryUserRepository) record(user *domain.User) error {
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.put(user)
+}
+
+// compareUsers orders users by the requested field, breaking ties by ID so
+// that every user has a unique position for cursors to point at.
+func compareUsers(a, b *domain.User, opts domain.ListOptions) int {
+	var c int
+	switch opts.SortBy {
+	case domain.SortByUsername:
+		c = strings.Compare(a.Username, b.Username)
+	case domain.SortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	if opts.Descending {
+		return -c
+	}
+	return c
+}
+
+// paginate sorts the users that remain after the cursor and cuts one page.
+func paginate(users []*domain.User, opts domain.ListOptions) *domain.UserPage {
+	slices.SortFunc(users, func(a, b *domain.User) int {
+		return compareUsers(a, b, opts)
+	})
+
+	page := &domain.UserPage{Users: users}
+	if len(users) > opts.Limit {
+		page.Users = users[:opts.Limit]
+		page.NextCursor = domain.CursorAfter(page.Users[opts.Limit-1], opts).Encode()
+	}
+	return page
+}
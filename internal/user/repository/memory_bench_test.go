@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+
+	"repo-guardian/internal/domain"
+)
+
+const benchUsers = 10000
+
+// BenchmarkUserRepository compares the single-lock and the sharded memory
+// repositories under parallel workloads mixing lookups by ID and username
+// with updates. Run it with -cpu to see how each scales, for example
+// go test -bench UserRepository -cpu 1,4,16 ./internal/user/repository.
+func BenchmarkUserRepository(b *testing.B) {
+	repos := []struct {
+		name string
+		new  func() domain.UserRepository
+	}{
+		{"memory", NewMemoryUserRepository},
+		{"sharded", func() domain.UserRepository { return NewShardedMemoryUserRepository(DefaultShards) }},
+	}
+	for _, writes := range []int{10, 50, 90} {
+		for _, repo := range repos {
+			b.Run(fmt.Sprintf("writes=%d%%/%s", writes, repo.name), func(b *testing.B) {
+				benchmarkMixed(b, repo.new(), writes)
+			})
+		}
+	}
+}
+
+func benchmarkMixed(b *testing.B, r domain.UserRepository, writePercent int) {
+	ctx := context.Background()
+	for id := int64(1); id <= benchUsers; id++ {
+		if err := r.Create(ctx, newTestUser(id)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var seed atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rng := rand.New(rand.NewPCG(seed.Add(1), 0))
+		for pb.Next() {
+			id := rng.Int64N(benchUsers) + 1
+			switch op := rng.IntN(100); {
+			case op < writePercent:
+				// A fresh user, since Update fills in the fields it keeps.
+				if err := r.Update(ctx, newTestUser(id)); err != nil {
+					b.Error(err)
+				}
+			case op%4 == 0:
+				if _, err := r.GetByUsername(ctx, fmt.Sprintf("user%d", id)); err != nil {
+					b.Error(err)
+				}
+			default:
+				if _, err := r.GetByID(ctx, id); err != nil {
+					b.Error(err)
+				}
+			}
+		}
+	})
+}
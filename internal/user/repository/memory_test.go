@@ -3,8 +3,11 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"repo-guardian/internal/domain"
 )
@@ -32,9 +35,9 @@ func TestMemoryUserRepository_Create(t *testing.T) {
 			args: args{
 				ctx: context.Background(),
 				user: &domain.User{
-					ID:    1,
-					Name:  "John Doe",
-					Email: "john.doe@example.com",
+					ID:       1,
+					Username: "John Doe",
+					Email:    "john.doe@example.com",
 				},
 			},
 			wantErr: false,
@@ -44,18 +47,39 @@ func TestMemoryUserRepository_Create(t *testing.T) {
 			fields: fields{
 				users: map[int64]*domain.User{
 					1: {
-						ID:    1,
-						Name:  "John Doe",
-						Email: "john.doe@example.com",
+						ID:       1,
+						Username: "John Doe",
+						Email:    "john.doe@example.com",
 					},
 				},
 			},
 			args: args{
 				ctx: context.Background(),
 				user: &domain.User{
-					ID:    1,
-					Name:  "John Doe",
-					Email: "john.doe@example.com",
+					ID:       1,
+					Username: "John Doe",
+					Email:    "john.doe@example.com",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fail to create a user - email already used",
+			fields: fields{
+				users: map[int64]*domain.User{
+					1: {
+						ID:       1,
+						Username: "John Doe",
+						Email:    "john.doe@example.com",
+					},
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				user: &domain.User{
+					ID:       2,
+					Username: "Johnny",
+					Email:    "john.doe@example.com",
 				},
 			},
 			wantErr: true,
@@ -68,9 +92,9 @@ func TestMemoryUserRepository_Create(t *testing.T) {
 			args: args{
 				ctx: context.Background(),
 				user: &domain.User{
-					ID:    1,
-					Name:  "John Doe",
-					Email: "john.doe@example.com",
+					ID:       1,
+					Username: "John Doe",
+					Email:    "john.doe@example.com",
 				},
 			},
 			wantErr: false,
@@ -117,9 +141,9 @@ func TestMemoryUserRepository_GetByID(t *testing.T) {
 			fields: fields{
 				users: map[int64]*domain.User{
 					1: {
-						ID:    1,
-						Name:  "John Doe",
-						Email: "john.doe@example.com",
+						ID:       1,
+						Username: "John Doe",
+						Email:    "john.doe@example.com",
 					},
 				},
 			},
@@ -128,9 +152,9 @@ func TestMemoryUserRepository_GetByID(t *testing.T) {
 				id:  1,
 			},
 			want: &domain.User{
-				ID:    1,
-				Name:  "John Doe",
-				Email: "john.doe@example.com",
+				ID:       1,
+				Username: "John Doe",
+				Email:    "john.doe@example.com",
 			},
 			wantErr: false,
 		},
@@ -151,9 +175,9 @@ func TestMemoryUserRepository_GetByID(t *testing.T) {
 			fields: fields{
 				users: map[int64]*domain.User{
 					2: {
-						ID:    2,
-						Name:  "John Doe",
-						Email: "john.doe@example.com",
+						ID:       2,
+						Username: "John Doe",
+						Email:    "john.doe@example.com",
 					},
 				},
 			},
@@ -182,6 +206,49 @@ func TestMemoryUserRepository_GetByID(t *testing.T) {
 	}
 }
 
+func TestMemoryUserRepository_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   map[int64]*domain.User
+		user    *domain.User
+		wantErr error
+	}{
+		{
+			name: "Successfully update a user",
+			users: map[int64]*domain.User{
+				1: {ID: 1, Username: "john", Email: "john@example.com"},
+			},
+			user: &domain.User{ID: 1, Username: "johnny", Email: "johnny@example.com"},
+		},
+		{
+			name:    "Fail to update a user - user not found",
+			users:   map[int64]*domain.User{},
+			user:    &domain.User{ID: 1, Username: "johnny", Email: "johnny@example.com"},
+			wantErr: domain.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &memoryUserRepository{
+				users: tt.users,
+			}
+			err := r.Update(context.Background(), tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, exists := r.users[tt.user.ID]; exists {
+					t.Errorf("Update() created a missing user")
+				}
+				return
+			}
+			if !reflect.DeepEqual(r.users[tt.user.ID], tt.user) {
+				t.Errorf("Update() stored = %v, want %v", r.users[tt.user.ID], tt.user)
+			}
+		})
+	}
+}
+
 func TestMemoryUserRepository_Delete(t *testing.T) {
 	type fields struct {
 		users map[int64]*domain.User
@@ -201,9 +268,9 @@ func TestMemoryUserRepository_Delete(t *testing.T) {
 			fields: fields{
 				users: map[int64]*domain.User{
 					1: {
-						ID:    1,
-						Name:  "John Doe",
-						Email: "john.doe@example.com",
+						ID:       1,
+						Username: "John Doe",
+						Email:    "john.doe@example.com",
 					},
 				},
 			},
@@ -229,9 +296,9 @@ func TestMemoryUserRepository_Delete(t *testing.T) {
 			fields: fields{
 				users: map[int64]*domain.User{
 					2: {
-						ID:    2,
-						Name:  "John Doe",
-						Email: "john.doe@example.com",
+						ID:       2,
+						Username: "John Doe",
+						Email:    "john.doe@example.com",
 					},
 				},
 			},
@@ -247,7 +314,7 @@ func TestMemoryUserRepository_Delete(t *testing.T) {
 			r := &memoryUserRepository{
 				users: tt.fields.users,
 			}
-			err := r.Delete(tt.args.ctx, tt.args.id)
+			err := r.Delete(tt.args.ctx, tt.args.id, time.Now())
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Delete() error = %v, wantErr %v", err, tt.wantErr)
@@ -259,9 +326,392 @@ func TestMemoryUserRepository_Delete(t *testing.T) {
 				}
 			}
 
-			if tt.wantErr && !errors.Is(err, errors.New("user not found")) {
-				t.Errorf("Delete() error = %v, wantErr %v", err, errors.New("user not found"))
+			if tt.wantErr && !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Delete() error = %v, wantErr %v", err, domain.ErrNotFound)
 			}
 		})
 	}
 }
+
+func TestMemoryUserRepository_Update_PreservesCreation(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &memoryUserRepository{
+		users: map[int64]*domain.User{
+			1: {ID: 1, Username: "john", Email: "john@example.com", Role: domain.RoleAdmin, CreatedAt: created, CreatedBy: "user:1"},
+		},
+	}
+
+	user := &domain.User{ID: 1, Username: "johnny", Email: "john@example.com", CreatedBy: "user:2"}
+	if err := r.Update(context.Background(), user); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if r.users[1].Role != domain.RoleAdmin {
+		t.Errorf("Update() without a role changed the role to %q", r.users[1].Role)
+	}
+	if !r.users[1].CreatedAt.Equal(created) || r.users[1].CreatedBy != "user:1" {
+		t.Errorf("Update() created %v by %q, want %v by user:1", r.users[1].CreatedAt, r.users[1].CreatedBy, created)
+	}
+}
+
+func TestMemoryUserRepository_Versioning(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryUserRepository().(*memoryUserRepository)
+
+	user := &domain.User{ID: 1, Username: "john", Email: "john@example.com"}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if user.Version != 1 {
+		t.Fatalf("Create() version = %d, want 1", user.Version)
+	}
+
+	update := &domain.User{ID: 1, Username: "johnny", Email: "john@example.com"}
+	if err := r.Update(domain.WithExpectedVersion(ctx, 1), update); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if update.Version != 2 {
+		t.Fatalf("Update() version = %d, want 2", update.Version)
+	}
+
+	stale := &domain.User{ID: 1, Username: "stale", Email: "john@example.com"}
+	if err := r.Update(domain.WithExpectedVersion(ctx, 1), stale); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Update() with stale version error = %v, want %v", err, domain.ErrVersionConflict)
+	}
+	if err := r.Delete(domain.WithExpectedVersion(ctx, 1), 1, time.Now()); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Delete() with stale version error = %v, want %v", err, domain.ErrVersionConflict)
+	}
+	if got, _ := r.GetByID(ctx, 1); got.Username != "johnny" {
+		t.Errorf("stale write was applied: username = %q", got.Username)
+	}
+	if err := r.Delete(domain.WithExpectedVersion(ctx, 2), 1, time.Now()); err != nil {
+		t.Errorf("Delete() with current version error = %v", err)
+	}
+}
+
+func TestMemoryUserRepository_ConcurrentConditionalUpdates(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryUserRepository()
+	if err := r.Create(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	results := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := &domain.User{ID: 1, Username: fmt.Sprintf("writer-%d", i), Email: "john@example.com"}
+			results <- r.Update(domain.WithExpectedVersion(ctx, 1), user)
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	var succeeded int
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, domain.ErrVersionConflict):
+			t.Errorf("Update() unexpected error = %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d conditional updates succeeded, want exactly 1", succeeded)
+	}
+}
+
+func TestMemoryUserRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := NewMemoryUserRepository()
+
+	if err := r.Create(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := r.Delete(ctx, 1, deletedAt); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := r.GetByID(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want %v", err, domain.ErrNotFound)
+	}
+	got, err := r.GetByID(domain.WithDeleted(ctx), 1)
+	if err != nil {
+		t.Fatalf("GetByID() including deleted error = %v", err)
+	}
+	if got.DeletedAt == nil || !got.DeletedAt.Equal(deletedAt) || got.Version != 2 {
+		t.Errorf("GetByID() including deleted = %+v, want deleted_at %v and version 2", got, deletedAt)
+	}
+
+	if err := r.Delete(ctx, 1, deletedAt); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Delete() twice error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if err := r.Update(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Update() of deleted user error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if err := r.Create(ctx, &domain.User{ID: 1, Username: "other", Email: "other@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create() reusing deleted ID error = %v, want %v", err, domain.ErrConflict)
+	}
+
+	page, err := r.List(ctx, domain.ListOptions{})
+	if err != nil || len(page.Users) != 0 {
+		t.Errorf("List() = %v, %v, want no users", page, err)
+	}
+	page, err = r.List(ctx, domain.ListOptions{IncludeDeleted: true})
+	if err != nil || len(page.Users) != 1 {
+		t.Errorf("List() including deleted = %v, %v, want one user", page, err)
+	}
+
+	if _, err := r.Restore(domain.WithExpectedVersion(ctx, 1), 1); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Restore() with stale version error = %v, want %v", err, domain.ErrVersionConflict)
+	}
+	restored, err := r.Restore(ctx, 1)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Version != 3 {
+		t.Errorf("Restore() = %+v, want live user at version 3", restored)
+	}
+	if _, err := r.GetByID(ctx, 1); err != nil {
+		t.Errorf("GetByID() after restore error = %v", err)
+	}
+	if _, err := r.Restore(ctx, 1); !errors.Is(err, domain.ErrNotDeleted) {
+		t.Errorf("Restore() of live user error = %v, want %v", err, domain.ErrNotDeleted)
+	}
+	if _, err := r.Restore(ctx, 2); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Restore() of unknown user error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestMemoryUserRepository_Purge(t *testing.T) {
+	ctx := context.Background()
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := old.Add(48 * time.Hour)
+	r := &memoryUserRepository{
+		users: map[int64]*domain.User{
+			1: {ID: 1, Username: "live", Email: "live@example.com"},
+		},
+		deleted: map[int64]*domain.User{
+			2: {ID: 2, Username: "old", Email: "old@example.com", DeletedAt: &old},
+			3: {ID: 3, Username: "recent", Email: "recent@example.com", DeletedAt: &recent},
+		},
+	}
+
+	n, err := r.Purge(ctx, old.Add(24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("Purge() = %d, %v, want 1, nil", n, err)
+	}
+	if _, exists := r.deleted[2]; exists {
+		t.Errorf("Purge() kept user deleted before the cutoff")
+	}
+	if _, exists := r.deleted[3]; !exists {
+		t.Errorf("Purge() removed user deleted after the cutoff")
+	}
+	if _, exists := r.users[1]; !exists {
+		t.Errorf("Purge() removed a live user")
+	}
+	if err := r.Create(ctx, &domain.User{ID: 2, Username: "new", Email: "new@example.com"}); err != nil {
+		t.Errorf("Create() reusing purged ID error = %v", err)
+	}
+}
+
+func TestMemoryUserRepository_Anonymize(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	earlier := at.Add(-time.Hour)
+	r := &memoryUserRepository{
+		users: map[int64]*domain.User{
+			1: {ID: 1, Username: "live", Email: "live@example.com", EmailVerifiedAt: &earlier, CreatedBy: "user:9", Version: 3},
+		},
+		deleted: map[int64]*domain.User{
+			2: {ID: 2, Username: "gone", Email: "gone@example.com", DeletedAt: &earlier, Version: 2},
+		},
+	}
+
+	got, err := r.Anonymize(ctx, 1, at)
+	if err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+	if got.Username != "erased-1" || got.Email != "erased-1@erased.invalid" || got.EmailVerifiedAt != nil ||
+		got.DeletedAt == nil || !got.DeletedAt.Equal(at) || got.CreatedBy != "user:9" || got.Version != 4 {
+		t.Errorf("Anonymize() = %+v", got)
+	}
+	if _, err := r.GetByID(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetByID() of an erased user error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if stored, _ := r.GetByID(domain.WithDeleted(ctx), 1); stored.Username != "erased-1" {
+		t.Errorf("GetByID() with deleted = %+v, want the erased user", stored)
+	}
+
+	got, err = r.Anonymize(ctx, 2, at)
+	if err != nil || got.Username != "erased-2" || !got.DeletedAt.Equal(earlier) {
+		t.Errorf("Anonymize() of a deleted user = %+v, %v, want it to stay deleted since %v", got, err, earlier)
+	}
+	if _, err := r.Anonymize(ctx, 3, at); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Anonymize() of an unknown user error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestMemoryUserRepository_List(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &memoryUserRepository{
+		users: map[int64]*domain.User{
+			1: {ID: 1, Username: "carol", Email: "carol@example.com", CreatedAt: base.Add(3 * time.Hour)},
+			2: {ID: 2, Username: "alice", Email: "alice@example.org", CreatedAt: base.Add(1 * time.Hour)},
+			3: {ID: 3, Username: "bob", Email: "bob@EXAMPLE.com", CreatedAt: base.Add(2 * time.Hour)},
+			4: {ID: 4, Username: "alan", Email: "alan@example.com", CreatedAt: base.Add(1 * time.Hour)},
+		},
+	}
+
+	ids := func(users []*domain.User) []int64 {
+		out := make([]int64, 0, len(users))
+		for _, u := range users {
+			out = append(out, u.ID)
+		}
+		return out
+	}
+
+	// walk collects every page for opts, following next cursors.
+	walk := func(t *testing.T, opts domain.ListOptions) [][]int64 {
+		var pages [][]int64
+		for {
+			page, err := r.List(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			pages = append(pages, ids(page.Users))
+			if page.NextCursor == "" {
+				return pages
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts domain.ListOptions
+		want [][]int64
+	}{
+		{
+			name: "default order by id",
+			opts: domain.ListOptions{},
+			want: [][]int64{{1, 2, 3, 4}},
+		},
+		{
+			name: "paginate by id",
+			opts: domain.ListOptions{Limit: 3},
+			want: [][]int64{{1, 2, 3}, {4}},
+		},
+		{
+			name: "username descending",
+			opts: domain.ListOptions{Limit: 2, SortBy: domain.SortByUsername, Descending: true},
+			want: [][]int64{{1, 3}, {2, 4}},
+		},
+		{
+			name: "created_at with ties broken by id",
+			opts: domain.ListOptions{Limit: 1, SortBy: domain.SortByCreatedAt},
+			want: [][]int64{{2}, {4}, {3}, {1}},
+		},
+		{
+			name: "username prefix",
+			opts: domain.ListOptions{UsernamePrefix: "al", SortBy: domain.SortByUsername},
+			want: [][]int64{{4, 2}},
+		},
+		{
+			name: "email domain is case-insensitive",
+			opts: domain.ListOptions{EmailDomain: "@example.com"},
+			want: [][]int64{{1, 3, 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := walk(t, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() pages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("cursor from another sort order is rejected", func(t *testing.T) {
+		page, err := r.List(context.Background(), domain.ListOptions{Limit: 1})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		_, err = r.List(context.Background(), domain.ListOptions{Limit: 1, SortBy: domain.SortByUsername, Cursor: page.NextCursor})
+		if !errors.Is(err, domain.ErrBadParamInput) {
+			t.Errorf("List() error = %v, want %v", err, domain.ErrBadParamInput)
+		}
+	})
+}
+
+func TestMemoryUserRepository_GetByUsername(t *testing.T) {
+	r := NewMemoryUserRepository()
+	ctx := context.Background()
+	john := &domain.User{ID: 1, Username: "john", Email: "john@example.com"}
+	if err := r.Create(ctx, john); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := r.GetByUsername(ctx, "john")
+	if err != nil || got.ID != 1 {
+		t.Errorf("GetByUsername() = %v, %v, want user 1", got, err)
+	}
+	if _, err := r.GetByUsername(ctx, "jane"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetByUsername() error = %v, want %v", err, domain.ErrNotFound)
+	}
+
+	if err := r.Create(ctx, &domain.User{ID: 2, Username: "john", Email: "other@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create() with a taken username error = %v, want %v", err, domain.ErrConflict)
+	}
+	if err := r.Delete(ctx, 1, time.Now()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.GetByUsername(ctx, "john"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetByUsername() of a deleted user error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if err := r.Create(ctx, &domain.User{ID: 2, Username: "john", Email: "other@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create() with the username of a deleted user error = %v, want %v", err, domain.ErrConflict)
+	}
+}
+
+func TestMemoryUserRepository_VerifyEmail(t *testing.T) {
+	r := NewMemoryUserRepository()
+	ctx := context.Background()
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := r.Create(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.VerifyEmail(ctx, 1, "old@example.com", at); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("VerifyEmail() of another address error = %v, want %v", err, domain.ErrConflict)
+	}
+	if err := r.VerifyEmail(ctx, 2, "john@example.com", at); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("VerifyEmail() of a missing user error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if err := r.VerifyEmail(ctx, 1, "john@example.com", at); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	user, _ := r.GetByID(ctx, 1)
+	if !user.EmailVerified() || !user.EmailVerifiedAt.Equal(at) || user.Version != 2 {
+		t.Errorf("VerifyEmail() stored %+v, want verified at %v with version 2", user, at)
+	}
+
+	// Updates that keep the address keep the verification, even if the
+	// client tries to clear it.
+	if err := r.Update(ctx, &domain.User{ID: 1, Username: "johnny", Email: "john@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := r.GetByID(ctx, 1); !user.EmailVerified() {
+		t.Errorf("Update() with the same email cleared the verification")
+	}
+
+	// A new address is unverified, even if the client claims otherwise.
+	if err := r.Update(ctx, &domain.User{ID: 1, Username: "johnny", Email: "new@example.com", EmailVerifiedAt: &at}); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := r.GetByID(ctx, 1); user.EmailVerified() {
+		t.Errorf("Update() with a new email kept the verification")
+	}
+}
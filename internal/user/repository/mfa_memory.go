@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"repo-guardian/internal/domain"
+)
+
+type memoryMFARepository struct {
+	mu          sync.Mutex
+	enrollments map[int64]*domain.MFAEnrollment
+	// journal, if set, records every change before it is applied.
+	journal mfaJournal
+}
+
+// mfaJournal records the state each change leaves an enrollment in, or its
+// deletion. A change it fails to record is not applied.
+type mfaJournal interface {
+	putMFA(enrollment *domain.MFAEnrollment) error
+	deleteMFA(userID int64) error
+}
+
+func NewMemoryMFARepository() domain.MFARepository {
+	return &memoryMFARepository{
+		enrollments: make(map[int64]*domain.MFAEnrollment),
+	}
+}
+
+func (r *memoryMFARepository) Get(ctx context.Context, userID int64) (*domain.MFAEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	return cloneEnrollment(enrollment), nil
+}
+
+func (r *memoryMFARepository) Set(ctx context.Context, enrollment *domain.MFAEnrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := cloneEnrollment(enrollment)
+	if err := r.record(copied); err != nil {
+		return err
+	}
+	r.enrollments[enrollment.UserID] = copied
+	return nil
+}
+
+func (r *memoryMFARepository) Delete(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.journal != nil {
+		if err := r.journal.deleteMFA(userID); err != nil {
+			return err
+		}
+	}
+	delete(r.enrollments, userID)
+	return nil
+}
+
+func (r *memoryMFARepository) UseStep(ctx context.Context, userID int64, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return domain.ErrMFANotEnrolled
+	}
+	if step <= enrollment.LastStep {
+		return domain.ErrInvalidMFACode
+	}
+	updated := cloneEnrollment(enrollment)
+	updated.LastStep = step
+	if err := r.record(updated); err != nil {
+		return err
+	}
+	r.enrollments[userID] = updated
+	return nil
+}
+
+func (r *memoryMFARepository) UseRecoveryCode(ctx context.Context, userID int64, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return domain.ErrMFANotEnrolled
+	}
+	i := slices.Index(enrollment.RecoveryCodes, hash)
+	if i < 0 {
+		return domain.ErrInvalidMFACode
+	}
+	updated := cloneEnrollment(enrollment)
+	updated.RecoveryCodes = slices.Delete(updated.RecoveryCodes, i, i+1)
+	if err := r.record(updated); err != nil {
+		return err
+	}
+	r.enrollments[userID] = updated
+	return nil
+}
+
+func (r *memoryMFARepository) record(enrollment *domain.MFAEnrollment) error {
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.putMFA(enrollment)
+}
+
+func cloneEnrollment(e *domain.MFAEnrollment) *domain.MFAEnrollment {
+	copied := *e
+	copied.Secret = slices.Clone(e.Secret)
+	copied.RecoveryCodes = slices.Clone(e.RecoveryCodes)
+	return &copied
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestMemoryMFARepository(t *testing.T) {
+	r := NewMemoryMFARepository()
+	ctx := context.Background()
+
+	if _, err := r.Get(ctx, 1); !errors.Is(err, domain.ErrMFANotEnrolled) {
+		t.Errorf("Get() error = %v, want %v", err, domain.ErrMFANotEnrolled)
+	}
+
+	enrollment := &domain.MFAEnrollment{UserID: 1, Secret: []byte("secret"), LastStep: 10, RecoveryCodes: []string{"h1", "h2"}}
+	if err := r.Set(ctx, enrollment); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	enrollment.Secret[0] = 'X'
+	enrollment.RecoveryCodes[0] = "mutated"
+	got, err := r.Get(ctx, 1)
+	if err != nil || string(got.Secret) != "secret" || got.RecoveryCodes[0] != "h1" {
+		t.Errorf("Get() = %+v, %v, want the enrollment as set", got, err)
+	}
+
+	if err := r.UseStep(ctx, 1, 10); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("UseStep() of the last step error = %v, want %v", err, domain.ErrInvalidMFACode)
+	}
+	if err := r.UseStep(ctx, 1, 11); err != nil {
+		t.Errorf("UseStep() error = %v", err)
+	}
+	if err := r.UseStep(ctx, 1, 11); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("UseStep() twice error = %v, want %v", err, domain.ErrInvalidMFACode)
+	}
+
+	if err := r.UseRecoveryCode(ctx, 1, "h2"); err != nil {
+		t.Errorf("UseRecoveryCode() error = %v", err)
+	}
+	if err := r.UseRecoveryCode(ctx, 1, "h2"); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("UseRecoveryCode() twice error = %v, want %v", err, domain.ErrInvalidMFACode)
+	}
+	if got, _ := r.Get(ctx, 1); len(got.RecoveryCodes) != 1 || got.LastStep != 11 {
+		t.Errorf("Get() = %+v, want one recovery code left and last step 11", got)
+	}
+
+	if err := r.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := r.UseStep(ctx, 1, 12); !errors.Is(err, domain.ErrMFANotEnrolled) {
+		t.Errorf("UseStep() after Delete() error = %v, want %v", err, domain.ErrMFANotEnrolled)
+	}
+}
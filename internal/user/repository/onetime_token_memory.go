@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type memoryOneTimeTokenRepository struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+	now  func() time.Time
+}
+
+func NewMemoryOneTimeTokenRepository() domain.OneTimeTokenRepository {
+	return &memoryOneTimeTokenRepository{
+		used: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+func (r *memoryOneTimeTokenRepository) Consume(ctx context.Context, id string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for usedID, expiry := range r.used {
+		if !expiry.After(now) {
+			delete(r.used, usedID)
+		}
+	}
+	if _, used := r.used[id]; used {
+		return domain.ErrTokenReused
+	}
+	r.used[id] = expiresAt
+	return nil
+}
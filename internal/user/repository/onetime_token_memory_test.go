@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestMemoryOneTimeTokenRepository_Consume(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &memoryOneTimeTokenRepository{used: make(map[string]time.Time), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	if err := r.Consume(ctx, "a", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if err := r.Consume(ctx, "a", now.Add(time.Hour)); !errors.Is(err, domain.ErrTokenReused) {
+		t.Errorf("Consume() twice error = %v, want %v", err, domain.ErrTokenReused)
+	}
+	if err := r.Consume(ctx, "b", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := r.Consume(ctx, "c", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if _, kept := r.used["b"]; kept {
+		t.Errorf("Consume() kept the record of an expired token")
+	}
+	if _, kept := r.used["a"]; !kept {
+		t.Errorf("Consume() dropped the record of a token that has not expired")
+	}
+}
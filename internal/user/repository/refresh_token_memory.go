@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]domain.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() domain.RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{
+		tokens: make(map[string]domain.RefreshToken),
+	}
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.Hash]; exists {
+		return domain.ErrConflict
+	}
+	r.tokens[token.Hash] = *token
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) Get(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.tokens[hash]
+	if !exists {
+		return nil, domain.ErrInvalidToken
+	}
+	return &token, nil
+}
+
+func (r *memoryRefreshTokenRepository) Rotate(ctx context.Context, hash string, at time.Time, next *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.tokens[hash]
+	if !exists {
+		return domain.ErrInvalidToken
+	}
+	if token.RotatedAt != nil {
+		return domain.ErrTokenReused
+	}
+	if _, exists := r.tokens[next.Hash]; exists {
+		return domain.ErrConflict
+	}
+
+	token.RotatedAt = &at
+	r.tokens[hash] = token
+	r.tokens[next.Hash] = *next
+	r.pruneExpired(at)
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(ctx context.Context, family string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.Family == family {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tokens []*domain.RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RotatedAt == nil {
+			tokens = append(tokens, &token)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b *domain.RefreshToken) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return tokens, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeUser(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// pruneExpired drops tokens that can no longer be redeemed. Rotated tokens
+// are kept until then so that replaying them is still detected. Callers must
+// hold r.mu.
+func (r *memoryRefreshTokenRepository) pruneExpired(now time.Time) {
+	for hash, token := range r.tokens {
+		if !token.ExpiresAt.After(now) {
+			delete(r.tokens, hash)
+		}
+	}
+}
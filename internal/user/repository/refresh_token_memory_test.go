@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestMemoryRefreshTokenRepository(t *testing.T) {
+	r := NewMemoryRefreshTokenRepository()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expires := now.Add(time.Hour)
+
+	first := &domain.RefreshToken{Hash: "a", UserID: 1, Family: "f", ExpiresAt: expires}
+	if err := r.Create(ctx, first); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := r.Create(ctx, first); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create() duplicate error = %v, want %v", err, domain.ErrConflict)
+	}
+	if err := r.Create(ctx, &domain.RefreshToken{Hash: "other", UserID: 2, Family: "g", ExpiresAt: expires}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	second := &domain.RefreshToken{Hash: "b", UserID: 1, Family: "f", ExpiresAt: expires}
+	if err := r.Rotate(ctx, "a", now, second); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if got, _ := r.Get(ctx, "a"); got.RotatedAt == nil || !got.RotatedAt.Equal(now) {
+		t.Errorf("Rotate() did not mark the old token as rotated: %+v", got)
+	}
+	if err := r.Rotate(ctx, "a", now, &domain.RefreshToken{Hash: "c", Family: "f", ExpiresAt: expires}); !errors.Is(err, domain.ErrTokenReused) {
+		t.Errorf("Rotate() of a rotated token error = %v, want %v", err, domain.ErrTokenReused)
+	}
+	if _, err := r.Get(ctx, "c"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("Rotate() of a rotated token stored its successor")
+	}
+
+	if err := r.RevokeFamily(ctx, "f"); err != nil {
+		t.Fatalf("RevokeFamily() error = %v", err)
+	}
+	for _, hash := range []string{"a", "b"} {
+		if _, err := r.Get(ctx, hash); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("Get(%q) after RevokeFamily() error = %v, want %v", hash, err, domain.ErrInvalidToken)
+		}
+	}
+	if _, err := r.Get(ctx, "other"); err != nil {
+		t.Errorf("RevokeFamily() removed a token of another family: %v", err)
+	}
+}
+
+func TestMemoryRefreshTokenRepository_ByUser(t *testing.T) {
+	r := NewMemoryRefreshTokenRepository()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expires := now.Add(time.Hour)
+
+	r.Create(ctx, &domain.RefreshToken{Hash: "a", UserID: 1, Family: "f", CreatedAt: now, ExpiresAt: expires})
+	r.Create(ctx, &domain.RefreshToken{Hash: "c", UserID: 1, Family: "g", CreatedAt: now.Add(-time.Minute), ExpiresAt: expires})
+	r.Create(ctx, &domain.RefreshToken{Hash: "other", UserID: 2, Family: "h", CreatedAt: now, ExpiresAt: expires})
+	r.Rotate(ctx, "a", now, &domain.RefreshToken{Hash: "b", UserID: 1, Family: "f", CreatedAt: now, ExpiresAt: expires})
+
+	tokens, err := r.ListByUser(ctx, 1)
+	if err != nil || len(tokens) != 2 || tokens[0].Hash != "c" || tokens[1].Hash != "b" {
+		t.Fatalf("ListByUser() = %+v, %v, want the current tokens c and b", tokens, err)
+	}
+
+	if err := r.RevokeUser(ctx, 1); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+	for _, hash := range []string{"a", "b", "c"} {
+		if _, err := r.Get(ctx, hash); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("Get(%q) after RevokeUser() error = %v, want %v", hash, err, domain.ErrInvalidToken)
+		}
+	}
+	if tokens, _ := r.ListByUser(ctx, 2); len(tokens) != 1 {
+		t.Errorf("RevokeUser() removed another user's tokens")
+	}
+}
+
+func TestMemoryRefreshTokenRepository_PrunesExpired(t *testing.T) {
+	r := NewMemoryRefreshTokenRepository()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	_ = r.Create(ctx, &domain.RefreshToken{Hash: "stale", Family: "s", ExpiresAt: now.Add(-time.Second)})
+	_ = r.Create(ctx, &domain.RefreshToken{Hash: "a", Family: "f", ExpiresAt: now.Add(time.Hour)})
+	if err := r.Rotate(ctx, "a", now, &domain.RefreshToken{Hash: "b", Family: "f", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get(ctx, "stale"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("expired token was kept")
+	}
+	if _, err := r.Get(ctx, "a"); err != nil {
+		t.Errorf("rotated but unexpired token was pruned: %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+// Package repotest checks that implementations of domain.UserRepository keep
+// the contract the usecases rely on.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserRepository runs the contract tests against repositories made by
+// newRepo, which must return an empty repository on every call. Run it with
+// -race: some tests only fail through the race detector.
+func TestUserRepository(t *testing.T, newRepo func(t *testing.T) domain.UserRepository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r domain.UserRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"Uniqueness", testUniqueness},
+		{"Update", testUpdate},
+		{"Versioning", testVersioning},
+		{"SoftDelete", testSoftDelete},
+		{"VerifyEmail", testVerifyEmail},
+		{"PurgeAndAnonymize", testPurgeAndAnonymize},
+		{"List", testList},
+		{"InputNotAliased", testInputNotAliased},
+		{"OutputNotAliased", testOutputNotAliased},
+		{"ConcurrentAccess", testConcurrentAccess},
+		{"ConcurrentConditionalUpdates", testConcurrentConditionalUpdates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+var at = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newUser(id int64) *domain.User {
+	return &domain.User{
+		ID:        id,
+		Username:  fmt.Sprintf("user%d", id),
+		Email:     fmt.Sprintf("user%d@example.com", id),
+		Role:      domain.RoleUser,
+		CreatedAt: at,
+	}
+}
+
+func create(t *testing.T, r domain.UserRepository, ids ...int64) {
+	t.Helper()
+	for _, id := range ids {
+		require.NoError(t, r.Create(context.Background(), newUser(id)))
+	}
+}
+
+func get(t *testing.T, r domain.UserRepository, id int64) *domain.User {
+	t.Helper()
+	user, err := r.GetByID(domain.WithDeleted(context.Background()), id)
+	require.NoError(t, err)
+	return user
+}
+
+func testCreateAndGet(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	user := newUser(1)
+	require.NoError(t, r.Create(ctx, user))
+	assert.Equal(t, int64(1), user.Version, "Create fills in the version")
+
+	got, err := r.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+	got, err = r.GetByUsername(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	_, err = r.GetByID(ctx, 2)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = r.GetByUsername(ctx, "user2")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testUniqueness(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1, 2)
+	require.NoError(t, r.Delete(ctx, 2, at))
+
+	tests := []struct {
+		name string
+		user *domain.User
+	}{
+		{"same ID", &domain.User{ID: 1, Username: "other", Email: "other@example.com"}},
+		{"same username", &domain.User{ID: 3, Username: "user1", Email: "other@example.com"}},
+		{"same email", &domain.User{ID: 3, Username: "other", Email: "user1@example.com"}},
+		{"ID of a deleted user", &domain.User{ID: 2, Username: "other", Email: "other@example.com"}},
+		{"username of a deleted user", &domain.User{ID: 3, Username: "user2", Email: "other@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, r.Create(ctx, tt.user), domain.ErrConflict)
+		})
+	}
+
+	create(t, r, 3)
+	err := r.Update(ctx, &domain.User{ID: 3, Username: "user1", Email: "user3@example.com"})
+	assert.ErrorIs(t, err, domain.ErrConflict, "renaming to a taken username")
+}
+
+func testUpdate(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1)
+	require.NoError(t, r.VerifyEmail(ctx, 1, "user1@example.com", at))
+
+	update := &domain.User{ID: 1, Username: "john", Email: "user1@example.com"}
+	require.NoError(t, r.Update(ctx, update))
+	got := get(t, r, 1)
+	assert.Equal(t, update, got, "Update fills in the fields it keeps")
+	assert.Equal(t, domain.RoleUser, got.Role, "an empty role is kept")
+	assert.True(t, got.CreatedAt.Equal(at))
+	assert.True(t, got.EmailVerified(), "verification survives while the email stays")
+
+	_, err := r.GetByUsername(ctx, "user1")
+	assert.ErrorIs(t, err, domain.ErrNotFound, "the old username is free")
+	create(t, r, 2)
+	require.NoError(t, r.Update(ctx, &domain.User{ID: 2, Username: "user1", Email: "user2@example.com"}))
+
+	require.NoError(t, r.Update(ctx, &domain.User{ID: 1, Username: "john", Email: "john@example.com"}))
+	assert.False(t, get(t, r, 1).EmailVerified(), "a new email is unverified")
+
+	assert.ErrorIs(t, r.Update(ctx, newUser(9)), domain.ErrNotFound)
+}
+
+func testVersioning(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1)
+
+	require.NoError(t, r.Update(domain.WithExpectedVersion(ctx, 1), newUser(1)))
+	assert.Equal(t, int64(2), get(t, r, 1).Version)
+	assert.ErrorIs(t, r.Update(domain.WithExpectedVersion(ctx, 1), newUser(1)), domain.ErrVersionConflict)
+	assert.ErrorIs(t, r.Delete(domain.WithExpectedVersion(ctx, 1), 1, at), domain.ErrVersionConflict)
+	require.NoError(t, r.Delete(domain.WithExpectedVersion(ctx, 2), 1, at))
+	assert.Equal(t, int64(3), get(t, r, 1).Version)
+
+	_, err := r.Restore(domain.WithExpectedVersion(ctx, 2), 1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	restored, err := r.Restore(domain.WithExpectedVersion(ctx, 3), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), restored.Version)
+}
+
+func testSoftDelete(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1)
+	require.NoError(t, r.Delete(ctx, 1, at))
+
+	_, err := r.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = r.GetByUsername(ctx, "user1")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.True(t, get(t, r, 1).DeletedAt.Equal(at), "found when asked for deleted users")
+	assert.ErrorIs(t, r.Delete(ctx, 1, at), domain.ErrNotFound)
+	assert.ErrorIs(t, r.Update(ctx, newUser(1)), domain.ErrNotFound)
+
+	restored, err := r.Restore(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, restored, get(t, r, 1))
+	_, err = r.Restore(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrNotDeleted)
+	_, err = r.Restore(ctx, 9)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testVerifyEmail(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1)
+
+	assert.ErrorIs(t, r.VerifyEmail(ctx, 1, "other@example.com", at), domain.ErrConflict)
+	assert.ErrorIs(t, r.VerifyEmail(ctx, 9, "user9@example.com", at), domain.ErrNotFound)
+	require.NoError(t, r.VerifyEmail(ctx, 1, "user1@example.com", at))
+	got := get(t, r, 1)
+	assert.True(t, got.EmailVerifiedAt.Equal(at))
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func testPurgeAndAnonymize(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1, 2, 3)
+	require.NoError(t, r.Delete(ctx, 1, at))
+
+	anonymized, err := r.Anonymize(ctx, 2, at)
+	require.NoError(t, err)
+	assert.Equal(t, "erased-2", anonymized.Username)
+	assert.Equal(t, anonymized, get(t, r, 2))
+	create(t, r, 4)
+	require.NoError(t, r.Update(ctx, &domain.User{ID: 4, Username: "user2", Email: "user2@example.com"}),
+		"anonymizing frees the username and email")
+
+	n, err := r.Purge(ctx, at.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, id := range []int64{1, 2} {
+		_, err := r.GetByID(domain.WithDeleted(ctx), id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	}
+	get(t, r, 3)
+	create(t, r, 1)
+}
+
+func testList(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 3, 1, 5, 2, 4)
+	require.NoError(t, r.Delete(ctx, 4, at))
+
+	var ids []int64
+	opts := domain.ListOptions{Limit: 2}
+	for {
+		page, err := r.List(ctx, opts)
+		require.NoError(t, err)
+		for _, user := range page.Users {
+			ids = append(ids, user.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []int64{1, 2, 3, 5}, ids)
+
+	page, err := r.List(ctx, domain.ListOptions{SortBy: domain.SortByUsername, Descending: true, IncludeDeleted: true})
+	require.NoError(t, err)
+	ids = ids[:0]
+	for _, user := range page.Users {
+		ids = append(ids, user.ID)
+	}
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, ids)
+}
+
+// testInputNotAliased checks that a repository keeps none of the users it is
+// given: callers change them after the call.
+func testInputNotAliased(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	user := newUser(1)
+	verified := at
+	user.EmailVerifiedAt = &verified
+	require.NoError(t, r.Create(ctx, user))
+	want := get(t, r, 1).Clone()
+
+	user.Username = "changed"
+	verified = at.Add(time.Hour)
+	assert.Equal(t, want, get(t, r, 1), "changing the created user")
+
+	update := newUser(1)
+	require.NoError(t, r.Update(ctx, update))
+	want = get(t, r, 1).Clone()
+	update.Username = "changed"
+	assert.Equal(t, want, get(t, r, 1), "changing the updated user")
+}
+
+// testOutputNotAliased checks that the users a repository returns are the
+// callers' own to change. Expectations are cloned, in case they are aliased
+// too.
+func testOutputNotAliased(t *testing.T, r domain.UserRepository) {
+	ctx := context.Background()
+	create(t, r, 1, 2, 3)
+	require.NoError(t, r.VerifyEmail(ctx, 1, "user1@example.com", at))
+	require.NoError(t, r.Delete(ctx, 3, at))
+
+	scribble := func(user *domain.User) {
+		user.Username = "changed"
+		user.Version = 99
+		if user.EmailVerifiedAt != nil {
+			*user.EmailVerifiedAt = at.Add(time.Hour)
+		}
+		if user.DeletedAt != nil {
+			*user.DeletedAt = at.Add(time.Hour)
+		}
+	}
+	check := func(name string, id int64, call func() *domain.User) {
+		want := get(t, r, id).Clone()
+		scribble(call())
+		assert.Equal(t, want, get(t, r, id), name)
+	}
+
+	check("GetByID", 1, func() *domain.User { return get(t, r, 1) })
+	check("GetByUsername", 1, func() *domain.User {
+		user, err := r.GetByUsername(ctx, "user1")
+		require.NoError(t, err)
+		return user
+	})
+	check("GetByID of a deleted user", 3, func() *domain.User { return get(t, r, 3) })
+	check("List", 1, func() *domain.User {
+		page, err := r.List(ctx, domain.ListOptions{})
+		require.NoError(t, err)
+		return page.Users[0]
+	})
+
+	restored, err := r.Restore(ctx, 3)
+	require.NoError(t, err)
+	want := get(t, r, 3).Clone()
+	scribble(restored)
+	assert.Equal(t, want, get(t, r, 3), "Restore")
+
+	anonymized, err := r.Anonymize(ctx, 2, at)
+	require.NoError(t, err)
+	want = get(t, r, 2).Clone()
+	scribble(anonymized)
+	assert.Equal(t, want, get(t, r, 2), "Anonymize")
+}
+
+// testConcurrentAccess changes users while others read and change what they
+// read, which the race detector reports if any of it is shared.
+func testConcurrentAccess(t *testing.T, r domain.UserRepository) {
+	const (
+		users   = 8
+		workers = 8
+		rounds  = 50
+	)
+	ctx := context.Background()
+	for id := int64(1); id <= users; id++ {
+		create(t, r, id)
+		require.NoError(t, r.VerifyEmail(ctx, id, newUser(id).Email, at))
+	}
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				id := int64((w+i)%users) + 1
+				var user *domain.User
+				var err error
+				switch i % 4 {
+				case 0:
+					user = newUser(id)
+					err = r.Update(ctx, user)
+				case 1:
+					user, err = r.GetByID(ctx, id)
+				case 2:
+					user, err = r.GetByUsername(ctx, newUser(id).Username)
+				case 3:
+					var page *domain.UserPage
+					if page, err = r.List(ctx, domain.ListOptions{}); err == nil {
+						for _, u := range page.Users {
+							u.Username = "changed"
+						}
+					}
+				}
+				if err != nil {
+					t.Errorf("worker %d round %d: %v", w, i, err)
+					return
+				}
+				if user != nil {
+					user.Username = "changed"
+					if user.EmailVerifiedAt != nil {
+						*user.EmailVerifiedAt = at.Add(time.Hour)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id := int64(1); id <= users; id++ {
+		assert.Equal(t, newUser(id).Username, get(t, r, id).Username)
+	}
+}
+
+func testConcurrentConditionalUpdates(t *testing.T, r domain.UserRepository) {
+	const writers = 20
+	ctx := context.Background()
+	create(t, r, 1)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user := &domain.User{ID: 1, Username: fmt.Sprintf("writer%d", i), Email: "user1@example.com"}
+			errs <- r.Update(domain.WithExpectedVersion(ctx, 1), user)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded int
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	}
+	assert.Equal(t, 1, succeeded, "conditional updates that succeeded")
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// DefaultShards is a shard count that keeps lock contention low on typical
+// core counts.
+const DefaultShards = 64
+
+// userShard holds the users whose IDs hash to it.
+type userShard struct {
+	mu      sync.RWMutex
+	users   map[int64]*domain.User
+	deleted map[int64]*domain.User
+}
+
+// shardedUserRepository spreads users over shards by ID, each with its own
+// lock, so that operations on different users rarely wait for each other.
+// Usernames and emails must be unique across shards, which the index
+// guarantees. Locks are taken shard first, then index; List takes every
+// shard lock in order.
+//
+// Users are copied in and out, so that the index never disagrees with what
+// callers do to their copies.
+type shardedUserRepository struct {
+	shards []*userShard
+	index  identityIndex
+}
+
+// NewShardedMemoryUserRepository returns an in-memory repository split into
+// the given number of shards, DefaultShards if not positive. It behaves like
+// NewMemoryUserRepository but scales with concurrent writers.
+func NewShardedMemoryUserRepository(shards int) domain.UserRepository {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+	r := &shardedUserRepository{
+		shards: make([]*userShard, shards),
+		index: identityIndex{
+			usernames: make(map[string]int64),
+			emails:    make(map[string]int64),
+		},
+	}
+	for i := range r.shards {
+		// Allocated one by one, so that neighbouring locks do not share a
+		// cache line.
+		r.shards[i] = &userShard{
+			users:   make(map[int64]*domain.User),
+			deleted: make(map[int64]*domain.User),
+		}
+	}
+	return r
+}
+
+func (r *shardedUserRepository) shard(id int64) *userShard {
+	// IDs are often sequential; mixing them spreads neighbours apart.
+	h := uint64(id) * 0x9e3779b97f4a7c15
+	h ^= h >> 32
+	return r.shards[h%uint64(len(r.shards))]
+}
+
+func (r *shardedUserRepository) Create(ctx context.Context, user *domain.User) error {
+	s := r.shard(user.ID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.ID]; exists {
+		return domain.ErrConflict
+	}
+	if _, exists := s.deleted[user.ID]; exists {
+		return domain.ErrConflict
+	}
+	if !r.index.claim(user, nil) {
+		return domain.ErrConflict
+	}
+
+	user.Version = 1
+	user.DeletedAt = nil
+	s.users[user.ID] = user.Clone()
+	return nil
+}
+
+func (r *shardedUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	s := r.shard(id)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, exists := s.users[id]
+	if !exists && domain.IncludeDeleted(ctx) {
+		user, exists = s.deleted[id]
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	return user.Clone(), nil
+}
+
+func (r *shardedUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	for {
+		id, ok := r.index.lookup(username)
+		if !ok {
+			return nil, domain.ErrNotFound
+		}
+
+		s := r.shard(id)
+		s.mu.RLock()
+		user, live := s.users[id]
+		if !live {
+			user = s.deleted[id]
+		}
+		s.mu.RUnlock()
+		switch {
+		case user == nil || user.Username != username:
+			// Renamed or purged since the lookup; the name may have moved
+			// to another user.
+			continue
+		case !live:
+			return nil, domain.ErrNotFound
+		}
+		return user.Clone(), nil
+	}
+}
+
+func (r *shardedUserRepository) Update(ctx context.Context, user *domain.User) error {
+	s := r.shard(user.ID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[user.ID]
+	if !exists {
+		return domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(ctx, existing.Version); err != nil {
+		return err
+	}
+	if !r.index.claim(user, existing) {
+		return domain.ErrConflict
+	}
+
+	if user.Role == "" {
+		user.Role = existing.Role
+	}
+	// Verification belongs to the address, so it survives only while the
+	// address stays the same.
+	user.EmailVerifiedAt = nil
+	if user.Email == existing.Email {
+		user.EmailVerifiedAt = existing.EmailVerifiedAt
+	}
+	user.CreatedAt = existing.CreatedAt
+	user.CreatedBy = existing.CreatedBy
+	user.Version = existing.Version + 1
+	user.DeletedAt = nil
+	s.users[user.ID] = user.Clone()
+	return nil
+}
+
+func (r *shardedUserRepository) Delete(ctx context.Context, id int64, at time.Time) error {
+	s := r.shard(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		return domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(ctx, existing.Version); err != nil {
+		return err
+	}
+
+	deleted := existing.Clone()
+	deleted.DeletedAt = &at
+	deleted.Version++
+	s.deleted[id] = deleted
+	delete(s.users, id)
+	return nil
+}
+
+func (r *shardedUserRepository) Restore(ctx context.Context, id int64) (*domain.User, error) {
+	s := r.shard(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.deleted[id]
+	if !exists {
+		if _, live := s.users[id]; live {
+			return nil, domain.ErrNotDeleted
+		}
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(ctx, existing.Version); err != nil {
+		return nil, err
+	}
+
+	restored := existing.Clone()
+	restored.DeletedAt = nil
+	restored.Version++
+	s.users[id] = restored
+	delete(s.deleted, id)
+	return restored.Clone(), nil
+}
+
+func (r *shardedUserRepository) VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error {
+	s := r.shard(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		return domain.ErrNotFound
+	}
+	if existing.Email != email {
+		return domain.ErrConflict
+	}
+
+	verified := existing.Clone()
+	verified.EmailVerifiedAt = &at
+	verified.Version++
+	s.users[id] = verified
+	return nil
+}
+
+func (r *shardedUserRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	var purged int
+	for _, s := range r.shards {
+		s.mu.Lock()
+		for id, user := range s.deleted {
+			if user.DeletedAt.Before(cutoff) {
+				r.index.release(user)
+				delete(s.deleted, id)
+				purged++
+			}
+		}
+		s.mu.Unlock()
+	}
+	return purged, nil
+}
+
+func (r *shardedUserRepository) Anonymize(ctx context.Context, id int64, at time.Time) (*domain.User, error) {
+	s := r.shard(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		if existing, exists = s.deleted[id]; !exists {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	anonymized := existing.Clone()
+	anonymized.Anonymize(at)
+	anonymized.Version++
+	r.index.release(existing)
+	r.index.claimFree(anonymized)
+	s.deleted[id] = anonymized
+	delete(s.users, id)
+	return anonymized.Clone(), nil
+}
+
+func (r *shardedUserRepository) List(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+
+	var pivot *domain.User
+	if opts.Cursor != "" {
+		cursor, err := domain.DecodeCursor(opts.Cursor, opts)
+		if err != nil {
+			return nil, err
+		}
+		if pivot, err = cursor.Pivot(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Holding every shard at once makes the page a consistent snapshot.
+	for _, s := range r.shards {
+		s.mu.RLock()
+	}
+	var users []*domain.User
+	collect := func(source map[int64]*domain.User) {
+		for _, user := range source {
+			if opts.Matches(user) && (pivot == nil || compareUsers(user, pivot, opts) > 0) {
+				users = append(users, user.Clone())
+			}
+		}
+	}
+	for _, s := range r.shards {
+		collect(s.users)
+		if opts.IncludeDeleted {
+			collect(s.deleted)
+		}
+	}
+	for _, s := range r.shards {
+		s.mu.RUnlock()
+	}
+
+	return paginate(users, opts), nil
+}
+
+// identityIndex maps the usernames and emails of all users, live or
+// soft-deleted, to their IDs. Callers change a user's entries only while
+// holding the lock of the user's shard.
+type identityIndex struct {
+	mu        sync.RWMutex
+	usernames map[string]int64
+	emails    map[string]int64
+}
+
+func (x *identityIndex) lookup(username string) (int64, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	id, ok := x.usernames[username]
+	return id, ok
+}
+
+// claim moves the entries of the user from previous, nil for a new user, to
+// user's username and email. It reports false, changing nothing, if another
+// user holds either of them.
+func (x *identityIndex) claim(user, previous *domain.User) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if id, taken := x.usernames[user.Username]; taken && id != user.ID {
+		return false
+	}
+	if id, taken := x.emails[user.Email]; taken && id != user.ID {
+		return false
+	}
+	if previous != nil {
+		x.releaseLocked(previous)
+	}
+	x.usernames[user.Username] = user.ID
+	x.emails[user.Email] = user.ID
+	return true
+}
+
+// claimFree takes user's username and email where no other user holds
+// them. Anonymized users use it: their placeholders are derived from the ID
+// and so unique, unless a user chose one, who then keeps it.
+func (x *identityIndex) claimFree(user *domain.User) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if _, taken := x.usernames[user.Username]; !taken {
+		x.usernames[user.Username] = user.ID
+	}
+	if _, taken := x.emails[user.Email]; !taken {
+		x.emails[user.Email] = user.ID
+	}
+}
+
+func (x *identityIndex) release(user *domain.User) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.releaseLocked(user)
+}
+
+// releaseLocked drops the entries of user, unless they belong to another.
+func (x *identityIndex) releaseLocked(user *domain.User) {
+	if x.usernames[user.Username] == user.ID {
+		delete(x.usernames, user.Username)
+	}
+	if x.emails[user.Email] == user.ID {
+		delete(x.emails, user.Email)
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUser(id int64) *domain.User {
+	return &domain.User{ID: id, Username: fmt.Sprintf("user%d", id), Email: fmt.Sprintf("user%d@example.com", id)}
+}
+
+func TestShardedMemoryUserRepository_CRUD(t *testing.T) {
+	r := NewShardedMemoryUserRepository(4)
+	ctx := context.Background()
+
+	for id := int64(1); id <= 20; id++ {
+		assert.NoError(t, r.Create(ctx, newTestUser(id)))
+	}
+	for id := int64(1); id <= 20; id++ {
+		user, err := r.GetByID(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("user%d", id), user.Username)
+		assert.Equal(t, int64(1), user.Version)
+	}
+	assert.ErrorIs(t, r.Create(ctx, newTestUser(3)), domain.ErrConflict)
+
+	user, err := r.GetByUsername(ctx, "user7")
+	assert.NoError(t, err)
+	user.Username = "changed"
+	stored, _ := r.GetByID(ctx, 7)
+	assert.Equal(t, "user7", stored.Username, "callers get copies")
+
+	assert.NoError(t, r.Update(ctx, user))
+	_, err = r.GetByUsername(ctx, "user7")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	renamed, err := r.GetByUsername(ctx, "changed")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), renamed.Version)
+
+	assert.NoError(t, r.Delete(ctx, 7, time.Now()))
+	_, err = r.GetByID(ctx, 7)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = r.GetByUsername(ctx, "changed")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	deleted, err := r.GetByID(domain.WithDeleted(ctx), 7)
+	assert.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	restored, err := r.Restore(ctx, 7)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	_, err = r.Restore(ctx, 7)
+	assert.ErrorIs(t, err, domain.ErrNotDeleted)
+
+	assert.NoError(t, r.VerifyEmail(ctx, 7, "user7@example.com", time.Now()))
+	assert.ErrorIs(t, r.VerifyEmail(ctx, 7, "other@example.com", time.Now()), domain.ErrConflict)
+	verified, _ := r.GetByID(ctx, 7)
+	assert.True(t, verified.EmailVerified())
+
+	page, err := r.List(ctx, domain.ListOptions{Limit: 8, SortBy: domain.SortByUsername})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 8)
+	var listed []int64
+	for {
+		for _, u := range page.Users {
+			listed = append(listed, u.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		page, err = r.List(ctx, domain.ListOptions{Limit: 8, SortBy: domain.SortByUsername, Cursor: page.NextCursor})
+		assert.NoError(t, err)
+	}
+	assert.Len(t, listed, 20, "pages cover every shard")
+}
+
+func TestShardedMemoryUserRepository_Uniqueness(t *testing.T) {
+	r := NewShardedMemoryUserRepository(8)
+	ctx := context.Background()
+	assert.NoError(t, r.Create(ctx, &domain.User{ID: 1, Username: "alice", Email: "alice@example.com"}))
+
+	assert.ErrorIs(t, r.Create(ctx, &domain.User{ID: 2, Username: "alice", Email: "other@example.com"}), domain.ErrConflict)
+	assert.ErrorIs(t, r.Create(ctx, &domain.User{ID: 2, Username: "bob", Email: "alice@example.com"}), domain.ErrConflict)
+	assert.NoError(t, r.Create(ctx, &domain.User{ID: 2, Username: "bob", Email: "bob@example.com"}))
+	assert.ErrorIs(t, r.Update(ctx, &domain.User{ID: 2, Username: "alice", Email: "bob@example.com"}), domain.ErrConflict)
+
+	// Renaming frees the old name and email.
+	assert.NoError(t, r.Update(ctx, &domain.User{ID: 1, Username: "alicia", Email: "alicia@example.com"}))
+	assert.NoError(t, r.Update(ctx, &domain.User{ID: 2, Username: "alice", Email: "alice@example.com"}))
+
+	// Deleted users keep their name until purged.
+	assert.NoError(t, r.Delete(ctx, 1, time.Now()))
+	assert.ErrorIs(t, r.Create(ctx, &domain.User{ID: 3, Username: "alicia", Email: "c@example.com"}), domain.ErrConflict)
+	n, err := r.Purge(ctx, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, r.Create(ctx, &domain.User{ID: 3, Username: "alicia", Email: "alicia@example.com"}))
+
+	// Anonymizing frees the personal data right away.
+	anonymized, err := r.Anonymize(ctx, 2, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "erased-2", anonymized.Username)
+	assert.NoError(t, r.Create(ctx, &domain.User{ID: 4, Username: "alice", Email: "alice@example.com"}))
+	assert.ErrorIs(t, r.Create(ctx, &domain.User{ID: 5, Username: "erased-2", Email: "e@example.com"}), domain.ErrConflict)
+}
+
+func TestShardedMemoryUserRepository_ConcurrentClaims(t *testing.T) {
+	r := NewShardedMemoryUserRepository(16)
+	ctx := context.Background()
+
+	var created atomic.Int32
+	var wg sync.WaitGroup
+	for id := int64(1); id <= 50; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The users spread over the shards, but all want the same name.
+			if r.Create(ctx, &domain.User{ID: id, Username: "popular", Email: fmt.Sprintf("u%d@example.com", id)}) == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, created.Load())
+
+	winner, err := r.GetByUsername(ctx, "popular")
+	assert.NoError(t, err)
+	for id := int64(100); id < 110; id++ {
+		assert.NoError(t, r.Create(ctx, newTestUser(id)))
+	}
+
+	// Users swap names back and forth while others look them up.
+	var renames atomic.Int32
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			id := int64(100 + i)
+			for j := range 20 {
+				name := fmt.Sprintf("swap%d", j%2)
+				user, err := r.GetByID(ctx, id)
+				if !assert.NoError(t, err) {
+					return
+				}
+				user.Username = name
+				if r.Update(ctx, user) == nil {
+					renames.Add(1)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if user, err := r.GetByUsername(ctx, "popular"); assert.NoError(t, err) {
+					assert.Equal(t, winner.ID, user.ID)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Positive(t, renames.Load())
+
+	// Each name belongs to at most one user, as the index says.
+	owners := map[string]int64{}
+	page, err := r.List(ctx, domain.ListOptions{Limit: 100})
+	assert.NoError(t, err)
+	for _, user := range page.Users {
+		_, dup := owners[user.Username]
+		assert.False(t, dup, user.Username)
+		owners[user.Username] = user.ID
+		found, err := r.GetByUsername(ctx, user.Username)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+	}
+}
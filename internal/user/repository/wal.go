@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"slices"
+	"sync"
+
+	"repo-guardian/internal/domain"
+)
+
+// ErrCorrupt reports a snapshot or write-ahead log damaged other than by a
+// write cut short, which needs an operator to look at it.
+var ErrCorrupt = errors.New("corrupt user data")
+
+// errTornTail reports records cut short at the end of the log, as a crash
+// during a write leaves them.
+var errTornTail = errors.New("torn record at end of log")
+
+var errWALClosed = errors.New("write-ahead log is closed")
+
+// Records are framed by their length and CRC-32C checksum, both little
+// endian, followed by their JSON encoding.
+const (
+	frameHeaderSize = 8
+	maxRecordSize   = 64 << 20
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// walRecord is a change to the users: put stores a user, live or deleted
+// depending on its DeletedAt, and remove drops deleted users for good. The
+// credential and MFA ops store or delete what a user signs in with.
+// Snapshots are puts closed by an end record with their count.
+type walRecord struct {
+	Op         string                `json:"op"`
+	User       *domain.User          `json:"user,omitempty"`
+	IDs        []int64               `json:"ids,omitempty"`
+	Credential *domain.Credential    `json:"credential,omitempty"`
+	MFA        *domain.MFAEnrollment `json:"mfa,omitempty"`
+	UserID     int64                 `json:"user_id,omitempty"`
+	Count      int                   `json:"count,omitempty"`
+}
+
+const (
+	opPut              = "put"
+	opRemove           = "remove"
+	opPutCredential    = "put_credential"
+	opDeleteCredential = "delete_credential"
+	opPutMFA           = "put_mfa"
+	opDeleteMFA        = "delete_mfa"
+	opEnd              = "end"
+)
+
+func encodeFrame(rec walRecord) ([]byte, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(frame, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.Checksum(payload, castagnoli))
+	return append(frame, payload...), nil
+}
+
+// decodeRecords calls apply with each record in data and returns the length
+// of the valid records. A torn last record is reported with errTornTail,
+// other damage with ErrCorrupt.
+func decodeRecords(data []byte, apply func(walRecord) error) (int, error) {
+	off := 0
+	for off < len(data) {
+		rest := data[off:]
+		payload, ok := decodeFrame(rest)
+		if !ok {
+			if tornTail(rest) {
+				return off, errTornTail
+			}
+			return off, fmt.Errorf("%w: bad record at offset %d", ErrCorrupt, off)
+		}
+		var rec walRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			return off, fmt.Errorf("%w: record at offset %d: %v", ErrCorrupt, off, err)
+		}
+		if err := apply(rec); err != nil {
+			return off, err
+		}
+		off += frameHeaderSize + len(payload)
+	}
+	return off, nil
+}
+
+func decodeFrame(data []byte) ([]byte, bool) {
+	if len(data) < frameHeaderSize {
+		return nil, false
+	}
+	n := int(binary.LittleEndian.Uint32(data))
+	if n == 0 || n > maxRecordSize || frameHeaderSize+n > len(data) {
+		return nil, false
+	}
+	payload := data[frameHeaderSize : frameHeaderSize+n]
+	if crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(data[4:]) {
+		return nil, false
+	}
+	return payload, true
+}
+
+// tornTail reports whether the invalid record at the start of rest is the
+// remains of the last write before a crash: its header or payload is cut
+// short by the end of the data and no record follows it, or only zeros
+// follow, as some file systems leave after extending a file. A record that
+// is complete but fails its checksum is damage, not a torn write.
+func tornTail(rest []byte) bool {
+	if !slices.ContainsFunc(rest, func(b byte) bool { return b != 0 }) {
+		return true
+	}
+	if len(rest) < frameHeaderSize {
+		return true
+	}
+	n := int(binary.LittleEndian.Uint32(rest))
+	if n == 0 || n > maxRecordSize || frameHeaderSize+n <= len(rest) {
+		return false
+	}
+	// A length damaged to reach past the end would otherwise pass for a torn
+	// write and hide the records after it.
+	for off := frameHeaderSize + 1; off < len(rest); off++ {
+		if _, ok := decodeFrame(rest[off:]); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// wal appends records to a file. A failed write is rolled back, so that
+// later records stay readable; when even that or a sync fails, what reached
+// the disk is unknown and the log refuses further records.
+type wal struct {
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	syncEach bool
+	dirty    bool
+	err      error
+}
+
+func openWAL(path string, syncEach bool) (*wal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &wal{f: f, size: info.Size(), syncEach: syncEach}, nil
+}
+
+func (w *wal) put(user *domain.User) error {
+	return w.append(walRecord{Op: opPut, User: user})
+}
+
+func (w *wal) remove(ids []int64) error {
+	return w.append(walRecord{Op: opRemove, IDs: ids})
+}
+
+func (w *wal) putCredential(cred *domain.Credential) error {
+	return w.append(walRecord{Op: opPutCredential, Credential: cred})
+}
+
+func (w *wal) deleteCredential(userID int64) error {
+	return w.append(walRecord{Op: opDeleteCredential, UserID: userID})
+}
+
+func (w *wal) putMFA(enrollment *domain.MFAEnrollment) error {
+	return w.append(walRecord{Op: opPutMFA, MFA: enrollment})
+}
+
+func (w *wal) deleteMFA(userID int64) error {
+	return w.append(walRecord{Op: opDeleteMFA, UserID: userID})
+}
+
+func (w *wal) append(rec walRecord) error {
+	frame, err := encodeFrame(rec)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := w.f.Write(frame); err != nil {
+		if terr := w.f.Truncate(w.size); terr != nil {
+			w.err = fmt.Errorf("write-ahead log unusable: %w", errors.Join(err, terr))
+		}
+		return err
+	}
+	w.size += int64(len(frame))
+	w.dirty = true
+	if w.syncEach {
+		return w.syncLocked()
+	}
+	return nil
+}
+
+// sync flushes the records appended since the last sync to disk.
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	return w.syncLocked()
+}
+
+func (w *wal) syncLocked() error {
+	if !w.dirty {
+		return nil
+	}
+	if err := w.f.Sync(); err != nil {
+		w.err = fmt.Errorf("write-ahead log unusable: %w", err)
+		return w.err
+	}
+	w.dirty = false
+	return nil
+}
+
+// reset empties the log once a snapshot holds its records.
+func (w *wal) reset() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.f.Truncate(0); err != nil {
+		w.err = fmt.Errorf("write-ahead log unusable: %w", err)
+		return w.err
+	}
+	w.size = 0
+	w.dirty = true
+	return w.syncLocked()
+}
+
+func (w *wal) len() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+// health returns the error that makes the log refuse records, if any.
+func (w *wal) health() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if errors.Is(w.err, errWALClosed) {
+		return nil
+	}
+	err := w.err
+	if err == nil {
+		err = w.syncLocked()
+	}
+	w.err = errWALClosed
+	return errors.Join(err, w.f.Close())
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFrames(t *testing.T, ids ...int64) ([]byte, []int) {
+	t.Helper()
+	var data []byte
+	var ends []int
+	for _, id := range ids {
+		frame, err := encodeFrame(walRecord{Op: opPut, User: newTestUser(id)})
+		assert.NoError(t, err)
+		data = append(data, frame...)
+		ends = append(ends, len(data))
+	}
+	return data, ends
+}
+
+func TestDecodeRecords(t *testing.T) {
+	data, ends := testFrames(t, 1, 2, 3)
+	decode := func(data []byte) ([]int64, int, error) {
+		var ids []int64
+		valid, err := decodeRecords(data, func(rec walRecord) error {
+			ids = append(ids, rec.User.ID)
+			return nil
+		})
+		return ids, valid, err
+	}
+
+	ids, valid, err := decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.Equal(t, len(data), valid)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantIDs []int64
+		wantErr error
+	}{
+		{"header cut short", data[:ends[1]+3], []int64{1, 2}, errTornTail},
+		{"payload cut short", data[:len(data)-5], []int64{1, 2}, errTornTail},
+		{"last record garbled", flip(data, len(data)-2), []int64{1, 2}, ErrCorrupt},
+		{"zeros after the end", append(data[:ends[1]:ends[1]], make([]byte, 100)...), []int64{1, 2}, errTornTail},
+		{"middle record garbled", flip(data, ends[0]+frameHeaderSize+2), []int64{1}, ErrCorrupt},
+		{"middle length garbled", flip(data, ends[0]+3), []int64{1}, ErrCorrupt},
+		{"middle length past the end", setLength(data, ends[0], len(data)), []int64{1}, ErrCorrupt},
+		{"middle length shortened", setLength(data, ends[0], ends[1]-ends[0]-frameHeaderSize-1), []int64{1}, ErrCorrupt},
+		{"last length past the end", setLength(data, ends[1], len(data)), []int64{1, 2}, errTornTail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, valid, err := decode(tt.data)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, ends[len(tt.wantIDs)-1], valid)
+		})
+	}
+}
+
+// setLength returns a copy of data with the length of the record at off
+// set to n.
+func setLength(data []byte, off, n int) []byte {
+	data = append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(data[off:], uint32(n))
+	return data
+}
+
+// flip returns a copy of data with the byte at i changed.
+func flip(data []byte, i int) []byte {
+	data = append([]byte(nil), data...)
+	data[i] ^= 0xff
+	return data
+}
+
+func TestWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	w, err := openWAL(path, true)
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.put(&domain.User{ID: 1, Username: "john"}))
+	assert.NoError(t, w.remove([]int64{1}))
+	assert.Positive(t, w.len())
+	assert.NoError(t, w.close())
+	assert.ErrorIs(t, w.put(&domain.User{ID: 2}), errWALClosed)
+	assert.ErrorIs(t, w.health(), errWALClosed)
+
+	w, err = openWAL(path, false)
+	assert.NoError(t, err)
+	size := w.len()
+	assert.Positive(t, size, "appends to what is there")
+	assert.NoError(t, w.reset())
+	assert.Zero(t, w.len())
+	assert.NoError(t, w.close())
+}
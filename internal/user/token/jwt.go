@@ -0,0 +1,78 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type claims struct {
+	jwt.RegisteredClaims
+	Role domain.Role `json:"role,omitempty"`
+}
+
+type jwtIssuer struct {
+	keys   *KeySet
+	issuer string
+	ttl    time.Duration
+	clock  domain.Clock
+}
+
+// NewJWTIssuer returns an issuer of JWT access tokens valid for ttl. The
+// token subject is the user ID and the user's role travels in a role claim.
+func NewJWTIssuer(keys *KeySet, issuer string, ttl time.Duration, clock domain.Clock) domain.AccessTokenIssuer {
+	return &jwtIssuer{keys: keys, issuer: issuer, ttl: ttl, clock: clock}
+}
+
+func (i *jwtIssuer) Issue(user *domain.User) (string, time.Time, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, err
+	}
+	now := i.clock.Now()
+	expiresAt := now.Add(i.ttl)
+	claims := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+		},
+		Role: user.Role,
+	}
+
+	key := i.keys.active
+	t := jwt.NewWithClaims(key.method, claims)
+	t.Header["kid"] = key.ID
+	signed, err := t.SignedString(key.signKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+func (i *jwtIssuer) Verify(token string) (domain.Principal, error) {
+	var claims claims
+	_, err := jwt.ParseWithClaims(token, &claims, i.keys.lookup,
+		jwt.WithValidMethods([]string{"HS256", "EdDSA", "RS256"}),
+		jwt.WithIssuer(i.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(i.clock.Now),
+	)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %v", domain.ErrInvalidToken, err)
+	}
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: malformed subject", domain.ErrInvalidToken)
+	}
+	return domain.UserPrincipal(id, claims.Role), nil
+}
@@ -0,0 +1,139 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+var testNow = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newEd25519Key(t *testing.T, id string) Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEd25519Key(id, private)
+}
+
+func TestJWTIssuer_RoundTrip(t *testing.T) {
+	hmacKey, err := NewHMACKey("hs", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []Key{hmacKey, newEd25519Key(t, "ed"), NewRSAKey("rs", rsaPrivate)} {
+		t.Run(key.method.Alg(), func(t *testing.T) {
+			keys, err := NewKeySet(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			issuer := NewJWTIssuer(keys, "test", time.Minute, fixedClock(testNow))
+
+			signed, expiresAt, err := issuer.Issue(&domain.User{ID: 42, Role: domain.RoleAdmin})
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			if !expiresAt.Equal(testNow.Add(time.Minute)) {
+				t.Errorf("Issue() expiresAt = %v, want %v", expiresAt, testNow.Add(time.Minute))
+			}
+			principal, err := issuer.Verify(signed)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !reflect.DeepEqual(principal, domain.UserPrincipal(42, domain.RoleAdmin)) {
+				t.Errorf("Verify() = %+v, want user:42", principal)
+			}
+		})
+	}
+}
+
+func TestJWTIssuer_Rotation(t *testing.T) {
+	oldKey, newKey := newEd25519Key(t, "2024-01"), newEd25519Key(t, "2024-02")
+	oldKeys, _ := NewKeySet(oldKey)
+	rotated, _ := NewKeySet(newKey, oldKey)
+	dropped, _ := NewKeySet(newKey)
+
+	signed, _, err := NewJWTIssuer(oldKeys, "test", time.Minute, fixedClock(testNow)).Issue(&domain.User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJWTIssuer(rotated, "test", time.Minute, fixedClock(testNow)).Verify(signed); err != nil {
+		t.Errorf("Verify() with retired key error = %v", err)
+	}
+	if _, err := NewJWTIssuer(dropped, "test", time.Minute, fixedClock(testNow)).Verify(signed); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("Verify() after key removal error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+
+	signed, _, _ = NewJWTIssuer(rotated, "test", time.Minute, fixedClock(testNow)).Issue(&domain.User{ID: 1})
+	parsed, _, _ := jwt.NewParser().ParseUnverified(signed, &jwt.RegisteredClaims{})
+	if parsed.Header["kid"] != "2024-02" {
+		t.Errorf("Issue() kid = %v, want the active key", parsed.Header["kid"])
+	}
+}
+
+func TestJWTIssuer_Rejects(t *testing.T) {
+	key := newEd25519Key(t, "ed")
+	keys, _ := NewKeySet(key)
+	issuer := NewJWTIssuer(keys, "test", time.Minute, fixedClock(testNow))
+	signed, _, err := issuer.Issue(&domain.User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hmacSecret := []byte(key.verifyKey.(ed25519.PublicKey))
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    "test",
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(testNow.Add(time.Minute)),
+	})
+	confused.Header["kid"] = "ed"
+	confusedToken, _ := confused.SignedString(hmacSecret)
+
+	parts := strings.Split(signed, ".")
+	forgery := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{
+		Issuer:    "test",
+		Subject:   "2",
+		ExpiresAt: jwt.NewNumericDate(testNow.Add(time.Minute)),
+	})
+	forgery.Header["kid"] = "ed"
+	forged, _ := forgery.SigningString()
+	tampered := forged + "." + parts[2]
+
+	tests := []struct {
+		name   string
+		token  string
+		issuer domain.AccessTokenIssuer
+	}{
+		{"expired", signed, NewJWTIssuer(keys, "test", time.Minute, fixedClock(testNow.Add(2*time.Minute)))},
+		{"wrong issuer", signed, NewJWTIssuer(keys, "other", time.Minute, fixedClock(testNow))},
+		{"tampered", tampered, issuer},
+		{"garbage", "not.a.token", issuer},
+		{"algorithm confusion", confusedToken, issuer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.issuer.Verify(tt.token); !errors.Is(err, domain.ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, want %v", err, domain.ErrInvalidToken)
+			}
+		})
+	}
+}
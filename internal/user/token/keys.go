@@ -0,0 +1,106 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minHMACSecretLength = 32
+
+// Key is a signing key identified by the kid placed in token headers.
+type Key struct {
+	ID        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if len(secret) < minHMACSecretLength {
+		return Key{}, fmt.Errorf("HS256 secret must be at least %d bytes", minHMACSecretLength)
+	}
+	return Key{ID: id, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+func NewEd25519Key(id string, key ed25519.PrivateKey) Key {
+	return Key{ID: id, method: jwt.SigningMethodEdDSA, signKey: key, verifyKey: key.Public()}
+}
+
+func NewRSAKey(id string, key *rsa.PrivateKey) Key {
+	return Key{ID: id, method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}
+}
+
+// LoadKeyFile reads a PKCS #8 PEM encoded Ed25519 or RSA private key. The
+// file name without its extension becomes the key ID.
+func LoadKeyFile(path string) (Key, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParsePrivateKeyPEM(id, raw)
+}
+
+func ParsePrivateKeyPEM(id string, raw []byte) (Key, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return Key{}, err
+	}
+	switch key := parsed.(type) {
+	case ed25519.PrivateKey:
+		return NewEd25519Key(id, key), nil
+	case *rsa.PrivateKey:
+		return NewRSAKey(id, key), nil
+	default:
+		return Key{}, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
+
+// KeySet signs with its active key and verifies with any key it holds, so
+// that tokens signed before a rotation stay valid until they expire.
+type KeySet struct {
+	active Key
+	keys   map[string]Key
+}
+
+func NewKeySet(active Key, retired ...Key) (*KeySet, error) {
+	s := &KeySet{active: active, keys: make(map[string]Key)}
+	for _, key := range append([]Key{active}, retired...) {
+		if key.ID == "" {
+			return nil, errors.New("key ID is required")
+		}
+		if _, exists := s.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		s.keys[key.ID] = key
+	}
+	return s, nil
+}
+
+// lookup returns the verification key for a token header. The algorithm must
+// be the one the key was created for, so a public key can never be used as an
+// HMAC secret.
+func (s *KeySet) lookup(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("algorithm %s does not match key %q", t.Method.Alg(), kid)
+	}
+	return key.verifyKey, nil
+}
@@ -0,0 +1,54 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHMACKey_ShortSecret(t *testing.T) {
+	if _, err := NewHMACKey("hs", []byte("too short")); err == nil {
+		t.Errorf("NewHMACKey() accepted a short secret")
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	key := newEd25519Key(t, "a")
+	if _, err := NewKeySet(key, newEd25519Key(t, "a")); err == nil {
+		t.Errorf("NewKeySet() accepted duplicate key IDs")
+	}
+	if _, err := NewKeySet(newEd25519Key(t, "")); err == nil {
+		t.Errorf("NewKeySet() accepted an empty key ID")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "2024-01.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile() error = %v", err)
+	}
+	if key.ID != "2024-01" || key.method.Alg() != "EdDSA" {
+		t.Errorf("LoadKeyFile() = %s %s, want 2024-01 EdDSA", key.ID, key.method.Alg())
+	}
+
+	if _, err := ParsePrivateKeyPEM("x", []byte("not pem")); err == nil {
+		t.Errorf("ParsePrivateKeyPEM() accepted invalid input")
+	}
+}
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"repo-guardian/internal/domain"
+)
+
+type oneTimeSigner struct {
+	secret []byte
+	clock  domain.Clock
+}
+
+// NewOneTimeSigner returns a signer of one-time tokens authenticated with
+// HMAC-SHA256. It assigns each token a random ID, which callers use to make
+// the token single-use.
+func NewOneTimeSigner(secret []byte, clock domain.Clock) (domain.OneTimeTokenSigner, error) {
+	if len(secret) < minHMACSecretLength {
+		return nil, fmt.Errorf("one-time token secret must be at least %d bytes", minHMACSecretLength)
+	}
+	return &oneTimeSigner{secret: secret, clock: clock}, nil
+}
+
+func (s *oneTimeSigner) Sign(t *domain.OneTimeToken) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	t.ID = base64.RawURLEncoding.EncodeToString(id)
+
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+func (s *oneTimeSigner) Parse(purpose domain.TokenPurpose, signed string) (*domain.OneTimeToken, error) {
+	t, err := s.parse(signed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidToken, err)
+	}
+	if t.Purpose != purpose {
+		return nil, fmt.Errorf("%w: token is not for %s", domain.ErrInvalidToken, purpose)
+	}
+	if !t.ExpiresAt.After(s.clock.Now()) {
+		return nil, fmt.Errorf("%w: token has expired", domain.ErrInvalidToken)
+	}
+	return t, nil
+}
+
+func (s *oneTimeSigner) parse(signed string) (*domain.OneTimeToken, error) {
+	encoded, sig, ok := strings.Cut(signed, ".")
+	if !ok {
+		return nil, errors.New("malformed token")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return nil, errors.New("bad signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("malformed token")
+	}
+	var t domain.OneTimeToken
+	if err := json.Unmarshal(payload, &t); err != nil {
+		return nil, errors.New("malformed token")
+	}
+	return &t, nil
+}
+
+func (s *oneTimeSigner) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
+}
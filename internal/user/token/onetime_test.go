@@ -0,0 +1,71 @@
+package token
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestOneTimeSigner(t *testing.T) {
+	secret := []byte(strings.Repeat("k", 32))
+	signer, err := NewOneTimeSigner(secret, fixedClock(testNow))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := &domain.OneTimeToken{
+		Purpose:   domain.PurposeVerifyEmail,
+		UserID:    7,
+		Email:     "john@example.com",
+		ExpiresAt: testNow.Add(time.Hour),
+	}
+	signed, err := signer.Sign(token)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if token.ID == "" {
+		t.Errorf("Sign() did not assign a token ID")
+	}
+
+	got, err := signer.Parse(domain.PurposeVerifyEmail, signed)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got.ID != token.ID || got.UserID != 7 || got.Email != "john@example.com" || !got.ExpiresAt.Equal(token.ExpiresAt) {
+		t.Errorf("Parse() = %+v, want %+v", got, token)
+	}
+
+	other, _ := NewOneTimeSigner([]byte(strings.Repeat("x", 32)), fixedClock(testNow))
+	expired, _ := NewOneTimeSigner(secret, fixedClock(testNow.Add(2*time.Hour)))
+	payload, sig, _ := strings.Cut(signed, ".")
+
+	tests := []struct {
+		name    string
+		signer  domain.OneTimeTokenSigner
+		purpose domain.TokenPurpose
+		signed  string
+	}{
+		{"other purpose", signer, domain.PurposeResetPassword, signed},
+		{"other secret", other, domain.PurposeVerifyEmail, signed},
+		{"expired", expired, domain.PurposeVerifyEmail, signed},
+		{"tampered payload", signer, domain.PurposeVerifyEmail, "e30." + sig},
+		{"tampered signature", signer, domain.PurposeVerifyEmail, payload + ".AAAA"},
+		{"malformed", signer, domain.PurposeVerifyEmail, "garbage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.signer.Parse(tt.purpose, tt.signed); !errors.Is(err, domain.ErrInvalidToken) {
+				t.Errorf("Parse() error = %v, want %v", err, domain.ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestNewOneTimeSigner_ShortSecret(t *testing.T) {
+	if _, err := NewOneTimeSigner([]byte("short"), fixedClock(testNow)); err == nil {
+		t.Errorf("NewOneTimeSigner() accepted a short secret")
+	}
+}
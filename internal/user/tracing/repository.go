@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type userRepository struct {
+	next   domain.UserRepository
+	tracer trace.Tracer
+}
+
+// NewUserRepository traces every call to next as a child of the span in its
+// context.
+func NewUserRepository(next domain.UserRepository, tp trace.TracerProvider) domain.UserRepository {
+	return &userRepository{next: next, tracer: tp.Tracer(ScopeName)}
+}
+
+func (r *userRepository) Create(c context.Context, user *domain.User) error {
+	ctx, span := r.tracer.Start(c, "UserRepository.Create")
+	err := r.next.Create(ctx, user)
+	setUser(span, user)
+	end(span, err)
+	return err
+}
+
+func (r *userRepository) GetByID(c context.Context, id int64) (*domain.User, error) {
+	ctx, span := r.tracer.Start(c, "UserRepository.GetByID", userID(id))
+	user, err := r.next.GetByID(ctx, id)
+	end(span, err)
+	return user, err
+}
+
+func (r *userRepository) GetByUsername(c context.Context, username string) (*domain.User, error) {
+	ctx, span := r.tracer.Start(c, "UserRepository.GetByUsername")
+	user, err := r.next.GetByUsername(ctx, username)
+	setUser(span, user)
+	end(span, err)
+	return user, err
+}
+
+func (r *userRepository) Update(c context.Context, user *domain.User) error {
+	ctx, span := r.tracer.Start(c, "UserRepository.Update", userID(user.ID))
+	err := r.next.Update(ctx, user)
+	end(span, err)
+	return err
+}
+
+func (r *userRepository) Delete(c context.Context, id int64, at time.Time) error {
+	ctx, span := r.tracer.Start(c, "UserRepository.Delete", userID(id))
+	err := r.next.Delete(ctx, id, at)
+	end(span, err)
+	return err
+}
+
+func (r *userRepository) List(c context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	ctx, span := r.tracer.Start(c, "UserRepository.List")
+	page, err := r.next.List(ctx, opts)
+	end(span, err)
+	return page, err
+}
+
+func (r *userRepository) Restore(c context.Context, id int64) (*domain.User, error) {
+	ctx, span := r.tracer.Start(c, "UserRepository.Restore", userID(id))
+	user, err := r.next.Restore(ctx, id)
+	end(span, err)
+	return user, err
+}
+
+func (r *userRepository) VerifyEmail(c context.Context, id int64, email string, at time.Time) error {
+	ctx, span := r.tracer.Start(c, "UserRepository.VerifyEmail", userID(id))
+	err := r.next.VerifyEmail(ctx, id, email, at)
+	end(span, err)
+	return err
+}
+
+func (r *userRepository) Purge(c context.Context, cutoff time.Time) (int, error) {
+	ctx, span := r.tracer.Start(c, "UserRepository.Purge")
+	n, err := r.next.Purge(ctx, cutoff)
+	end(span, err)
+	return n, err
+}
+
+func (r *userRepository) Anonymize(c context.Context, id int64, at time.Time) (*domain.User, error) {
+	ctx, span := r.tracer.Start(c, "UserRepository.Anonymize", userID(id))
+	user, err := r.next.Anonymize(ctx, id, at)
+	end(span, err)
+	return user, err
+}
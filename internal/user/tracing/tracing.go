@@ -0,0 +1,35 @@
+// Package tracing traces the user service with OpenTelemetry.
+package tracing
+
+import (
+	"repo-guardian/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName names the tracer of the user service's spans.
+const ScopeName = "repo-guardian/internal/user/tracing"
+
+var userIDKey = attribute.Key("user.id")
+
+// end ends span, recording err if the operation failed.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func userID(id int64) trace.SpanStartOption {
+	return trace.WithAttributes(userIDKey.Int64(id))
+}
+
+// setUser records the ID of user, if any, once an operation assigned it.
+func setUser(span trace.Span, user *domain.User) {
+	if user != nil && user.ID != 0 {
+		span.SetAttributes(userIDKey.Int64(user.ID))
+	}
+}
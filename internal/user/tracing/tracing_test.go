@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/audit"
+	"repo-guardian/internal/user/handler"
+	"repo-guardian/internal/user/repository"
+	"repo-guardian/internal/user/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanTree renders spans as indented names, children under their parents,
+// in the order they started.
+func spanTree(spans tracetest.SpanStubs) string {
+	children := make(map[string][]tracetest.SpanStub)
+	for _, s := range spans {
+		parent := ""
+		if s.Parent.IsValid() && !s.Parent.IsRemote() {
+			parent = s.Parent.SpanID().String()
+		}
+		children[parent] = append(children[parent], s)
+	}
+	var b strings.Builder
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		kids := children[parent]
+		// Spans are exported as they end, children first.
+		slices.SortFunc(kids, func(a, b tracetest.SpanStub) int { return a.StartTime.Compare(b.StartTime) })
+		for _, s := range kids {
+			b.WriteString(strings.Repeat("  ", depth) + s.Name + "\n")
+			walk(s.SpanContext.SpanID().String(), depth+1)
+		}
+	}
+	walk("", 0)
+	return b.String()
+}
+
+func TestSpanTree(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	repo := NewUserRepository(repository.NewMemoryUserRepository(), tp)
+	app := fiber.New()
+	app.Use(handler.Tracing(tp, propagation.TraceContext{}))
+	handler.NewUserHandler(app, NewUserUsecase(usecase.NewUserUsecase(repo, time.Second, usecase.WithAudit(audit.NewMemorySink())), tp))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"id":7,"username":"john","email":"john@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	assert.Equal(t, "POST /users\n"+
+		"  UserUsecase.Register\n"+
+		"    UserRepository.Create\n", spanTree(exporter.GetSpans()))
+
+	exporter.Reset()
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/users/8", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, "GET /users/:id\n"+
+		"  UserUsecase.GetUser\n"+
+		"    UserRepository.GetByID\n", spanTree(spans))
+	repoSpan := spans[0]
+	assert.Contains(t, repoSpan.Attributes, attribute.Int64("user.id", 8))
+	assert.Equal(t, codes.Error, repoSpan.Status.Code)
+	assert.Equal(t, domain.ErrNotFound.Error(), repoSpan.Status.Description)
+	assert.Equal(t, "exception", repoSpan.Events[0].Name)
+
+	exporter.Reset()
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/users/7", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "DELETE /users/:id\n"+
+		"  UserUsecase.DeleteUser\n"+
+		"    UserRepository.GetByID\n"+
+		"    UserRepository.Delete\n"+
+		"    UserRepository.GetByID\n", spanTree(exporter.GetSpans()))
+}
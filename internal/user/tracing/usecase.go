@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+
+	"repo-guardian/internal/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type userUsecase struct {
+	next   domain.UserUsecase
+	tracer trace.Tracer
+}
+
+// NewUserUsecase traces every call to next as a child of the span in its
+// context, typically the request's.
+func NewUserUsecase(next domain.UserUsecase, tp trace.TracerProvider) domain.UserUsecase {
+	return &userUsecase{next: next, tracer: tp.Tracer(ScopeName)}
+}
+
+func (u *userUsecase) Register(c context.Context, user *domain.User) error {
+	ctx, span := u.tracer.Start(c, "UserUsecase.Register")
+	err := u.next.Register(ctx, user)
+	setUser(span, user)
+	end(span, err)
+	return err
+}
+
+func (u *userUsecase) GetUser(c context.Context, id int64) (*domain.User, error) {
+	ctx, span := u.tracer.Start(c, "UserUsecase.GetUser", userID(id))
+	user, err := u.next.GetUser(ctx, id)
+	end(span, err)
+	return user, err
+}
+
+func (u *userUsecase) UpdateUser(c context.Context, user *domain.User) error {
+	ctx, span := u.tracer.Start(c, "UserUsecase.UpdateUser", userID(user.ID))
+	err := u.next.UpdateUser(ctx, user)
+	end(span, err)
+	return err
+}
+
+func (u *userUsecase) PatchUser(c context.Context, id int64, patch domain.UserPatch) (*domain.User, error) {
+	ctx, span := u.tracer.Start(c, "UserUsecase.PatchUser", userID(id))
+	user, err := u.next.PatchUser(ctx, id, patch)
+	end(span, err)
+	return user, err
+}
+
+func (u *userUsecase) DeleteUser(c context.Context, id int64) error {
+	ctx, span := u.tracer.Start(c, "UserUsecase.DeleteUser", userID(id))
+	err := u.next.DeleteUser(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (u *userUsecase) ListUsers(c context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	ctx, span := u.tracer.Start(c, "UserUsecase.ListUsers")
+	page, err := u.next.ListUsers(ctx, opts)
+	end(span, err)
+	return page, err
+}
+
+func (u *userUsecase) RestoreUser(c context.Context, id int64) (*domain.User, error) {
+	ctx, span := u.tracer.Start(c, "UserUsecase.RestoreUser", userID(id))
+	user, err := u.next.RestoreUser(ctx, id)
+	end(span, err)
+	return user, err
+}
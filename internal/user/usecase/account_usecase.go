@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// AccountDeps are the collaborators of the account usecase.
+type AccountDeps struct {
+	Users         domain.UserRepository
+	Credentials   domain.CredentialRepository
+	RefreshTokens domain.RefreshTokenRepository
+	Hasher        domain.PasswordHasher
+	Policy        domain.PasswordPolicy
+	Tokens        domain.OneTimeTokenSigner
+	UsedTokens    domain.OneTimeTokenRepository
+	Mailer        domain.Mailer
+	Authorizer    domain.Authorizer
+}
+
+type accountUsecase struct {
+	AccountDeps
+	// baseURL is where the links in mails point; the token is appended as a
+	// query parameter.
+	baseURL        string
+	contextTimeout time.Duration
+	clock          domain.Clock
+}
+
+func NewAccountUsecase(d AccountDeps, baseURL string, timeout time.Duration) domain.AccountUsecase {
+	return &accountUsecase{
+		AccountDeps:    d,
+		baseURL:        baseURL,
+		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+	}
+}
+
+func (a *accountUsecase) RequestEmailVerification(c context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	user, err := a.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, a.Authorizer, domain.ActionUpdate, user); err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	link, err := a.link("/verify-email", &domain.OneTimeToken{
+		Purpose:   domain.PurposeVerifyEmail,
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: a.clock.Now().Add(verifyEmailTTL),
+	})
+	if err != nil {
+		return err
+	}
+	return a.Mailer.Send(ctx, domain.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nOpen this link to verify your email address:\n\n%s\n\nThe link expires in 24 hours.\n", user.Username, link),
+	})
+}
+
+func (a *accountUsecase) VerifyEmail(c context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	t, err := a.Tokens.Parse(domain.PurposeVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+	if err := a.UsedTokens.Consume(ctx, t.ID, t.ExpiresAt); err != nil {
+		return err
+	}
+	err = a.Users.VerifyEmail(ctx, t.UserID, t.Email, a.clock.Now())
+	if errors.Is(err, domain.ErrConflict) || errors.Is(err, domain.ErrNotFound) {
+		// The address changed or the user is gone since the mail was sent.
+		return fmt.Errorf("%w: email address has changed", domain.ErrInvalidToken)
+	}
+	return err
+}
+
+func (a *accountUsecase) RequestPasswordReset(c context.Context, username string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	user, err := a.Users.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.EmailVerified() {
+		// An unverified address may not belong to the user, so it must not
+		// receive a way into the account.
+		slog.InfoContext(ctx, "password reset refused", "user_id", user.ID, "error", domain.ErrEmailNotVerified)
+		return nil
+	}
+
+	link, err := a.link("/reset-password", &domain.OneTimeToken{
+		Purpose:   domain.PurposeResetPassword,
+		UserID:    user.ID,
+		ExpiresAt: a.clock.Now().Add(resetPasswordTTL),
+	})
+	if err != nil {
+		return err
+	}
+	err = a.Mailer.Send(ctx, domain.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Hi %s,\n\nOpen this link to choose a new password:\n\n%s\n\nThe link expires in one hour. If you did not ask for it, ignore this mail.\n", user.Username, link),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "send password reset failed", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+func (a *accountUsecase) ResetPassword(c context.Context, token, password string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	t, err := a.Tokens.Parse(domain.PurposeResetPassword, token)
+	if err != nil {
+		return err
+	}
+	// Check the password before consuming the token, so that a rejected
+	// password can be retried with the same link.
+	if err := a.Policy.Check(password); err != nil {
+		return err
+	}
+	hash, err := a.Hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if _, err := a.Users.GetByID(ctx, t.UserID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+	if err := a.UsedTokens.Consume(ctx, t.ID, t.ExpiresAt); err != nil {
+		return err
+	}
+	if err := a.Credentials.Set(ctx, &domain.Credential{UserID: t.UserID, PasswordHash: hash, UpdatedAt: a.clock.Now()}); err != nil {
+		return err
+	}
+	// Whoever made the reset necessary may hold a session; end them all.
+	return a.RefreshTokens.RevokeUser(ctx, t.UserID)
+}
+
+func (a *accountUsecase) link(path string, t *domain.OneTimeToken) (string, error) {
+	signed, err := a.Tokens.Sign(t)
+	if err != nil {
+		return "", err
+	}
+	return a.baseURL + path + "?token=" + url.QueryEscape(signed), nil
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/authz"
+	"repo-guardian/internal/user/token"
+)
+
+type recordingMailer struct {
+	sent []domain.Message
+	err  error
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg domain.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// token extracts the token from the link in the last mail.
+func (m *recordingMailer) token(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no mail was sent")
+	}
+	link := regexp.MustCompile(`https?://\S+`).FindString(m.sent[len(m.sent)-1].Body)
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("token")
+}
+
+type fakeOneTimeTokenRepository map[string]bool
+
+func (r fakeOneTimeTokenRepository) Consume(ctx context.Context, id string, expiresAt time.Time) error {
+	if r[id] {
+		return domain.ErrTokenReused
+	}
+	r[id] = true
+	return nil
+}
+
+type accountFixture struct {
+	usecase *accountUsecase
+	users   map[int64]*domain.User
+	creds   *mockCredentialRepository
+	mailer  *recordingMailer
+	tokens  *tokenUsecase
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	f := &accountFixture{
+		users: map[int64]*domain.User{
+			1: {ID: 1, Username: "john", Email: "john@example.com"},
+		},
+		creds:  &mockCredentialRepository{creds: map[int64]domain.Credential{}},
+		mailer: &recordingMailer{},
+	}
+	repo := &mockUserRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if user, ok := f.users[id]; ok {
+				return user, nil
+			}
+			return nil, domain.ErrNotFound
+		},
+		getByNameFunc: func(ctx context.Context, username string) (*domain.User, error) {
+			for _, user := range f.users {
+				if user.Username == username {
+					return user, nil
+				}
+			}
+			return nil, domain.ErrNotFound
+		},
+		verifyEmailFunc: func(ctx context.Context, id int64, email string, at time.Time) error {
+			user, ok := f.users[id]
+			if !ok {
+				return domain.ErrNotFound
+			}
+			if user.Email != email {
+				return domain.ErrConflict
+			}
+			user.EmailVerifiedAt = &at
+			return nil
+		},
+	}
+	signer, err := token.NewOneTimeSigner([]byte(strings.Repeat("k", 32)), fixedClock(testNow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refresh *fakeRefreshTokenRepository
+	f.tokens, refresh = newTestTokenUsecase(f.users)
+	f.usecase = &accountUsecase{
+		AccountDeps: AccountDeps{
+			Users:         repo,
+			Credentials:   f.creds,
+			RefreshTokens: refresh,
+			Hasher:        prefixHasher{cost: "v1"},
+			Policy:        minLengthPolicy(8),
+			Tokens:        signer,
+			UsedTokens:    fakeOneTimeTokenRepository{},
+			Mailer:        f.mailer,
+			Authorizer:    authz.NewPolicy(authz.DefaultRules...),
+		},
+		baseURL:        "https://app.example.com",
+		contextTimeout: time.Second,
+		clock:          fixedClock(testNow),
+	}
+	return f
+}
+
+func TestAccountUsecase_VerifyEmail(t *testing.T) {
+	f := newAccountFixture(t)
+	john := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+
+	if err := f.usecase.RequestEmailVerification(john, 1); err != nil {
+		t.Fatalf("RequestEmailVerification() error = %v", err)
+	}
+	if msg := f.mailer.sent[0]; msg.To != "john@example.com" || !strings.Contains(msg.Body, "https://app.example.com/verify-email?token=") {
+		t.Errorf("RequestEmailVerification() sent %+v", msg)
+	}
+	tok := f.mailer.token(t)
+
+	if err := f.usecase.VerifyEmail(context.Background(), tok); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if !f.users[1].EmailVerified() || !f.users[1].EmailVerifiedAt.Equal(testNow) {
+		t.Errorf("VerifyEmail() did not mark the email as verified")
+	}
+	if err := f.usecase.VerifyEmail(context.Background(), tok); !errors.Is(err, domain.ErrTokenReused) {
+		t.Errorf("VerifyEmail() twice error = %v, want %v", err, domain.ErrTokenReused)
+	}
+
+	// Already verified addresses get no further mail.
+	if err := f.usecase.RequestEmailVerification(john, 1); err != nil || len(f.mailer.sent) != 1 {
+		t.Errorf("RequestEmailVerification() of a verified address = %v, sent %d mails", err, len(f.mailer.sent))
+	}
+}
+
+func TestAccountUsecase_VerifyEmail_AddressChanged(t *testing.T) {
+	f := newAccountFixture(t)
+	john := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+
+	if err := f.usecase.RequestEmailVerification(john, 1); err != nil {
+		t.Fatal(err)
+	}
+	f.users[1].Email = "new@example.com"
+
+	if err := f.usecase.VerifyEmail(context.Background(), f.mailer.token(t)); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("VerifyEmail() error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
+
+func TestAccountUsecase_RequestEmailVerification_Authorization(t *testing.T) {
+	f := newAccountFixture(t)
+	other := domain.WithPrincipal(context.Background(), domain.UserPrincipal(2, domain.RoleUser))
+
+	if err := f.usecase.RequestEmailVerification(other, 1); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("RequestEmailVerification() for another user error = %v, want %v", err, domain.ErrForbidden)
+	}
+	if len(f.mailer.sent) != 0 {
+		t.Errorf("a denied request sent mail")
+	}
+}
+
+func TestAccountUsecase_ResetPassword(t *testing.T) {
+	f := newAccountFixture(t)
+	verified := testNow
+	f.users[1].EmailVerifiedAt = &verified
+	ctx := context.Background()
+
+	if err := f.usecase.RequestPasswordReset(ctx, "john"); err != nil {
+		t.Fatalf("RequestPasswordReset() error = %v", err)
+	}
+	tok := f.mailer.token(t)
+
+	if err := f.usecase.ResetPassword(ctx, tok, "short"); !errors.Is(err, domain.ErrWeakPassword) {
+		t.Errorf("ResetPassword() with weak password error = %v, want %v", err, domain.ErrWeakPassword)
+	}
+	if err := f.usecase.ResetPassword(ctx, tok, "correct horse"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	want := domain.Credential{UserID: 1, PasswordHash: "v1:correct horse", UpdatedAt: testNow}
+	if f.creds.creds[1] != want {
+		t.Errorf("ResetPassword() stored %+v, want %+v", f.creds.creds[1], want)
+	}
+	if err := f.usecase.ResetPassword(ctx, tok, "another password"); !errors.Is(err, domain.ErrTokenReused) {
+		t.Errorf("ResetPassword() twice error = %v, want %v", err, domain.ErrTokenReused)
+	}
+}
+
+func TestAccountUsecase_ResetPassword_EndsSessions(t *testing.T) {
+	f := newAccountFixture(t)
+	verified := testNow
+	f.users[1].EmailVerifiedAt = &verified
+	ctx := context.Background()
+	session, err := f.tokens.Issue(ctx, f.users[1])
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	if err := f.usecase.RequestPasswordReset(ctx, "john"); err != nil {
+		t.Fatalf("RequestPasswordReset() error = %v", err)
+	}
+	if err := f.usecase.ResetPassword(ctx, f.mailer.token(t), "correct horse"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if _, err := f.tokens.Refresh(ctx, session.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("Refresh() with a token issued before the reset error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
+
+func TestAccountUsecase_RequestPasswordReset_NoMail(t *testing.T) {
+	f := newAccountFixture(t)
+	ctx := context.Background()
+
+	if err := f.usecase.RequestPasswordReset(ctx, "nobody"); err != nil {
+		t.Errorf("RequestPasswordReset() of an unknown user error = %v", err)
+	}
+	if err := f.usecase.RequestPasswordReset(ctx, "john"); err != nil {
+		t.Errorf("RequestPasswordReset() of an unverified user error = %v", err)
+	}
+	if len(f.mailer.sent) != 0 {
+		t.Errorf("RequestPasswordReset() sent %d mails, want none", len(f.mailer.sent))
+	}
+
+	verified := testNow
+	f.users[1].EmailVerifiedAt = &verified
+	f.mailer.err = errors.New("smtp down")
+	if err := f.usecase.RequestPasswordReset(ctx, "john"); err != nil {
+		t.Errorf("RequestPasswordReset() with a failing mailer error = %v", err)
+	}
+}
+
+func TestAccountUsecase_TokensArePurposeBound(t *testing.T) {
+	f := newAccountFixture(t)
+	john := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+
+	if err := f.usecase.RequestEmailVerification(john, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.usecase.ResetPassword(context.Background(), f.mailer.token(t), "correct horse"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("ResetPassword() with a verification token error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
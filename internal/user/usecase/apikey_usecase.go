@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+const (
+	apiKeyPrefix = "rg"
+	// lastUsedGranularity bounds how often authenticating with a key writes
+	// its last-used time.
+	lastUsedGranularity = time.Minute
+)
+
+type apiKeyUsecase struct {
+	keyRepo        domain.APIKeyRepository
+	authorizer     domain.Authorizer
+	contextTimeout time.Duration
+	clock          domain.Clock
+}
+
+func NewAPIKeyUsecase(r domain.APIKeyRepository, authorizer domain.Authorizer, timeout time.Duration) domain.APIKeyUsecase {
+	return &apiKeyUsecase{
+		keyRepo:        r,
+		authorizer:     authorizer,
+		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+	}
+}
+
+func (a *apiKeyUsecase) Create(c context.Context, key *domain.APIKey) (string, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err := authorize(ctx, a.authorizer, domain.ActionManageKeys, nil); err != nil {
+		return "", err
+	}
+	if key.Role == "" {
+		key.Role = domain.RoleService
+	}
+	if err := key.Validate(); err != nil {
+		return "", err
+	}
+
+	id, err := randomHex(6)
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	key.ID = id
+	key.Hash = hashToken(secret)
+	key.CreatedAt = a.clock.Now()
+	key.CreatedBy = domain.Actor(ctx)
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	if err := a.keyRepo.Create(ctx, key); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + "_" + id + "_" + secret, nil
+}
+
+func (a *apiKeyUsecase) List(c context.Context) ([]*domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err := authorize(ctx, a.authorizer, domain.ActionManageKeys, nil); err != nil {
+		return nil, err
+	}
+	return a.keyRepo.List(ctx)
+}
+
+func (a *apiKeyUsecase) Revoke(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err := authorize(ctx, a.authorizer, domain.ActionManageKeys, nil); err != nil {
+		return err
+	}
+	return a.keyRepo.Revoke(ctx, id, a.clock.Now())
+}
+
+func (a *apiKeyUsecase) Authenticate(c context.Context, secret string) (domain.Principal, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	prefix, rest, ok := strings.Cut(secret, "_")
+	id, raw, ok2 := strings.Cut(rest, "_")
+	if !ok || !ok2 || prefix != apiKeyPrefix {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	key, err := a.keyRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(raw)), []byte(key.Hash)) != 1 || key.RevokedAt != nil {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	now := a.clock.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedGranularity {
+		if err := a.keyRepo.Touch(ctx, key.ID, now); err != nil {
+			slog.WarnContext(ctx, "record use of api key failed", "key_id", key.ID, "error", err)
+		}
+	}
+	return key.Principal(), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
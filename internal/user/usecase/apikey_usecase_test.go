@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/authz"
+)
+
+type fakeAPIKeyRepository struct {
+	keys    map[string]*domain.APIKey
+	touches int
+}
+
+func (r *fakeAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *fakeAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	key, ok := r.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &at
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	r.touches++
+	r.keys[id].LastUsedAt = &at
+	return nil
+}
+
+func newTestAPIKeyUsecase() (*apiKeyUsecase, *fakeAPIKeyRepository) {
+	repo := &fakeAPIKeyRepository{keys: make(map[string]*domain.APIKey)}
+	return &apiKeyUsecase{
+		keyRepo:        repo,
+		authorizer:     authz.NewPolicy(authz.DefaultRules...),
+		contextTimeout: time.Second,
+		clock:          fixedClock(testNow),
+	}, repo
+}
+
+func TestAPIKeyUsecase_CreateAndAuthenticate(t *testing.T) {
+	a, repo := newTestAPIKeyUsecase()
+	admin := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleAdmin))
+
+	key := &domain.APIKey{Name: "billing", Scopes: []domain.Scope{domain.ScopeUsersRead}}
+	secret, err := a.Create(admin, key)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, "rg_"+key.ID+"_") {
+		t.Errorf("Create() secret = %q, want it prefixed with the key ID", secret)
+	}
+	stored := repo.keys[key.ID]
+	if stored.Role != domain.RoleService || stored.CreatedBy != "user:1" || !stored.CreatedAt.Equal(testNow) {
+		t.Errorf("Create() stored %+v", stored)
+	}
+	if strings.Contains(secret, stored.Hash) {
+		t.Errorf("Create() stored the secret instead of its hash")
+	}
+
+	p, err := a.Authenticate(context.Background(), secret)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	want := domain.Principal{Subject: "apikey:" + key.ID, Role: domain.RoleService, Scopes: []domain.Scope{domain.ScopeUsersRead}}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Authenticate() = %+v, want %+v", p, want)
+	}
+	if stored := repo.keys[key.ID]; stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(testNow) {
+		t.Errorf("Authenticate() did not record the last use")
+	}
+
+	// Uses within the same minute are not written again.
+	_, _ = a.Authenticate(context.Background(), secret)
+	if repo.touches != 1 {
+		t.Errorf("Authenticate() recorded %d uses, want 1", repo.touches)
+	}
+
+	if err := a.Revoke(admin, key.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := a.Authenticate(context.Background(), secret); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() with revoked key error = %v, want %v", err, domain.ErrInvalidAPIKey)
+	}
+}
+
+func TestAPIKeyUsecase_Authenticate_Invalid(t *testing.T) {
+	a, _ := newTestAPIKeyUsecase()
+	admin := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleAdmin))
+	key := &domain.APIKey{Name: "billing", Scopes: []domain.Scope{domain.ScopeUsersRead}}
+	secret, err := a.Create(admin, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, candidate := range []string{"", "rg_", "xx_" + key.ID + "_abc", "rg_missing_abc", "rg_" + key.ID + "_wrong", secret + "x"} {
+		if _, err := a.Authenticate(context.Background(), candidate); !errors.Is(err, domain.ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) error = %v, want %v", candidate, err, domain.ErrInvalidAPIKey)
+		}
+	}
+}
+
+func TestAPIKeyUsecase_Authorization(t *testing.T) {
+	a, _ := newTestAPIKeyUsecase()
+	user := domain.WithPrincipal(context.Background(), domain.UserPrincipal(2, domain.RoleUser))
+
+	if _, err := a.Create(user, &domain.APIKey{Name: "mine", Scopes: []domain.Scope{domain.ScopeUsersAdmin}}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Create() by a user error = %v, want %v", err, domain.ErrForbidden)
+	}
+	if _, err := a.List(context.Background()); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("List() anonymously error = %v, want %v", err, domain.ErrUnauthenticated)
+	}
+	if err := a.Revoke(user, "any"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Revoke() by a user error = %v, want %v", err, domain.ErrForbidden)
+	}
+}
+
+func TestAPIKeyUsecase_Create_Invalid(t *testing.T) {
+	a, _ := newTestAPIKeyUsecase()
+	admin := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleAdmin))
+
+	for _, key := range []*domain.APIKey{
+		{Scopes: []domain.Scope{domain.ScopeUsersRead}},
+		{Name: "no scopes"},
+		{Name: "bad scope", Scopes: []domain.Scope{"users:everything"}},
+		{Name: "user role", Role: domain.RoleUser, Scopes: []domain.Scope{domain.ScopeUsersRead}},
+	} {
+		if _, err := a.Create(admin, key); !errors.Is(err, domain.ErrBadParamInput) {
+			t.Errorf("Create(%+v) error = %v, want %v", key, err, domain.ErrBadParamInput)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type auditUsecase struct {
+	sink           domain.AuditSink
+	authorizer     domain.Authorizer
+	contextTimeout time.Duration
+}
+
+func NewAuditUsecase(sink domain.AuditSink, authorizer domain.Authorizer, timeout time.Duration) domain.AuditUsecase {
+	return &auditUsecase{
+		sink:           sink,
+		authorizer:     authorizer,
+		contextTimeout: timeout,
+	}
+}
+
+func (a *auditUsecase) Query(c context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err := authorize(ctx, a.authorizer, domain.ActionReadAudit, nil); err != nil {
+		return nil, err
+	}
+	if err := filter.Normalize(); err != nil {
+		return nil, err
+	}
+	return a.sink.Query(ctx, filter)
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/audit"
+	"repo-guardian/internal/user/authz"
+	"repo-guardian/internal/user/repository"
+)
+
+func TestUserUsecase_Audit(t *testing.T) {
+	sink := audit.NewMemorySink()
+	creds := &mockCredentialRepository{creds: map[int64]domain.Credential{}}
+	a := NewUserUsecase(repository.NewMemoryUserRepository(), time.Second,
+		WithClock(fixedClock(testNow)),
+		WithCredentials(creds, prefixHasher{cost: "v1"}, minLengthPolicy(8)),
+		WithAudit(sink))
+	ctx := domain.WithRequestID(domain.WithPrincipal(context.Background(), domain.UserPrincipal(9, domain.RoleAdmin)), "req-1")
+
+	if err := a.Register(ctx, &domain.User{ID: 42, Username: "john", Email: "john@example.com", Password: "correct horse"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UpdateUser(ctx, &domain.User{ID: 42, Username: "john", Email: "john@example.org"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.PatchUser(ctx, 42, patchFunc(func(u *domain.User) error { u.Username = "johnny"; return nil })); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UpdateUser(ctx, &domain.User{ID: 42, Username: "bad"}); err == nil {
+		t.Fatal("UpdateUser() with an invalid user succeeded")
+	}
+	if err := a.DeleteUser(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RestoreUser(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := sink.Query(context.Background(), domain.AuditFilter{Target: "user:42", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantActions := []domain.Action{domain.ActionCreate, domain.ActionUpdate, domain.ActionUpdate, domain.ActionDelete, domain.ActionRestore}
+	if len(events) != len(wantActions) {
+		t.Fatalf("recorded %d events, want %d: %+v", len(events), len(wantActions), events)
+	}
+	for i, event := range events {
+		if event.Action != wantActions[i] || event.Actor != "user:9" || event.RequestID != "req-1" || !event.Time.Equal(testNow) {
+			t.Errorf("event %d = %+v, want %s by user:9 in req-1", i, event, wantActions[i])
+		}
+	}
+
+	created := events[0].Changes
+	if created["username"] != (domain.Change{After: "john"}) || created["role"] != (domain.Change{After: "user"}) {
+		t.Errorf("create changes = %+v", created)
+	}
+	if created["password"] != (domain.Change{After: domain.Redacted}) {
+		t.Errorf("create recorded password as %+v, want it redacted", created["password"])
+	}
+	if got := events[1].Changes; len(got) != 1 || got["email"] != (domain.Change{Before: "john@example.com", After: "john@example.org"}) {
+		t.Errorf("update changes = %+v, want only the email", got)
+	}
+	if got := events[2].Changes; len(got) != 1 || got["username"] != (domain.Change{Before: "john", After: "johnny"}) {
+		t.Errorf("patch changes = %+v, want only the username", got)
+	}
+	if got := events[3].Changes["deleted_at"]; got.Before != nil || got.After == nil {
+		t.Errorf("delete changes = %+v, want deleted_at set", events[3].Changes)
+	}
+}
+
+// secondsRepository keeps deletion times to the second, as some databases do.
+type secondsRepository struct {
+	domain.UserRepository
+}
+
+func (r secondsRepository) Delete(ctx context.Context, id int64, at time.Time) error {
+	return r.UserRepository.Delete(ctx, id, at.Truncate(time.Second))
+}
+
+func TestUserUsecase_AuditDeleteRestore(t *testing.T) {
+	sink := audit.NewMemorySink()
+	repo := secondsRepository{repository.NewMemoryUserRepository()}
+	a := NewUserUsecase(repo, time.Second,
+		WithClock(fixedClock(testNow.Add(500*time.Millisecond))),
+		WithAuthorizer(authz.NewPolicy(authz.DefaultRules...)),
+		WithAudit(sink))
+	ctx := domain.WithPrincipal(context.Background(), domain.UserPrincipal(9, domain.RoleAdmin))
+
+	if err := repo.Create(ctx, &domain.User{ID: 42, Username: "john", Email: "john@example.com", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteUser(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RestoreUser(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := sink.Query(context.Background(), domain.AuditFilter{Target: "user:42", Limit: 10})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Query() = %+v, %v, want the delete and the restore", events, err)
+	}
+	stored := testNow.Format(time.RFC3339Nano)
+	if got := events[0].Changes["deleted_at"]; got != (domain.Change{After: stored}) {
+		t.Errorf("delete recorded deleted_at as %+v, want the stored %s", got, stored)
+	}
+	if got := events[1].Changes["deleted_at"]; got != (domain.Change{Before: stored}) {
+		t.Errorf("restore recorded deleted_at as %+v, want it cleared from %s", got, stored)
+	}
+}
+
+func TestAuditUsecase_Query(t *testing.T) {
+	sink := audit.NewMemorySink()
+	sink.Append(context.Background(), &domain.AuditEvent{Action: domain.ActionDelete, Target: "user:42"})
+	a := NewAuditUsecase(sink, authz.NewPolicy(authz.DefaultRules...), time.Second)
+
+	admin := domain.WithPrincipal(context.Background(), domain.UserPrincipal(9, domain.RoleAdmin))
+	events, err := a.Query(admin, domain.AuditFilter{Target: "user:42"})
+	if err != nil || len(events) != 1 {
+		t.Errorf("Query() by an admin = %+v, %v", events, err)
+	}
+
+	user := domain.WithPrincipal(context.Background(), domain.UserPrincipal(42, domain.RoleUser))
+	if _, err := a.Query(user, domain.AuditFilter{Target: "user:42"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Query() by a user error = %v, want %v", err, domain.ErrForbidden)
+	}
+	if _, err := a.Query(admin, domain.AuditFilter{Limit: domain.MaxListLimit + 1}); !errors.Is(err, domain.ErrBadParamInput) {
+		t.Errorf("Query() with a too large limit error = %v, want %v", err, domain.ErrBadParamInput)
+	}
+}
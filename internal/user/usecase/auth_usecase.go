@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type authUsecase struct {
+	userRepo       domain.UserRepository
+	credRepo       domain.CredentialRepository
+	hasher         domain.PasswordHasher
+	contextTimeout time.Duration
+	clock          domain.Clock
+	// dummyHash is verified against when the username is unknown, so that
+	// response times do not reveal which usernames exist.
+	dummyHash string
+	mfa       domain.MFAUsecase
+	mfaTokens domain.OneTimeTokenSigner
+	usedMFA   domain.OneTimeTokenRepository
+	lockout   domain.LoginThrottle
+}
+
+const mfaLoginTTL = 5 * time.Minute
+
+type AuthOption func(*authUsecase)
+
+// WithMFA makes Login require a second factor from users who enabled one.
+func WithMFA(mfa domain.MFAUsecase, tokens domain.OneTimeTokenSigner, used domain.OneTimeTokenRepository) AuthOption {
+	return func(a *authUsecase) {
+		a.mfa = mfa
+		a.mfaTokens = tokens
+		a.usedMFA = used
+	}
+}
+
+// WithLockout locks out usernames, and users entering second factor codes,
+// after repeated failures.
+func WithLockout(l domain.LoginThrottle) AuthOption {
+	return func(a *authUsecase) {
+		a.lockout = l
+	}
+}
+
+func NewAuthUsecase(u domain.UserRepository, c domain.CredentialRepository, h domain.PasswordHasher, timeout time.Duration, opts ...AuthOption) (domain.AuthUsecase, error) {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	dummyHash, err := h.Hash(hex.EncodeToString(secret))
+	if err != nil {
+		return nil, err
+	}
+	a := &authUsecase{
+		userRepo:       u,
+		credRepo:       c,
+		hasher:         h,
+		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+		dummyHash:      dummyHash,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+func (a *authUsecase) Login(c context.Context, username, password string) (*domain.LoginResult, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	key := "login:" + strings.ToLower(username)
+	if err := checkLockout(ctx, a.lockout, key); err != nil {
+		return nil, err
+	}
+	result, err := a.login(ctx, username, password)
+	return result, recordAttempt(ctx, a.lockout, key, err)
+}
+
+func (a *authUsecase) login(ctx context.Context, username, password string) (*domain.LoginResult, error) {
+	user, err := a.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		_, _, _ = a.hasher.Verify(password, a.dummyHash)
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := a.credRepo.Get(ctx, user.ID)
+	if errors.Is(err, domain.ErrCredentialNotFound) {
+		_, _, _ = a.hasher.Verify(password, a.dummyHash)
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, needsRehash, err := a.hasher.Verify(password, cred.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		a.rehash(ctx, cred, password)
+	}
+
+	if a.mfa == nil {
+		return &domain.LoginResult{User: user}, nil
+	}
+	enabled, err := a.mfa.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return &domain.LoginResult{User: user}, nil
+	}
+	token, err := a.mfaTokens.Sign(&domain.OneTimeToken{
+		Purpose:   domain.PurposeMFALogin,
+		UserID:    user.ID,
+		ExpiresAt: a.clock.Now().Add(mfaLoginTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{MFAToken: token}, nil
+}
+
+func (a *authUsecase) CompleteLogin(c context.Context, mfaToken, code string) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if a.mfa == nil {
+		return nil, domain.ErrInvalidToken
+	}
+	t, err := a.mfaTokens.Parse(domain.PurposeMFALogin, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	key := mfaLockoutKey(t.UserID)
+	if err := checkLockout(ctx, a.lockout, key); err != nil {
+		return nil, err
+	}
+	if err := recordAttempt(ctx, a.lockout, key, a.mfa.Verify(ctx, t.UserID, code)); err != nil {
+		return nil, err
+	}
+	if err := a.usedMFA.Consume(ctx, t.ID, t.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return a.userRepo.GetByID(ctx, t.UserID)
+}
+
+// mfaLockoutKey is shared by every check of a user's second factor, so that
+// guesses at one do not restart the count at another.
+func mfaLockoutKey(userID int64) string {
+	return "mfa:" + strconv.FormatInt(userID, 10)
+}
+
+// checkLockout refuses attempts for a key that is locked out. Should the
+// lockout state be unavailable, attempts are let through rather than locking
+// everyone out. A nil lockout allows everything.
+func checkLockout(ctx context.Context, lockout domain.LoginThrottle, key string) error {
+	if lockout == nil {
+		return nil
+	}
+	d, err := lockout.Check(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "check lockout failed; letting attempt through", "key", key, "error", err)
+		return nil
+	}
+	if d > 0 {
+		return &domain.RetryAfterError{RetryAfter: d}
+	}
+	return nil
+}
+
+// recordAttempt counts a wrong password or code towards a lockout and clears
+// the count after a success. It returns the attempt's error.
+func recordAttempt(ctx context.Context, lockout domain.LoginThrottle, key string, err error) error {
+	if lockout == nil {
+		return err
+	}
+	var lerr error
+	switch {
+	case err == nil:
+		lerr = lockout.Reset(ctx, key)
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrInvalidMFACode):
+		_, lerr = lockout.Fail(ctx, key)
+	}
+	if lerr != nil {
+		slog.WarnContext(ctx, "record login attempt failed", "key", key, "error", lerr)
+	}
+	return err
+}
+
+// rehash upgrades a hash produced with outdated parameters. Failing to do so
+// must not fail a login that has already been verified.
+func (a *authUsecase) rehash(ctx context.Context, cred *domain.Credential, password string) {
+	hash, err := a.hasher.Hash(password)
+	if err == nil {
+		err = a.credRepo.Set(ctx, &domain.Credential{UserID: cred.UserID, PasswordHash: hash, UpdatedAt: a.clock.Now()})
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "rehash password failed", "user_id", cred.UserID, "error", err)
+	}
+}
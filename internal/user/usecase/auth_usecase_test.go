@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/ratelimit"
+	"repo-guardian/internal/user/repository"
+	"repo-guardian/internal/user/token"
+)
+
+func TestNewAuthUsecase(t *testing.T) {
+	a, err := NewAuthUsecase(&mockUserRepository{}, &mockCredentialRepository{}, prefixHasher{cost: "v1"}, time.Second)
+	if err != nil {
+		t.Fatalf("NewAuthUsecase() error = %v", err)
+	}
+	u, ok := a.(*authUsecase)
+	if !ok {
+		t.Fatalf("NewAuthUsecase() returned %T, want *authUsecase", a)
+	}
+	if u.dummyHash == "" || u.contextTimeout != time.Second {
+		t.Errorf("NewAuthUsecase() did not initialize the usecase: %+v", u)
+	}
+}
+
+func TestAuthUsecase_Login(t *testing.T) {
+	user := &domain.User{ID: 1, Username: "test", Email: "test@example.com"}
+	users := &mockUserRepository{
+		getByNameFunc: func(ctx context.Context, username string) (*domain.User, error) {
+			if username == "test" {
+				return user, nil
+			}
+			if username == "broken" {
+				return nil, errors.New("repo error")
+			}
+			return nil, domain.ErrNotFound
+		},
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		stored   string
+		want     *domain.User
+		wantErr  error
+		wantHash string
+	}{
+		{
+			name:     "success",
+			username: "test",
+			password: "correct horse",
+			stored:   "v2:correct horse",
+			want:     user,
+			wantHash: "v2:correct horse",
+		},
+		{
+			name:     "success rehashes outdated hash",
+			username: "test",
+			password: "correct horse",
+			stored:   "v1:correct horse",
+			want:     user,
+			wantHash: "v2:correct horse",
+		},
+		{
+			name:     "wrong password",
+			username: "test",
+			password: "wrong",
+			stored:   "v1:correct horse",
+			wantErr:  domain.ErrInvalidCredentials,
+			wantHash: "v1:correct horse",
+		},
+		{
+			name:     "unknown user",
+			username: "nobody",
+			password: "correct horse",
+			wantErr:  domain.ErrInvalidCredentials,
+		},
+		{
+			name:     "user without password",
+			username: "test",
+			password: "correct horse",
+			wantErr:  domain.ErrInvalidCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds := &mockCredentialRepository{creds: map[int64]domain.Credential{}}
+			if tt.stored != "" {
+				creds.creds[1] = domain.Credential{UserID: 1, PasswordHash: tt.stored}
+			}
+			a := &authUsecase{
+				userRepo:       users,
+				credRepo:       creds,
+				hasher:         prefixHasher{cost: "v2"},
+				contextTimeout: time.Second,
+				clock:          fixedClock(testNow),
+				dummyHash:      "v2:dummy",
+			}
+
+			got, err := a.Login(context.Background(), tt.username, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("authUsecase.Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && (got.User != tt.want || got.MFAToken != "") {
+				t.Errorf("authUsecase.Login() = %+v, want %v", got, tt.want)
+			}
+			if tt.wantHash != "" && creds.creds[1].PasswordHash != tt.wantHash {
+				t.Errorf("stored hash = %q, want %q", creds.creds[1].PasswordHash, tt.wantHash)
+			}
+		})
+	}
+
+	t.Run("repository error", func(t *testing.T) {
+		a := &authUsecase{userRepo: users, credRepo: &mockCredentialRepository{}, hasher: prefixHasher{}, contextTimeout: time.Second}
+		if _, err := a.Login(context.Background(), "broken", "pw"); err == nil || errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("authUsecase.Login() error = %v, want repository error", err)
+		}
+	})
+
+	t.Run("failed rehash does not fail login", func(t *testing.T) {
+		creds := &mockCredentialRepository{
+			creds:  map[int64]domain.Credential{1: {UserID: 1, PasswordHash: "v1:correct horse"}},
+			setErr: errors.New("write error"),
+		}
+		a := &authUsecase{userRepo: users, credRepo: creds, hasher: prefixHasher{cost: "v2"}, contextTimeout: time.Second, clock: fixedClock(testNow)}
+		if _, err := a.Login(context.Background(), "test", "correct horse"); err != nil {
+			t.Errorf("authUsecase.Login() error = %v", err)
+		}
+	})
+}
+
+func TestAuthUsecase_LoginAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store, err := repository.OpenDurableUserRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := NewUserUsecase(store, time.Second,
+		WithClock(fixedClock(testNow)),
+		WithCredentials(store.Credentials(), prefixHasher{cost: "v1"}, minLengthPolicy(8)))
+	user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Password: "correct horse"}
+	if err := users.Register(ctx, user); err != nil {
+		t.Fatalf("userUsecase.Register() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = repository.OpenDurableUserRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	a, err := NewAuthUsecase(store, store.Credentials(), prefixHasher{cost: "v1"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.Login(ctx, "test", "correct horse")
+	if err != nil {
+		t.Fatalf("authUsecase.Login() after reopening error = %v", err)
+	}
+	if got.User == nil || got.User.ID != 1 {
+		t.Errorf("authUsecase.Login() after reopening = %+v, want user 1", got)
+	}
+}
+
+func TestAuthUsecase_LoginMFA(t *testing.T) {
+	user := &domain.User{ID: 1, Username: "john", Email: "john@example.com"}
+	users := &mockUserRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) { return user, nil },
+		getByNameFunc: func(ctx context.Context, username string) (*domain.User, error) {
+			return user, nil
+		},
+	}
+	creds := &mockCredentialRepository{creds: map[int64]domain.Credential{1: {UserID: 1, PasswordHash: "v1:correct horse"}}}
+	mfa, _ := newTestMFAUsecase()
+	signer, err := token.NewOneTimeSigner([]byte(strings.Repeat("k", 32)), fixedClock(testNow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuthUsecase(users, creds, prefixHasher{cost: "v1"}, time.Second, WithMFA(mfa, signer, fakeOneTimeTokenRepository{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.(*authUsecase).clock = fixedClock(testNow)
+	ctx := context.Background()
+
+	got, err := a.Login(ctx, "john", "correct horse")
+	if err != nil || got.User != user || got.MFAToken != "" {
+		t.Fatalf("Login() without MFA = %+v, %v, want the user", got, err)
+	}
+
+	if _, err := mfa.Enroll(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mfa.Confirm(ctx, 1, "100001"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = a.Login(ctx, "john", "correct horse")
+	if err != nil || got.User != nil || got.MFAToken == "" {
+		t.Fatalf("Login() with MFA = %+v, %v, want an MFA token only", got, err)
+	}
+
+	if _, err := a.CompleteLogin(ctx, got.MFAToken, "999999"); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("CompleteLogin() with a wrong code error = %v, want %v", err, domain.ErrInvalidMFACode)
+	}
+	if _, err := a.CompleteLogin(ctx, "forged", "100002"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("CompleteLogin() with a forged token error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+	completed, err := a.CompleteLogin(ctx, got.MFAToken, "100002")
+	if err != nil || completed != user {
+		t.Fatalf("CompleteLogin() = %v, %v, want the user", completed, err)
+	}
+	if _, err := a.CompleteLogin(ctx, got.MFAToken, "100003"); !errors.Is(err, domain.ErrTokenReused) {
+		t.Errorf("CompleteLogin() twice error = %v, want %v", err, domain.ErrTokenReused)
+	}
+}
+
+func TestAuthUsecase_CompleteLogin_WithoutMFA(t *testing.T) {
+	a := &authUsecase{contextTimeout: time.Second}
+	if _, err := a.CompleteLogin(context.Background(), "token", "123456"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("CompleteLogin() error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
+
+func TestAuthUsecase_Lockout(t *testing.T) {
+	user := &domain.User{ID: 1, Username: "john", Email: "john@example.com"}
+	users := &mockUserRepository{
+		getByNameFunc: func(ctx context.Context, username string) (*domain.User, error) {
+			if username == "john" {
+				return user, nil
+			}
+			return nil, domain.ErrNotFound
+		},
+	}
+	creds := &mockCredentialRepository{creds: map[int64]domain.Credential{1: {UserID: 1, PasswordHash: "v1:correct horse"}}}
+	clock := fixedClock(testNow)
+	a := &authUsecase{
+		userRepo:       users,
+		credRepo:       creds,
+		hasher:         prefixHasher{cost: "v1"},
+		contextTimeout: time.Second,
+		clock:          clock,
+		dummyHash:      "v1:dummy",
+		lockout:        ratelimit.NewLockout(ratelimit.NewMemoryStore(clock), 2, time.Minute, time.Hour, clock),
+	}
+	ctx := context.Background()
+
+	if _, err := a.Login(ctx, "john", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("Login() error = %v, want %v", err, domain.ErrInvalidCredentials)
+	}
+	if _, err := a.Login(ctx, "john", "correct horse"); err != nil {
+		t.Fatalf("Login() below the threshold error = %v", err)
+	}
+	for range 2 {
+		if _, err := a.Login(ctx, "JOHN", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("Login() error = %v, want %v", err, domain.ErrInvalidCredentials)
+		}
+	}
+
+	_, err := a.Login(ctx, "john", "correct horse")
+	var retry *domain.RetryAfterError
+	if !errors.As(err, &retry) || retry.RetryAfter != time.Minute || !errors.Is(err, domain.ErrTooManyRequests) {
+		t.Errorf("Login() when locked out error = %v, want a retry after 1m", err)
+	}
+
+	for range 2 {
+		a.Login(ctx, "nobody", "wrong")
+	}
+	if _, err := a.Login(ctx, "nobody", "wrong"); !errors.Is(err, domain.ErrTooManyRequests) {
+		t.Errorf("Login() for an unknown user error = %v, want it locked out too", err)
+	}
+}
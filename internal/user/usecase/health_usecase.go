@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+var errShuttingDown = errors.New("shutting down")
+
+type healthCheck struct {
+	name    string
+	checker domain.HealthChecker
+	// mu serializes runs, so that probes arriving together run the check
+	// once and share its result.
+	mu     sync.Mutex
+	result *domain.CheckResult
+}
+
+// Health aggregates the health checks of the server's dependencies into its
+// readiness.
+type Health struct {
+	mu       sync.RWMutex
+	checks   []*healthCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	draining atomic.Bool
+	clock    domain.Clock
+}
+
+// NewHealth returns a Health that gives each check timeout to finish and
+// reuses its result for cacheTTL, so that frequent probes do not load the
+// dependencies.
+func NewHealth(timeout, cacheTTL time.Duration) *Health {
+	return &Health{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		clock:    domain.SystemClock{},
+	}
+}
+
+// Register adds a check to readiness.
+func (h *Health) Register(name string, checker domain.HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, &healthCheck{name: name, checker: checker})
+}
+
+// Drain makes the server unready for good, so that load balancers stop
+// sending it requests while it shuts down.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready runs every check concurrently, or reuses its recent result.
+func (h *Health) Ready(ctx context.Context) *domain.HealthReport {
+	if h.draining.Load() {
+		return &domain.HealthReport{
+			Status: domain.HealthFail,
+			Checks: []domain.CheckResult{{
+				Name:      "shutdown",
+				Status:    domain.HealthFail,
+				Error:     errShuttingDown.Error(),
+				Duration:  "0s",
+				CheckedAt: h.clock.Now(),
+			}},
+		}
+	}
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := &domain.HealthReport{Status: domain.HealthOK, Checks: make([]domain.CheckResult, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = h.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+	for _, result := range report.Checks {
+		if result.Status != domain.HealthOK {
+			report.Status = domain.HealthFail
+		}
+	}
+	return report
+}
+
+func (h *Health) run(c context.Context, check *healthCheck) domain.CheckResult {
+	check.mu.Lock()
+	defer check.mu.Unlock()
+
+	start := h.clock.Now()
+	if check.result != nil && start.Sub(check.result.CheckedAt) < h.cacheTTL {
+		return *check.result
+	}
+
+	result := domain.CheckResult{Name: check.name, Status: domain.HealthFail, Duration: "0s", CheckedAt: start}
+	// A probe that went away says nothing about the dependency, so its
+	// result is not kept.
+	if err := c.Err(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(c, h.timeout)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- check.checker.CheckHealth(ctx) }()
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		// Checks that ignore their context are abandoned rather than waited
+		// for.
+		err = ctx.Err()
+	}
+
+	result.Duration = h.clock.Now().Sub(start).String()
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Status = domain.HealthOK
+	}
+	if c.Err() == nil {
+		check.result = &result
+	}
+	return result
+}
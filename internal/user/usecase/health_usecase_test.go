@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	clock := fixedClock(testNow)
+	h := NewHealth(20*time.Millisecond, time.Second)
+	h.clock = &clock
+
+	var dbCalls atomic.Int32
+	dbErr := errors.New("connection refused")
+	var failing atomic.Bool
+	h.Register("database", domain.HealthFunc(func(ctx context.Context) error {
+		dbCalls.Add(1)
+		if failing.Load() {
+			return dbErr
+		}
+		return nil
+	}))
+	h.Register("queue", domain.HealthFunc(func(ctx context.Context) error { return nil }))
+
+	report := h.Ready(context.Background())
+	assert.Equal(t, domain.HealthOK, report.Status)
+	assert.Equal(t, []domain.CheckResult{
+		{Name: "database", Status: domain.HealthOK, Duration: "0s", CheckedAt: testNow},
+		{Name: "queue", Status: domain.HealthOK, Duration: "0s", CheckedAt: testNow},
+	}, report.Checks)
+
+	t.Run("cached", func(t *testing.T) {
+		failing.Store(true)
+		clock = fixedClock(testNow.Add(500 * time.Millisecond))
+		assert.Equal(t, domain.HealthOK, h.Ready(context.Background()).Status)
+		assert.Equal(t, int32(1), dbCalls.Load())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		clock = fixedClock(testNow.Add(time.Second))
+		report := h.Ready(context.Background())
+		assert.Equal(t, domain.HealthFail, report.Status)
+		assert.Equal(t, "connection refused", report.Checks[0].Error)
+		assert.Equal(t, domain.HealthOK, report.Checks[1].Status)
+		assert.Equal(t, int32(2), dbCalls.Load())
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		failing.Store(false)
+		clock = fixedClock(testNow.Add(time.Hour))
+		h.Drain()
+		report := h.Ready(context.Background())
+		assert.Equal(t, domain.HealthFail, report.Status)
+		assert.Equal(t, "shutdown", report.Checks[0].Name)
+		assert.Equal(t, int32(2), dbCalls.Load(), "checks do not run while draining")
+	})
+}
+
+func TestHealth_Timeout(t *testing.T) {
+	h := NewHealth(10*time.Millisecond, time.Minute)
+	block := make(chan struct{})
+	defer close(block)
+	h.Register("stuck", domain.HealthFunc(func(ctx context.Context) error {
+		<-block
+		return nil
+	}))
+
+	report := h.Ready(context.Background())
+	assert.Equal(t, domain.HealthFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+
+	// A probe that gave up does not leave its result behind.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h = NewHealth(time.Second, time.Minute)
+	var calls atomic.Int32
+	h.Register("database", domain.HealthFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		return ctx.Err()
+	}))
+	assert.Equal(t, domain.HealthFail, h.Ready(ctx).Status)
+	assert.Equal(t, domain.HealthOK, h.Ready(context.Background()).Status)
+	assert.Equal(t, int32(1), calls.Load())
+}
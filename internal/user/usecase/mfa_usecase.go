@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/mfa"
+)
+
+const recoveryCodeCount = 10
+
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+type mfaUsecase struct {
+	userRepo       domain.UserRepository
+	mfaRepo        domain.MFARepository
+	totp           domain.TOTP
+	authorizer     domain.Authorizer
+	lockout        domain.LoginThrottle
+	contextTimeout time.Duration
+	clock          domain.Clock
+}
+
+// NewMFAUsecase returns the second factor usecase. Wrong codes given to
+// Confirm and Disable count towards lockout, under the same key as those
+// given at login; a nil lockout disables this.
+func NewMFAUsecase(u domain.UserRepository, m domain.MFARepository, totp domain.TOTP, authorizer domain.Authorizer, lockout domain.LoginThrottle, timeout time.Duration) domain.MFAUsecase {
+	return &mfaUsecase{
+		userRepo:       u,
+		mfaRepo:        m,
+		totp:           totp,
+		authorizer:     authorizer,
+		lockout:        lockout,
+		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+	}
+}
+
+func (a *mfaUsecase) Enroll(c context.Context, userID int64) (*domain.MFASetup, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	user, err := a.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := a.mfaRepo.Get(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := a.totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.mfaRepo.Set(ctx, &domain.MFAEnrollment{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &domain.MFASetup{
+		Secret: mfa.Encoding.EncodeToString(secret),
+		URI:    a.totp.URI(user.Username, secret),
+	}, nil
+}
+
+func (a *mfaUsecase) Confirm(c context.Context, userID int64, code string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if _, err := a.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	enrollment, err := a.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	key := mfaLockoutKey(userID)
+	if err := checkLockout(ctx, a.lockout, key); err != nil {
+		return nil, err
+	}
+	step, ok := a.totp.Validate(enrollment.Secret, code, a.clock.Now())
+	if !ok {
+		return nil, recordAttempt(ctx, a.lockout, key, domain.ErrInvalidMFACode)
+	}
+	recordAttempt(ctx, a.lockout, key, nil)
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := a.clock.Now()
+	enrollment.ConfirmedAt = &now
+	enrollment.LastStep = step
+	enrollment.RecoveryCodes = hashes
+	if err := a.mfaRepo.Set(ctx, enrollment); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (a *mfaUsecase) Disable(c context.Context, userID int64, code string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if _, err := a.authorizeUser(ctx, userID); err != nil {
+		return err
+	}
+	key := mfaLockoutKey(userID)
+	if err := checkLockout(ctx, a.lockout, key); err != nil {
+		return err
+	}
+	if err := recordAttempt(ctx, a.lockout, key, a.verify(ctx, userID, code)); err != nil {
+		return err
+	}
+	return a.mfaRepo.Delete(ctx, userID)
+}
+
+func (a *mfaUsecase) Enabled(c context.Context, userID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	enrollment, err := a.mfaRepo.Get(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return enrollment.Enabled(), nil
+}
+
+func (a *mfaUsecase) Verify(c context.Context, userID int64, code string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+	return a.verify(ctx, userID, code)
+}
+
+// verify accepts a current TOTP code that has not been used yet, or an unused
+// recovery code.
+func (a *mfaUsecase) verify(ctx context.Context, userID int64, code string) error {
+	enrollment, err := a.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enrollment.Enabled() {
+		return domain.ErrMFANotEnrolled
+	}
+	if step, ok := a.totp.Validate(enrollment.Secret, code, a.clock.Now()); ok {
+		return a.mfaRepo.UseStep(ctx, userID, step)
+	}
+	return a.mfaRepo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+}
+
+func (a *mfaUsecase) authorizeUser(ctx context.Context, userID int64) (*domain.User, error) {
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, a.authorizer, domain.ActionUpdate, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// newRecoveryCodes returns codes formatted for display, such as
+// "abcd-efgh-ijkl-mnop", and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := recoveryEncoding.EncodeToString(raw)
+		codes[i] = code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
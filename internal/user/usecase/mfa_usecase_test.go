@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/authz"
+	"repo-guardian/internal/user/ratelimit"
+)
+
+// fakeTOTP accepts the codes it maps to a time step, at any time.
+type fakeTOTP map[string]int64
+
+func (fakeTOTP) GenerateSecret() ([]byte, error) { return []byte("secret"), nil }
+
+func (fakeTOTP) URI(account string, secret []byte) string {
+	return "otpauth://totp/test:" + account + "?secret=" + string(secret)
+}
+
+func (f fakeTOTP) Validate(secret []byte, code string, at time.Time) (int64, bool) {
+	step, ok := f[code]
+	return step, ok
+}
+
+type fakeMFARepository map[int64]*domain.MFAEnrollment
+
+func (r fakeMFARepository) Get(ctx context.Context, userID int64) (*domain.MFAEnrollment, error) {
+	e, ok := r[userID]
+	if !ok {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	copied := *e
+	copied.RecoveryCodes = slices.Clone(e.RecoveryCodes)
+	return &copied, nil
+}
+
+func (r fakeMFARepository) Set(ctx context.Context, enrollment *domain.MFAEnrollment) error {
+	copied := *enrollment
+	r[enrollment.UserID] = &copied
+	return nil
+}
+
+func (r fakeMFARepository) Delete(ctx context.Context, userID int64) error {
+	delete(r, userID)
+	return nil
+}
+
+func (r fakeMFARepository) UseStep(ctx context.Context, userID int64, step int64) error {
+	e, ok := r[userID]
+	if !ok {
+		return domain.ErrMFANotEnrolled
+	}
+	if step <= e.LastStep {
+		return domain.ErrInvalidMFACode
+	}
+	e.LastStep = step
+	return nil
+}
+
+func (r fakeMFARepository) UseRecoveryCode(ctx context.Context, userID int64, hash string) error {
+	e, ok := r[userID]
+	if !ok {
+		return domain.ErrMFANotEnrolled
+	}
+	i := slices.Index(e.RecoveryCodes, hash)
+	if i < 0 {
+		return domain.ErrInvalidMFACode
+	}
+	e.RecoveryCodes = slices.Delete(e.RecoveryCodes, i, i+1)
+	return nil
+}
+
+func newTestMFAUsecase() (*mfaUsecase, fakeMFARepository) {
+	users := &mockUserRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if id == 1 {
+				return &domain.User{ID: 1, Username: "john", Email: "john@example.com", Role: domain.RoleUser}, nil
+			}
+			return nil, domain.ErrNotFound
+		},
+	}
+	repo := fakeMFARepository{}
+	totp := fakeTOTP{"100001": 1, "100002": 2, "100003": 3}
+	u := NewMFAUsecase(users, repo, totp, nil, nil, time.Second).(*mfaUsecase)
+	u.clock = fixedClock(testNow)
+	return u, repo
+}
+
+func TestMFAUsecase_EnrollAndConfirm(t *testing.T) {
+	a, repo := newTestMFAUsecase()
+	ctx := context.Background()
+
+	setup, err := a.Enroll(ctx, 1)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if setup.Secret != "ONSWG4TFOQ" || !strings.Contains(setup.URI, "john") {
+		t.Errorf("Enroll() = %+v", setup)
+	}
+	if enabled, _ := a.Enabled(ctx, 1); enabled {
+		t.Errorf("Enabled() before Confirm() = true")
+	}
+	if err := a.Verify(ctx, 1, "100001"); !errors.Is(err, domain.ErrMFANotEnrolled) {
+		t.Errorf("Verify() before Confirm() error = %v, want %v", err, domain.ErrMFANotEnrolled)
+	}
+
+	if _, err := a.Confirm(ctx, 1, "999999"); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("Confirm() with a wrong code error = %v, want %v", err, domain.ErrInvalidMFACode)
+	}
+	codes, err := a.Confirm(ctx, 1, "100001")
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	format := regexp.MustCompile(`^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`)
+	if len(codes) != recoveryCodeCount || !format.MatchString(codes[0]) {
+		t.Errorf("Confirm() recovery codes = %v", codes)
+	}
+	for _, hash := range repo[1].RecoveryCodes {
+		if slices.Contains(codes, hash) {
+			t.Errorf("recovery code %q stored in plain text", hash)
+		}
+	}
+	if enabled, _ := a.Enabled(ctx, 1); !enabled {
+		t.Errorf("Enabled() after Confirm() = false")
+	}
+
+	if _, err := a.Enroll(ctx, 1); !errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+		t.Errorf("Enroll() when enabled error = %v, want %v", err, domain.ErrMFAAlreadyEnabled)
+	}
+	if _, err := a.Confirm(ctx, 1, "100002"); !errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+		t.Errorf("Confirm() when enabled error = %v, want %v", err, domain.ErrMFAAlreadyEnabled)
+	}
+}
+
+func TestMFAUsecase_Verify(t *testing.T) {
+	a, _ := newTestMFAUsecase()
+	ctx := context.Background()
+	if _, err := a.Enroll(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	codes, err := a.Confirm(ctx, 1, "100001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{"code used to confirm", "100001", domain.ErrInvalidMFACode},
+		{"next code", "100002", nil},
+		{"replayed code", "100002", domain.ErrInvalidMFACode},
+		{"wrong code", "999999", domain.ErrInvalidMFACode},
+		{"recovery code", strings.ToUpper(strings.ReplaceAll(codes[0], "-", " ")), nil},
+		{"used recovery code", codes[0], domain.ErrInvalidMFACode},
+		{"another recovery code", codes[1], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := a.Verify(ctx, 1, tt.code); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify(%q) error = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMFAUsecase_Disable(t *testing.T) {
+	a, repo := newTestMFAUsecase()
+	ctx := context.Background()
+	if _, err := a.Enroll(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Confirm(ctx, 1, "100001"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Disable(ctx, 1, "999999"); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("Disable() with a wrong code error = %v, want %v", err, domain.ErrInvalidMFACode)
+	}
+	if err := a.Disable(ctx, 1, "100002"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if _, enrolled := repo[1]; enrolled {
+		t.Errorf("Disable() kept the enrollment")
+	}
+	if enabled, err := a.Enabled(ctx, 1); enabled || err != nil {
+		t.Errorf("Enabled() after Disable() = %v, %v", enabled, err)
+	}
+}
+
+func TestMFAUsecase_Lockout(t *testing.T) {
+	const threshold = 3
+	ctx := context.Background()
+	newLocked := func(t *testing.T) *mfaUsecase {
+		a, _ := newTestMFAUsecase()
+		a.lockout = ratelimit.NewLockout(ratelimit.NewMemoryStore(a.clock), threshold, time.Minute, time.Hour, a.clock)
+		if _, err := a.Enroll(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	wantLockedOut := func(t *testing.T, name string, err error) {
+		t.Helper()
+		var retry *domain.RetryAfterError
+		if !errors.As(err, &retry) || retry.RetryAfter != time.Minute {
+			t.Errorf("%s after %d wrong codes error = %v, want a retry after 1m", name, threshold, err)
+		}
+	}
+
+	t.Run("confirm", func(t *testing.T) {
+		a := newLocked(t)
+		for range threshold {
+			if _, err := a.Confirm(ctx, 1, "999999"); !errors.Is(err, domain.ErrInvalidMFACode) {
+				t.Fatalf("Confirm() error = %v, want %v", err, domain.ErrInvalidMFACode)
+			}
+		}
+		_, err := a.Confirm(ctx, 1, "100001")
+		wantLockedOut(t, "Confirm()", err)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		a := newLocked(t)
+		if _, err := a.Confirm(ctx, 1, "100001"); err != nil {
+			t.Fatal(err)
+		}
+		for range threshold {
+			if err := a.Disable(ctx, 1, "999999"); !errors.Is(err, domain.ErrInvalidMFACode) {
+				t.Fatalf("Disable() error = %v, want %v", err, domain.ErrInvalidMFACode)
+			}
+		}
+		wantLockedOut(t, "Disable()", a.Disable(ctx, 1, "100002"))
+		if enabled, _ := a.Enabled(ctx, 1); !enabled {
+			t.Errorf("Disable() turned off the second factor while locked out")
+		}
+	})
+}
+
+func TestMFAUsecase_Authorization(t *testing.T) {
+	a, _ := newTestMFAUsecase()
+	a.authorizer = authz.NewPolicy(authz.DefaultRules...)
+	other := domain.WithPrincipal(context.Background(), domain.UserPrincipal(2, domain.RoleUser))
+	self := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+
+	if _, err := a.Enroll(context.Background(), 1); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("Enroll() anonymously error = %v, want %v", err, domain.ErrUnauthenticated)
+	}
+	if _, err := a.Enroll(other, 1); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Enroll() for another user error = %v, want %v", err, domain.ErrForbidden)
+	}
+	if _, err := a.Enroll(self, 1); err != nil {
+		t.Errorf("Enroll() for self error = %v", err)
+	}
+	if err := a.Disable(other, 1, "100001"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Disable() for another user error = %v, want %v", err, domain.ErrForbidden)
+	}
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/logging"
+)
+
+// erasureTimeout bounds an erasure job, which runs detached from the request
+// that started it.
+const erasureTimeout = time.Minute
+
+type privacyUsecase struct {
+	userRepo       domain.UserRepository
+	credRepo       domain.CredentialRepository
+	refreshRepo    domain.RefreshTokenRepository
+	keyRepo        domain.APIKeyRepository
+	mfaRepo        domain.MFARepository
+	audit          domain.AuditSink
+	jobRepo        domain.ErasureJobRepository
+	authorizer     domain.Authorizer
+	contextTimeout time.Duration
+	clock          domain.Clock
+	// start runs an erasure job in the background.
+	start func(job func())
+}
+
+type PrivacyOption func(*privacyUsecase)
+
+// WithJobRunner runs erasure jobs with start, which must not block, rather
+// than in plain goroutines, so that they can be waited for on shutdown.
+func WithJobRunner(start func(job func())) PrivacyOption {
+	return func(a *privacyUsecase) {
+		a.start = start
+	}
+}
+
+func NewPrivacyUsecase(u domain.UserRepository, c domain.CredentialRepository, r domain.RefreshTokenRepository, k domain.APIKeyRepository,
+	m domain.MFARepository, audit domain.AuditSink, jobs domain.ErasureJobRepository, authorizer domain.Authorizer, timeout time.Duration,
+	opts ...PrivacyOption) domain.PrivacyUsecase {
+	a := &privacyUsecase{
+		userRepo:       u,
+		credRepo:       c,
+		refreshRepo:    r,
+		keyRepo:        k,
+		mfaRepo:        m,
+		audit:          audit,
+		jobRepo:        jobs,
+		authorizer:     authorizer,
+		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+		start:          func(job func()) { go job() },
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+func (a *privacyUsecase) Export(c context.Context, userID int64) (*domain.UserExport, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	user, err := a.userRepo.GetByID(domain.WithDeleted(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, a.authorizer, domain.ActionExport, user); err != nil {
+		return nil, err
+	}
+
+	now := a.clock.Now()
+	export := &domain.UserExport{
+		ExportedAt:  now,
+		User:        user,
+		Sessions:    []domain.SessionInfo{},
+		APIKeys:     []*domain.APIKey{},
+		AuditEvents: []*domain.AuditEvent{},
+	}
+
+	cred, err := a.credRepo.Get(ctx, userID)
+	switch {
+	case err == nil:
+		export.Password = &domain.PasswordInfo{UpdatedAt: cred.UpdatedAt}
+	case !errors.Is(err, domain.ErrCredentialNotFound):
+		return nil, err
+	}
+
+	enrollment, err := a.mfaRepo.Get(ctx, userID)
+	switch {
+	case err == nil:
+		export.MFA = &domain.MFAInfo{
+			Enabled:             enrollment.Enabled(),
+			ConfirmedAt:         enrollment.ConfirmedAt,
+			RecoveryCodesUnused: len(enrollment.RecoveryCodes),
+		}
+	case !errors.Is(err, domain.ErrMFANotEnrolled):
+		return nil, err
+	}
+
+	tokens, err := a.refreshRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if token.ExpiresAt.After(now) {
+			export.Sessions = append(export.Sessions, domain.SessionInfo{Family: token.Family, CreatedAt: token.CreatedAt, ExpiresAt: token.ExpiresAt})
+		}
+	}
+
+	keys, err := a.keyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.CreatedBy == domain.UserTarget(userID) {
+			export.APIKeys = append(export.APIKeys, key)
+		}
+	}
+
+	filter := domain.AuditFilter{Target: domain.UserTarget(userID), Limit: domain.MaxListLimit}
+	for {
+		events, err := a.audit.Query(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		export.AuditEvents = append(export.AuditEvents, events...)
+		if len(events) < filter.Limit {
+			break
+		}
+		filter.After = events[len(events)-1].ID
+	}
+	return export, nil
+}
+
+func (a *privacyUsecase) RequestErasure(c context.Context, userID int64) (*domain.ErasureJob, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	user, err := a.userRepo.GetByID(domain.WithDeleted(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, a.authorizer, domain.ActionErase, user); err != nil {
+		return nil, err
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	now := a.clock.Now()
+	job := &domain.ErasureJob{
+		ID:          id,
+		UserID:      userID,
+		Status:      domain.ErasurePending,
+		RequestedBy: domain.Actor(ctx),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := a.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	queued := *job
+	a.start(func() { a.run(&queued) })
+	return job, nil
+}
+
+func (a *privacyUsecase) GetErasure(c context.Context, jobID string) (*domain.ErasureJob, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	job, err := a.jobRepo.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	// The user may have been purged since, so only the ID is certain.
+	if err := authorize(ctx, a.authorizer, domain.ActionErase, &domain.User{ID: job.UserID}); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// run carries out an erasure job and records its outcome.
+func (a *privacyUsecase) run(job *domain.ErasureJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), erasureTimeout)
+	defer cancel()
+	ctx = logging.With(ctx, "job_id", job.ID, "user_id", job.UserID)
+
+	a.setStatus(ctx, job, domain.ErasureRunning, nil)
+	err := a.erase(ctx, job)
+	if err != nil {
+		slog.ErrorContext(ctx, "erasure job failed", "error", err)
+		a.setStatus(ctx, job, domain.ErasureFailed, err)
+		return
+	}
+	a.setStatus(ctx, job, domain.ErasureCompleted, nil)
+}
+
+// erase ends the user's sessions, removes their secrets and anonymizes them
+// and the audit events about them. Every step can be repeated, so a failed
+// job can simply be requested again.
+func (a *privacyUsecase) erase(ctx context.Context, job *domain.ErasureJob) error {
+	if err := a.refreshRepo.RevokeUser(ctx, job.UserID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	if err := a.credRepo.Delete(ctx, job.UserID); err != nil {
+		return fmt.Errorf("delete password: %w", err)
+	}
+	if err := a.mfaRepo.Delete(ctx, job.UserID); err != nil {
+		return fmt.Errorf("delete second factor: %w", err)
+	}
+	if _, err := a.userRepo.Anonymize(ctx, job.UserID, a.clock.Now()); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("anonymize user: %w", err)
+	}
+
+	eraser, ok := a.audit.(domain.AuditEraser)
+	if !ok {
+		return errors.New("audit log cannot erase events")
+	}
+	if _, err := eraser.Erase(ctx, domain.UserTarget(job.UserID), domain.PersonalFields); err != nil {
+		return fmt.Errorf("erase audit events: %w", err)
+	}
+	return a.audit.Append(ctx, &domain.AuditEvent{
+		Time:   a.clock.Now(),
+		Actor:  job.RequestedBy,
+		Action: domain.ActionErase,
+		Target: domain.UserTarget(job.UserID),
+	})
+}
+
+func (a *privacyUsecase) setStatus(ctx context.Context, job *domain.ErasureJob, status domain.ErasureStatus, err error) {
+	now := a.clock.Now()
+	job.Status = status
+	job.UpdatedAt = now
+	job.Error = ""
+	if err != nil {
+		job.Error = err.Error()
+	}
+	if status == domain.ErasureCompleted || status == domain.ErasureFailed {
+		job.CompletedAt = &now
+	}
+	if err := a.jobRepo.Update(ctx, job); err != nil {
+		slog.ErrorContext(ctx, "update erasure job failed", "status", status, "error", err)
+	}
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/audit"
+	"repo-guardian/internal/user/authz"
+	"repo-guardian/internal/user/repository"
+)
+
+type privacyFixture struct {
+	usecase *privacyUsecase
+	users   domain.UserRepository
+	creds   domain.CredentialRepository
+	refresh domain.RefreshTokenRepository
+	mfa     domain.MFARepository
+	sink    domain.AuditSink
+}
+
+func newPrivacyFixture(t *testing.T) *privacyFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &privacyFixture{
+		users:   repository.NewMemoryUserRepository(),
+		creds:   repository.NewMemoryCredentialRepository(),
+		refresh: repository.NewMemoryRefreshTokenRepository(),
+		mfa:     repository.NewMemoryMFARepository(),
+		sink:    audit.NewMemorySink(),
+	}
+	keys := repository.NewMemoryAPIKeyRepository()
+	uc := NewPrivacyUsecase(f.users, f.creds, f.refresh, keys, f.mfa, f.sink, repository.NewMemoryErasureJobRepository(),
+		authz.NewPolicy(authz.DefaultRules...), time.Second, WithJobRunner(func(job func()) { job() })).(*privacyUsecase)
+	uc.clock = fixedClock(testNow)
+	f.usecase = uc
+
+	users := NewUserUsecase(f.users, time.Second, WithClock(fixedClock(testNow)), WithAudit(f.sink))
+	admin := domain.WithPrincipal(ctx, domain.UserPrincipal(9, domain.RoleAdmin))
+	for _, u := range []*domain.User{
+		{ID: 1, Username: "john", Email: "john@example.com"},
+		{ID: 2, Username: "jane", Email: "jane@example.com"},
+	} {
+		if err := users.Register(admin, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := users.PatchUser(admin, 1, patchFunc(func(u *domain.User) error { u.Email = "john@example.org"; return nil })); err != nil {
+		t.Fatal(err)
+	}
+
+	f.creds.Set(ctx, &domain.Credential{UserID: 1, PasswordHash: "secret hash", UpdatedAt: testNow})
+	f.mfa.Set(ctx, &domain.MFAEnrollment{UserID: 1, Secret: []byte("secret"), ConfirmedAt: &testNow, RecoveryCodes: []string{"a", "b"}})
+	f.refresh.Create(ctx, &domain.RefreshToken{Hash: "live", UserID: 1, Family: "f1", CreatedAt: testNow, ExpiresAt: testNow.Add(time.Hour)})
+	f.refresh.Create(ctx, &domain.RefreshToken{Hash: "old", UserID: 1, Family: "f2", ExpiresAt: testNow.Add(-time.Hour)})
+	keys.Create(ctx, &domain.APIKey{ID: "k1", Name: "ci", CreatedBy: "user:1"})
+	keys.Create(ctx, &domain.APIKey{ID: "k2", Name: "other", CreatedBy: "user:9"})
+	return f
+}
+
+func TestPrivacyUsecase_Export(t *testing.T) {
+	f := newPrivacyFixture(t)
+	ctx := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+
+	got, err := f.usecase.Export(ctx, 1)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if got.User.Email != "john@example.org" || !got.ExportedAt.Equal(testNow) {
+		t.Errorf("Export() user = %+v", got.User)
+	}
+	if got.Password == nil || !got.Password.UpdatedAt.Equal(testNow) {
+		t.Errorf("Export() password = %+v", got.Password)
+	}
+	if got.MFA == nil || !got.MFA.Enabled || got.MFA.RecoveryCodesUnused != 2 {
+		t.Errorf("Export() mfa = %+v", got.MFA)
+	}
+	if len(got.Sessions) != 1 || got.Sessions[0].Family != "f1" {
+		t.Errorf("Export() sessions = %+v, want only the live one", got.Sessions)
+	}
+	if len(got.APIKeys) != 1 || got.APIKeys[0].ID != "k1" {
+		t.Errorf("Export() api keys = %+v, want the one the user created", got.APIKeys)
+	}
+	if len(got.AuditEvents) != 2 || got.AuditEvents[0].Action != domain.ActionCreate || got.AuditEvents[1].Action != domain.ActionUpdate {
+		t.Errorf("Export() audit events = %+v, want the user's create and update", got.AuditEvents)
+	}
+
+	if _, err := f.usecase.Export(ctx, 2); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Export() of another user error = %v, want %v", err, domain.ErrForbidden)
+	}
+	if _, err := f.usecase.Export(ctx, 3); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Export() of an unknown user error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestPrivacyUsecase_Erasure(t *testing.T) {
+	f := newPrivacyFixture(t)
+	ctx := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+	other := domain.WithPrincipal(context.Background(), domain.UserPrincipal(2, domain.RoleUser))
+
+	if _, err := f.usecase.RequestErasure(other, 1); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("RequestErasure() of another user error = %v, want %v", err, domain.ErrForbidden)
+	}
+
+	job, err := f.usecase.RequestErasure(ctx, 1)
+	if err != nil {
+		t.Fatalf("RequestErasure() error = %v", err)
+	}
+	if job.ID == "" || job.Status != domain.ErasurePending || job.RequestedBy != "user:1" {
+		t.Errorf("RequestErasure() = %+v, want a pending job", job)
+	}
+
+	got, err := f.usecase.GetErasure(ctx, job.ID)
+	if err != nil || got.Status != domain.ErasureCompleted || got.CompletedAt == nil || got.Error != "" {
+		t.Fatalf("GetErasure() = %+v, %v, want a completed job", got, err)
+	}
+	if _, err := f.usecase.GetErasure(other, job.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("GetErasure() by another user error = %v, want %v", err, domain.ErrForbidden)
+	}
+	if _, err := f.usecase.GetErasure(ctx, "unknown"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("GetErasure() of an unknown job error = %v, want %v", err, domain.ErrJobNotFound)
+	}
+
+	bg := context.Background()
+	user, err := f.users.GetByID(domain.WithDeleted(bg), 1)
+	if err != nil || user.Username != "erased-1" || user.DeletedAt == nil {
+		t.Errorf("user after erasure = %+v, %v, want it anonymized", user, err)
+	}
+	if _, err := f.creds.Get(bg, 1); !errors.Is(err, domain.ErrCredentialNotFound) {
+		t.Errorf("password after erasure error = %v, want it deleted", err)
+	}
+	if _, err := f.mfa.Get(bg, 1); !errors.Is(err, domain.ErrMFANotEnrolled) {
+		t.Errorf("second factor after erasure error = %v, want it deleted", err)
+	}
+	if _, err := f.refresh.Get(bg, "live"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("session after erasure error = %v, want it revoked", err)
+	}
+
+	events, _ := f.sink.Query(bg, domain.AuditFilter{Target: "user:1", Limit: 10})
+	if len(events) != 3 || events[2].Action != domain.ActionErase || events[2].Actor != "user:1" {
+		t.Fatalf("audit events after erasure = %+v, want an erase event last", events)
+	}
+	for _, event := range events[:2] {
+		for _, field := range domain.PersonalFields {
+			if change, ok := event.Changes[field]; ok && change.After != domain.Redacted {
+				t.Errorf("event %d kept %s = %+v", event.ID, field, change)
+			}
+		}
+	}
+	if jane, _ := f.sink.Query(bg, domain.AuditFilter{Target: "user:2", Limit: 10}); jane[0].Changes["email"].After != "jane@example.com" {
+		t.Errorf("erasure redacted another user's events: %+v", jane[0].Changes)
+	}
+
+	// The erased user's record remains, so it can still be exported.
+	export, err := f.usecase.Export(domain.WithPrincipal(bg, domain.UserPrincipal(9, domain.RoleAdmin)), 1)
+	if err != nil || export.User.Email != "erased-1@erased.invalid" || export.Password != nil {
+		t.Errorf("Export() after erasure = %+v, %v", export, err)
+	}
+}
+
+func TestPrivacyUsecase_ErasureFails(t *testing.T) {
+	f := newPrivacyFixture(t)
+	// Hide the sink's Erase method.
+	f.usecase.audit = struct{ domain.AuditSink }{f.sink}
+	ctx := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+
+	job, err := f.usecase.RequestErasure(ctx, 1)
+	if err != nil {
+		t.Fatalf("RequestErasure() error = %v", err)
+	}
+	got, _ := f.usecase.GetErasure(ctx, job.ID)
+	if got.Status != domain.ErasureFailed || got.Error == "" || got.CompletedAt == nil {
+		t.Errorf("GetErasure() = %+v, want a failed job", got)
+	}
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+// Purger hard-deletes users once they have been soft-deleted for longer than
+// the retention window.
+type Purger struct {
+	userRepo  domain.UserRepository
+	retention time.Duration
+	interval  time.Duration
+	clock     domain.Clock
+}
+
+func NewPurger(u domain.UserRepository, retention, interval time.Duration, clock domain.Clock) *Purger {
+	return &Purger{
+		userRepo:  u,
+		retention: retention,
+		interval:  interval,
+		clock:     clock,
+	}
+}
+
+// PurgeOnce removes every user deleted before now minus the retention window.
+func (p *Purger) PurgeOnce(ctx context.Context) (int, error) {
+	return p.userRepo.Purge(ctx, p.clock.Now().Add(-p.retention))
+}
+
+// Run purges on every interval tick until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := p.PurgeOnce(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "purge deleted users failed", "error", err)
+				continue
+			}
+			if n > 0 {
+				slog.InfoContext(ctx, "purged deleted users", "count", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+func TestNewPurger(t *testing.T) {
+	repo := &mockUserRepository{}
+	p := NewPurger(repo, time.Hour, time.Minute, fixedClock(testNow))
+
+	if p.userRepo != repo || p.retention != time.Hour || p.interval != time.Minute || p.clock != fixedClock(testNow) {
+		t.Errorf("NewPurger() = %+v, fields not set correctly", p)
+	}
+}
+
+func TestPurger_PurgeOnce(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	var gotCutoff time.Time
+	repo := &mockUserRepository{
+		purgeFunc: func(ctx context.Context, cutoff time.Time) (int, error) {
+			gotCutoff = cutoff
+			return 2, nil
+		},
+	}
+	p := NewPurger(repo, 30*24*time.Hour, time.Hour, fixedClock(now))
+
+	n, err := p.PurgeOnce(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("Purger.PurgeOnce() = %d, %v, want 2, nil", n, err)
+	}
+	if want := now.Add(-30 * 24 * time.Hour); !gotCutoff.Equal(want) {
+		t.Errorf("Purger.PurgeOnce() cutoff = %v, want %v", gotCutoff, want)
+	}
+}
+
+func TestPurger_Run(t *testing.T) {
+	var calls atomic.Int32
+	repo := &mockUserRepository{
+		purgeFunc: func(ctx context.Context, cutoff time.Time) (int, error) {
+			if calls.Add(1) == 1 {
+				return 0, errors.New("purge error")
+			}
+			return 1, nil
+		},
+	}
+	p := NewPurger(repo, time.Hour, time.Millisecond, domain.SystemClock{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for calls.Load() < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("Purger.Run() purged %d times, want at least 2", calls.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Purger.Run() did not return after cancel")
+	}
+}
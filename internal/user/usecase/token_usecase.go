@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type tokenUsecase struct {
+	userRepo       domain.UserRepository
+	refreshRepo    domain.RefreshTokenRepository
+	access         domain.AccessTokenIssuer
+	refreshTTL     time.Duration
+	contextTimeout time.Duration
+	clock          domain.Clock
+}
+
+func NewTokenUsecase(u domain.UserRepository, r domain.RefreshTokenRepository, a domain.AccessTokenIssuer, refreshTTL, timeout time.Duration) domain.TokenUsecase {
+	return &tokenUsecase{
+		userRepo:       u,
+		refreshRepo:    r,
+		access:         a,
+		refreshTTL:     refreshTTL,
+		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+	}
+}
+
+func (a *tokenUsecase) Issue(c context.Context, user *domain.User) (*domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	family, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	refresh, record, err := a.newRefreshToken(user.ID, family)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.refreshRepo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	return a.pair(user, refresh)
+}
+
+func (a *tokenUsecase) Refresh(c context.Context, refreshToken string) (*domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	hash := hashToken(refreshToken)
+	current, err := a.refreshRepo.Get(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if current.RotatedAt != nil {
+		return nil, a.reused(ctx, current)
+	}
+	now := a.clock.Now()
+	if !current.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := a.userRepo.GetByID(ctx, current.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, next, err := a.newRefreshToken(user.ID, current.Family)
+	if err != nil {
+		return nil, err
+	}
+	// Two requests racing with the same token must not both succeed: the
+	// loser sees the token as reused.
+	if err := a.refreshRepo.Rotate(ctx, hash, now, next); err != nil {
+		if errors.Is(err, domain.ErrTokenReused) {
+			return nil, a.reused(ctx, current)
+		}
+		return nil, err
+	}
+	return a.pair(user, refresh)
+}
+
+func (a *tokenUsecase) Revoke(c context.Context, refreshToken string) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	current, err := a.refreshRepo.Get(ctx, hashToken(refreshToken))
+	if errors.Is(err, domain.ErrInvalidToken) {
+		// Revoking an unknown token already has the desired outcome.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return a.refreshRepo.RevokeFamily(ctx, current.Family)
+}
+
+func (a *tokenUsecase) Authenticate(ctx context.Context, accessToken string) (domain.Principal, error) {
+	return a.access.Verify(accessToken)
+}
+
+// reused handles a refresh token presented after it was rotated. Either the
+// client or an attacker holds a stolen copy, so the whole session is revoked.
+func (a *tokenUsecase) reused(ctx context.Context, token *domain.RefreshToken) error {
+	slog.WarnContext(ctx, "refresh token reuse detected; revoking session", "user_id", token.UserID, "family", token.Family)
+	if err := a.refreshRepo.RevokeFamily(ctx, token.Family); err != nil {
+		return err
+	}
+	return domain.ErrTokenReused
+}
+
+func (a *tokenUsecase) newRefreshToken(userID int64, family string) (string, *domain.RefreshToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := a.clock.Now()
+	return token, &domain.RefreshToken{
+		Hash:      hashToken(token),
+		UserID:    userID,
+		Family:    family,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.refreshTTL),
+	}, nil
+}
+
+func (a *tokenUsecase) pair(user *domain.User, refresh string) (*domain.TokenPair, error) {
+	access, expiresAt, err := a.access.Issue(user)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(expiresAt.Sub(a.clock.Now()).Seconds()),
+		RefreshToken: refresh,
+	}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the form a refresh token is stored in. The tokens carry
+// 256 bits of entropy, so a plain SHA-256 is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
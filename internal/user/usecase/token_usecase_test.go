@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"repo-guardian/internal/domain"
+)
+
+type fakeRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]domain.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.Hash] = *token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) Get(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[hash]
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+	return &token, nil
+}
+
+func (r *fakeRefreshTokenRepository) Rotate(ctx context.Context, hash string, at time.Time, next *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[hash]
+	if !ok {
+		return domain.ErrInvalidToken
+	}
+	if token.RotatedAt != nil {
+		return domain.ErrTokenReused
+	}
+	token.RotatedAt = &at
+	r.tokens[hash] = token
+	r.tokens[next.Hash] = *next
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(ctx context.Context, family string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, token := range r.tokens {
+		if token.Family == family {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []*domain.RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RotatedAt == nil {
+			tokens = append(tokens, &token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeUser(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// idIssuer issues access tokens that are simply the user ID.
+type idIssuer struct{}
+
+func (idIssuer) Issue(user *domain.User) (string, time.Time, error) {
+	return strconv.FormatInt(user.ID, 10), testNow.Add(15 * time.Minute), nil
+}
+
+func (idIssuer) Verify(token string) (domain.Principal, error) {
+	id, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return domain.Principal{}, domain.ErrInvalidToken
+	}
+	return domain.UserPrincipal(id, domain.RoleUser), nil
+}
+
+func newTestTokenUsecase(users map[int64]*domain.User) (*tokenUsecase, *fakeRefreshTokenRepository) {
+	refresh := &fakeRefreshTokenRepository{tokens: make(map[string]domain.RefreshToken)}
+	repo := &mockUserRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, domain.ErrNotFound
+		},
+	}
+	return &tokenUsecase{
+		userRepo:       repo,
+		refreshRepo:    refresh,
+		access:         idIssuer{},
+		refreshTTL:     time.Hour,
+		contextTimeout: time.Second,
+		clock:          fixedClock(testNow),
+	}, refresh
+}
+
+func TestTokenUsecase_IssueAndRefresh(t *testing.T) {
+	user := &domain.User{ID: 1, Username: "john"}
+	a, refresh := newTestTokenUsecase(map[int64]*domain.User{1: user})
+	ctx := context.Background()
+
+	pair, err := a.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if pair.AccessToken != "1" || pair.TokenType != "Bearer" || pair.ExpiresIn != 900 || pair.RefreshToken == "" {
+		t.Errorf("Issue() = %+v", pair)
+	}
+	if _, stored := refresh.tokens[pair.RefreshToken]; stored {
+		t.Errorf("Issue() stored the refresh token in plaintext")
+	}
+
+	next, err := a.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if next.RefreshToken == pair.RefreshToken {
+		t.Errorf("Refresh() did not rotate the refresh token")
+	}
+
+	// Replaying the first token revokes the whole session, including the
+	// token that replaced it.
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, domain.ErrTokenReused) {
+		t.Errorf("Refresh() with a used token error = %v, want %v", err, domain.ErrTokenReused)
+	}
+	if _, err := a.Refresh(ctx, next.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("Refresh() after reuse error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
+
+func TestTokenUsecase_Refresh_Rejects(t *testing.T) {
+	user := &domain.User{ID: 1}
+	ctx := context.Background()
+
+	t.Run("unknown token", func(t *testing.T) {
+		a, _ := newTestTokenUsecase(map[int64]*domain.User{1: user})
+		if _, err := a.Refresh(ctx, "unknown"); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("Refresh() error = %v, want %v", err, domain.ErrInvalidToken)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		a, _ := newTestTokenUsecase(map[int64]*domain.User{1: user})
+		pair, _ := a.Issue(ctx, user)
+		a.clock = fixedClock(testNow.Add(2 * time.Hour))
+		if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("Refresh() error = %v, want %v", err, domain.ErrInvalidToken)
+		}
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		users := map[int64]*domain.User{1: user}
+		a, _ := newTestTokenUsecase(users)
+		pair, _ := a.Issue(ctx, user)
+		delete(users, 1)
+		if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Errorf("Refresh() error = %v, want %v", err, domain.ErrInvalidToken)
+		}
+	})
+}
+
+func TestTokenUsecase_Refresh_Concurrent(t *testing.T) {
+	user := &domain.User{ID: 1}
+	a, _ := newTestTokenUsecase(map[int64]*domain.User{1: user})
+	pair, _ := a.Issue(context.Background(), user)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var succeeded int
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := a.Refresh(context.Background(), pair.RefreshToken); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("%d concurrent refreshes with one token succeeded, want 1", succeeded)
+	}
+}
+
+func TestTokenUsecase_Revoke(t *testing.T) {
+	user := &domain.User{ID: 1}
+	a, _ := newTestTokenUsecase(map[int64]*domain.User{1: user})
+	ctx := context.Background()
+
+	pair, _ := a.Issue(ctx, user)
+	if err := a.Revoke(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("Refresh() after Revoke() error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+	if err := a.Revoke(ctx, pair.RefreshToken); err != nil {
+		t.Errorf("Revoke() of an unknown token error = %v", err)
+	}
+}
+
+func TestTokenUsecase_Authenticate(t *testing.T) {
+	a, _ := newTestTokenUsecase(nil)
+	p, err := a.Authenticate(context.Background(), "7")
+	if err != nil || !reflect.DeepEqual(p, domain.UserPrincipal(7, domain.RoleUser)) {
+		t.Errorf("Authenticate() = %+v, %v, want user:7", p, err)
+	}
+}
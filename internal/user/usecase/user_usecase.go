@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"repo-guardian/internal/domain"
@@ -10,29 +12,387 @@ import (
 type userUsecase struct {
 	userRepo       domain.UserRepository
 	contextTimeout time.Duration
+	clock          domain.Clock
+	credRepo       domain.CredentialRepository
+	hasher         domain.PasswordHasher
+	policy         domain.PasswordPolicy
+	authorizer     domain.Authorizer
+	audit          domain.AuditSink
 }
 
-func NewUserUsecase(u domain.UserRepository, timeout time.Duration) domain.UserUsecase {
-	return &userUsecase{
+type Option func(*userUsecase)
+
+// WithClock sets the clock used for CreatedAt and UpdatedAt.
+func WithClock(clock domain.Clock) Option {
+	return func(u *userUsecase) {
+		u.clock = clock
+	}
+}
+
+// WithCredentials enables passwords on registration.
+func WithCredentials(repo domain.CredentialRepository, hasher domain.PasswordHasher, policy domain.PasswordPolicy) Option {
+	return func(u *userUsecase) {
+		u.credRepo = repo
+		u.hasher = hasher
+		u.policy = policy
+	}
+}
+
+// WithAuthorizer checks every call against the caller's permissions. Without
+// it all calls are allowed, as for a trusted in-process caller.
+func WithAuthorizer(authorizer domain.Authorizer) Option {
+	return func(u *userUsecase) {
+		u.authorizer = authorizer
+	}
+}
+
+// WithAudit records every mutation in sink.
+func WithAudit(sink domain.AuditSink) Option {
+	return func(u *userUsecase) {
+		u.audit = sink
+	}
+}
+
+func NewUserUsecase(u domain.UserRepository, timeout time.Duration, opts ...Option) domain.UserUsecase {
+	uc := &userUsecase{
 		userRepo:       u,
 		contextTimeout: timeout,
+		clock:          domain.SystemClock{},
+	}
+	for _, opt := range opts {
+		opt(uc)
 	}
+	return uc
 }
 
 func (a *userUsecase) Register(c context.Context, user *domain.User) error {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
-	return a.userRepo.Create(ctx, user)
+
+	password := user.Password
+	user.Password = ""
+
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+	if err := a.authorize(ctx, domain.ActionCreate, user); err != nil {
+		return err
+	}
+	if user.Role != domain.RoleUser {
+		if err := a.authorize(ctx, domain.ActionAssignRole, user); err != nil {
+			return err
+		}
+	}
+	if err := user.Validate(); err != nil {
+		return err
+	}
+	hash, err := a.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	now := a.now()
+	actor := domain.Actor(ctx)
+	user.CreatedAt, user.CreatedBy = now, actor
+	user.UpdatedAt, user.UpdatedBy = now, actor
+	if err := a.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+	if hash != "" {
+		if err := a.credRepo.Set(ctx, &domain.Credential{UserID: user.ID, PasswordHash: hash, UpdatedAt: now}); err != nil {
+			// A user without the password it registered with could never log
+			// in, and would keep its ID, username and email from a retry.
+			if err := a.unregister(context.WithoutCancel(ctx), user.ID); err != nil {
+				slog.ErrorContext(ctx, "removing half-registered user failed", "user_id", user.ID, "error", err)
+			}
+			return err
+		}
+	}
+
+	created := *user
+	created.Password = password
+	a.record(ctx, domain.ActionCreate, user.ID, nil, &created)
+	return nil
+}
+
+// unregister removes the user created by a registration that could not be
+// completed. Deleting it as of the zero time lets Purge remove it, and no
+// user deleted for real, straight away.
+func (a *userUsecase) unregister(ctx context.Context, id int64) error {
+	if err := a.userRepo.Delete(ctx, id, time.Time{}); err != nil {
+		return err
+	}
+	_, err := a.userRepo.Purge(ctx, time.Time{}.Add(time.Nanosecond))
+	return err
+}
+
+// hashPassword checks password against the policy and hashes it. An empty
+// password registers a user who cannot log in yet.
+func (a *userUsecase) hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	if a.credRepo == nil {
+		return "", fmt.Errorf("%w: passwords are not supported", domain.ErrBadParamInput)
+	}
+	if err := a.policy.Check(password); err != nil {
+		return "", err
+	}
+	return a.hasher.Hash(password)
 }
 
 func (a *userUsecase) GetUser(c context.Context, id int64) (*domain.User, error) {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
-	return a.userRepo.GetByID(ctx, id)
+
+	if domain.IncludeDeleted(ctx) {
+		if err := a.authorize(ctx, domain.ActionReadDeleted, nil); err != nil {
+			return nil, err
+		}
+	}
+	user, err := a.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.authorize(ctx, domain.ActionRead, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (a *userUsecase) UpdateUser(c context.Context, user *domain.User) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	current, err := a.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := domain.CheckVersion(ctx, current.Version); err != nil {
+		return err
+	}
+	if err := a.authorizeUpdate(ctx, current, user); err != nil {
+		return err
+	}
+	if field := withServerFields(user, current).ChangedServerField(current); field != "" {
+		return fmt.Errorf("%w: %s", domain.ErrImmutableField, field)
+	}
+	if err := user.Validate(); err != nil {
+		return err
+	}
+	a.touch(ctx, user)
+	// Pin the write to the version that was read, so that the audited
+	// current is the one replaced.
+	if err := a.userRepo.Update(domain.WithExpectedVersion(ctx, current.Version), user); err != nil {
+		return err
+	}
+	if a.audit != nil {
+		// The repository keeps some fields a replacement leaves out, so the
+		// stored user is what changed, not the input.
+		updated, err := a.userRepo.GetByID(ctx, user.ID)
+		if err != nil {
+			updated = user
+		}
+		a.record(ctx, domain.ActionUpdate, user.ID, current, updated)
+	}
+	return nil
+}
+
+// withServerFields returns a copy of the replacement user with the server
+// managed fields it leaves out taken from current. A replacement may omit
+// them, but not change them.
+func withServerFields(user, current *domain.User) *domain.User {
+	sent := *user
+	if sent.CreatedAt.IsZero() {
+		sent.CreatedAt = current.CreatedAt
+	}
+	if sent.CreatedBy == "" {
+		sent.CreatedBy = current.CreatedBy
+	}
+	if sent.UpdatedAt.IsZero() {
+		sent.UpdatedAt = current.UpdatedAt
+	}
+	if sent.UpdatedBy == "" {
+		sent.UpdatedBy = current.UpdatedBy
+	}
+	if sent.Version == 0 {
+		sent.Version = current.Version
+	}
+	if sent.EmailVerifiedAt == nil {
+		sent.EmailVerifiedAt = current.EmailVerifiedAt
+	}
+	if sent.DeletedAt == nil {
+		sent.DeletedAt = current.DeletedAt
+	}
+	return &sent
+}
+
+func (a *userUsecase) PatchUser(c context.Context, id int64, patch domain.UserPatch) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	current, err := a.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckVersion(ctx, current.Version); err != nil {
+		return nil, err
+	}
+
+	if err := a.authorize(ctx, domain.ActionUpdate, current); err != nil {
+		return nil, err
+	}
+
+	patched := *current
+	if err := patch.Apply(&patched); err != nil {
+		return nil, err
+	}
+	if err := a.authorizeUpdate(ctx, current, &patched); err != nil {
+		return nil, err
+	}
+	if field := patched.ChangedServerField(current); field != "" {
+		return nil, fmt.Errorf("%w: %s", domain.ErrImmutableField, field)
+	}
+	if err := patched.Validate(); err != nil {
+		return nil, err
+	}
+	a.touch(ctx, &patched)
+	// Pin the write to the version the patch was applied to, so a concurrent
+	// update between the read and the write is reported instead of lost.
+	if err := a.userRepo.Update(domain.WithExpectedVersion(ctx, current.Version), &patched); err != nil {
+		return nil, err
+	}
+	a.record(ctx, domain.ActionUpdate, id, current, &patched)
+	return &patched, nil
 }
 
 func (a *userUsecase) DeleteUser(c context.Context, id int64) error {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
-	return a.userRepo.Delete(ctx, id)
+
+	var current *domain.User
+	if a.authorizer != nil || a.audit != nil {
+		var err error
+		if current, err = a.userRepo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		if err := a.authorize(ctx, domain.ActionDelete, current); err != nil {
+			return err
+		}
+	}
+	if err := a.userRepo.Delete(ctx, id, a.now()); err != nil {
+		return err
+	}
+	if current != nil && a.audit != nil {
+		// The user is gone by now either way; a failed read only costs the
+		// audit trail its entry.
+		deleted, err := a.userRepo.GetByID(domain.WithDeleted(ctx), id)
+		if err != nil {
+			slog.ErrorContext(ctx, "audit failed", "action", domain.ActionDelete, "target", domain.UserTarget(id), "error", err)
+			return nil
+		}
+		a.record(ctx, domain.ActionDelete, id, current, deleted)
+	}
+	return nil
+}
+
+func (a *userUsecase) ListUsers(c context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err := a.authorize(ctx, domain.ActionList, nil); err != nil {
+		return nil, err
+	}
+	if opts.IncludeDeleted {
+		if err := a.authorize(ctx, domain.ActionReadDeleted, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+	return a.userRepo.List(ctx, opts)
+}
+
+func (a *userUsecase) RestoreUser(c context.Context, id int64) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	var deleted *domain.User
+	if a.authorizer != nil || a.audit != nil {
+		var err error
+		if deleted, err = a.userRepo.GetByID(domain.WithDeleted(ctx), id); err != nil {
+			return nil, err
+		}
+		if err := a.authorize(ctx, domain.ActionRestore, deleted); err != nil {
+			return nil, err
+		}
+	}
+	user, err := a.userRepo.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted != nil {
+		a.record(ctx, domain.ActionRestore, id, deleted, user)
+	}
+	return user, nil
+}
+
+func (a *userUsecase) authorize(ctx context.Context, action domain.Action, target *domain.User) error {
+	return authorize(ctx, a.authorizer, action, target)
+}
+
+// authorize checks that the caller in ctx may perform action on target. A nil
+// authorizer allows everything.
+func authorize(ctx context.Context, authorizer domain.Authorizer, action domain.Action, target *domain.User) error {
+	if authorizer == nil {
+		return nil
+	}
+	p, _ := domain.PrincipalFrom(ctx)
+	return authorizer.Authorize(p, action, target)
+}
+
+// authorizeUpdate checks that the caller may turn current into updated.
+// Changing the role needs a permission of its own.
+func (a *userUsecase) authorizeUpdate(ctx context.Context, current, updated *domain.User) error {
+	if err := a.authorize(ctx, domain.ActionUpdate, current); err != nil {
+		return err
+	}
+	if updated.Role != "" && updated.Role != current.Role {
+		return a.authorize(ctx, domain.ActionAssignRole, current)
+	}
+	return nil
+}
+
+// record appends an audit event for a mutation that has already been
+// stored. Failing to do so is logged rather than reported, as the caller
+// could not tell that the mutation itself succeeded.
+func (a *userUsecase) record(ctx context.Context, action domain.Action, id int64, before, after *domain.User) {
+	if a.audit == nil {
+		return
+	}
+	event := &domain.AuditEvent{
+		Time:      a.now(),
+		Actor:     domain.Actor(ctx),
+		Action:    action,
+		Target:    domain.UserTarget(id),
+		RequestID: domain.RequestID(ctx),
+		Changes:   domain.DiffUsers(before, after),
+	}
+	if err := a.audit.Append(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "audit failed", "action", action, "target", event.Target, "error", err)
+	}
+}
+
+// touch records the time and author of a modification.
+func (a *userUsecase) touch(ctx context.Context, user *domain.User) {
+	user.UpdatedAt = a.now()
+	user.UpdatedBy = domain.Actor(ctx)
+}
+
+func (a *userUsecase) now() time.Time {
+	if a.clock == nil {
+		return time.Now().UTC()
+	}
+	return a.clock.Now()
 }
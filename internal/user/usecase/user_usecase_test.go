@@ -3,16 +3,27 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"repo-guardian/internal/domain"
+	"repo-guardian/internal/user/authz"
+	"repo-guardian/internal/user/repository"
+	"strings"
 	"testing"
 	"time"
 )
 
 type mockUserRepository struct {
-	createFunc  func(ctx context.Context, user *domain.User) error
-	getByIDFunc func(ctx context.Context, id int64) (*domain.User, error)
-	deleteFunc  func(ctx context.Context, id int64) error
+	createFunc      func(ctx context.Context, user *domain.User) error
+	getByIDFunc     func(ctx context.Context, id int64) (*domain.User, error)
+	getByNameFunc   func(ctx context.Context, username string) (*domain.User, error)
+	updateFunc      func(ctx context.Context, user *domain.User) error
+	deleteFunc      func(ctx context.Context, id int64, at time.Time) error
+	listFunc        func(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error)
+	restoreFunc     func(ctx context.Context, id int64) (*domain.User, error)
+	verifyEmailFunc func(ctx context.Context, id int64, email string, at time.Time) error
+	purgeFunc       func(ctx context.Context, cutoff time.Time) (int, error)
+	anonymizeFunc   func(ctx context.Context, id int64, at time.Time) (*domain.User, error)
 }
 
 func (m *mockUserRepository) Create(ctx context.Context, user *domain.User) error {
@@ -23,8 +34,36 @@ func (m *mockUserRepository) GetByID(ctx context.Context, id int64) (*domain.Use
 	return m.getByIDFunc(ctx, id)
 }
 
-func (m *mockUserRepository) Delete(ctx context.Context, id int64) error {
-	return m.deleteFunc(ctx, id)
+func (m *mockUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return m.getByNameFunc(ctx, username)
+}
+
+func (m *mockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	return m.updateFunc(ctx, user)
+}
+
+func (m *mockUserRepository) Delete(ctx context.Context, id int64, at time.Time) error {
+	return m.deleteFunc(ctx, id, at)
+}
+
+func (m *mockUserRepository) List(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+	return m.listFunc(ctx, opts)
+}
+
+func (m *mockUserRepository) VerifyEmail(ctx context.Context, id int64, email string, at time.Time) error {
+	return m.verifyEmailFunc(ctx, id, email, at)
+}
+
+func (m *mockUserRepository) Restore(ctx context.Context, id int64) (*domain.User, error) {
+	return m.restoreFunc(ctx, id)
+}
+
+func (m *mockUserRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	return m.purgeFunc(ctx, cutoff)
+}
+
+func (m *mockUserRepository) Anonymize(ctx context.Context, id int64, at time.Time) (*domain.User, error) {
+	return m.anonymizeFunc(ctx, id, at)
 }
 
 func TestNewUserUsecase(t *testing.T) {
@@ -63,11 +102,11 @@ func TestUserUsecase_Register(t *testing.T) {
 		user *domain.User
 	}
 	tests := []struct {
-		name    string
+		name     string
 		mockRepo func() *mockUserRepository
-		args    args
-		wantErr bool
-		err     error
+		args     args
+		wantErr  bool
+		err      error
 	}{
 		{
 			name: "success",
@@ -80,7 +119,7 @@ func TestUserUsecase_Register(t *testing.T) {
 			},
 			args: args{
 				c:    context.Background(),
-				user: &domain.User{Name: "test"},
+				user: &domain.User{Username: "test", Email: "test@example.com"},
 			},
 			wantErr: false,
 		},
@@ -95,7 +134,7 @@ func TestUserUsecase_Register(t *testing.T) {
 			},
 			args: args{
 				c:    context.Background(),
-				user: &domain.User{Name: "test"},
+				user: &domain.User{Username: "test", Email: "test@example.com"},
 			},
 			wantErr: true,
 			err:     errors.New("create error"),
@@ -119,25 +158,186 @@ func TestUserUsecase_Register(t *testing.T) {
 	}
 }
 
+func TestUserUsecase_Register_AuditFields(t *testing.T) {
+	var stored *domain.User
+	repo := &mockUserRepository{
+		createFunc: func(ctx context.Context, user *domain.User) error {
+			stored = user
+			return nil
+		},
+	}
+	a := NewUserUsecase(repo, time.Second, WithClock(fixedClock(testNow)))
+	ctx := domain.WithPrincipal(context.Background(), domain.Principal{Subject: "user:7"})
+
+	user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", CreatedBy: "spoofed"}
+	if err := a.Register(ctx, user); err != nil {
+		t.Fatalf("userUsecase.Register() error = %v", err)
+	}
+	want := &domain.User{
+		ID:        1,
+		Username:  "test",
+		Email:     "test@example.com",
+		Role:      domain.RoleUser,
+		CreatedAt: testNow,
+		CreatedBy: "user:7",
+		UpdatedAt: testNow,
+		UpdatedBy: "user:7",
+	}
+	if !reflect.DeepEqual(stored, want) {
+		t.Errorf("userUsecase.Register() stored %+v, want %+v", stored, want)
+	}
+}
+
+type mockCredentialRepository struct {
+	creds  map[int64]domain.Credential
+	setErr error
+}
+
+func (m *mockCredentialRepository) Get(ctx context.Context, userID int64) (*domain.Credential, error) {
+	cred, ok := m.creds[userID]
+	if !ok {
+		return nil, domain.ErrCredentialNotFound
+	}
+	return &cred, nil
+}
+
+func (m *mockCredentialRepository) Set(ctx context.Context, cred *domain.Credential) error {
+	if m.setErr != nil {
+		return m.setErr
+	}
+	if m.creds == nil {
+		m.creds = make(map[int64]domain.Credential)
+	}
+	m.creds[cred.UserID] = *cred
+	return nil
+}
+
+func (m *mockCredentialRepository) Delete(ctx context.Context, userID int64) error {
+	delete(m.creds, userID)
+	return nil
+}
+
+// prefixHasher "hashes" by prefixing the password with its cost, so tests can
+// tell which parameters produced a hash.
+type prefixHasher struct {
+	cost string
+}
+
+func (h prefixHasher) Hash(password string) (string, error) {
+	return h.cost + ":" + password, nil
+}
+
+func (h prefixHasher) Verify(password, encoded string) (bool, bool, error) {
+	cost, pw, _ := strings.Cut(encoded, ":")
+	return pw == password, cost != h.cost, nil
+}
+
+type minLengthPolicy int
+
+func (p minLengthPolicy) Check(password string) error {
+	if len(password) < int(p) {
+		return domain.ErrWeakPassword
+	}
+	return nil
+}
+
+func TestUserUsecase_Register_Password(t *testing.T) {
+	newUsecase := func(creds *mockCredentialRepository, stored **domain.User) *userUsecase {
+		repo := &mockUserRepository{
+			createFunc: func(ctx context.Context, user *domain.User) error {
+				*stored = user
+				return nil
+			},
+		}
+		return NewUserUsecase(repo, time.Second,
+			WithClock(fixedClock(testNow)),
+			WithCredentials(creds, prefixHasher{cost: "v1"}, minLengthPolicy(8)),
+		).(*userUsecase)
+	}
+
+	t.Run("password is hashed and never stored on the user", func(t *testing.T) {
+		creds := &mockCredentialRepository{}
+		var stored *domain.User
+		a := newUsecase(creds, &stored)
+
+		user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Password: "correct horse"}
+		if err := a.Register(context.Background(), user); err != nil {
+			t.Fatalf("userUsecase.Register() error = %v", err)
+		}
+		if stored.Password != "" || user.Password != "" {
+			t.Errorf("userUsecase.Register() kept the plaintext password on the user")
+		}
+		want := domain.Credential{UserID: 1, PasswordHash: "v1:correct horse", UpdatedAt: testNow}
+		if creds.creds[1] != want {
+			t.Errorf("userUsecase.Register() stored credential %+v, want %+v", creds.creds[1], want)
+		}
+	})
+
+	t.Run("weak password is rejected before the user is created", func(t *testing.T) {
+		var stored *domain.User
+		a := newUsecase(&mockCredentialRepository{}, &stored)
+
+		user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Password: "short"}
+		if err := a.Register(context.Background(), user); !errors.Is(err, domain.ErrWeakPassword) {
+			t.Errorf("userUsecase.Register() error = %v, want %v", err, domain.ErrWeakPassword)
+		}
+		if stored != nil {
+			t.Errorf("userUsecase.Register() created a user with a weak password")
+		}
+	})
+
+	t.Run("failing to store the password removes the user", func(t *testing.T) {
+		repo := repository.NewMemoryUserRepository()
+		creds := &mockCredentialRepository{setErr: errors.New("write error")}
+		a := NewUserUsecase(repo, time.Second,
+			WithClock(fixedClock(testNow)),
+			WithCredentials(creds, prefixHasher{cost: "v1"}, minLengthPolicy(8)))
+		ctx := context.Background()
+
+		user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Password: "correct horse"}
+		if err := a.Register(ctx, user); err == nil || err.Error() != "write error" {
+			t.Fatalf("userUsecase.Register() error = %v, want the write error", err)
+		}
+		if _, err := repo.GetByID(domain.WithDeleted(ctx), 1); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID() after a failed registration error = %v, want %v", err, domain.ErrNotFound)
+		}
+
+		creds.setErr = nil
+		user = &domain.User{ID: 1, Username: "test", Email: "test@example.com", Password: "correct horse"}
+		if err := a.Register(ctx, user); err != nil {
+			t.Errorf("userUsecase.Register() retry error = %v", err)
+		}
+	})
+
+	t.Run("password without credentials configured", func(t *testing.T) {
+		a := &userUsecase{userRepo: &mockUserRepository{}, contextTimeout: time.Second}
+
+		user := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Password: "correct horse"}
+		if err := a.Register(context.Background(), user); !errors.Is(err, domain.ErrBadParamInput) {
+			t.Errorf("userUsecase.Register() error = %v, want %v", err, domain.ErrBadParamInput)
+		}
+	})
+}
+
 func TestUserUsecase_GetUser(t *testing.T) {
 	type args struct {
 		c  context.Context
 		id int64
 	}
 	tests := []struct {
-		name    string
+		name     string
 		mockRepo func() *mockUserRepository
-		args    args
-		want    *domain.User
-		wantErr bool
-		err     error
+		args     args
+		want     *domain.User
+		wantErr  bool
+		err      error
 	}{
 		{
 			name: "success",
 			mockRepo: func() *mockUserRepository {
 				return &mockUserRepository{
 					getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
-						return &domain.User{ID: id, Name: "test"}, nil
+						return &domain.User{ID: id, Username: "test"}, nil
 					},
 				}
 			},
@@ -145,7 +345,7 @@ func TestUserUsecase_GetUser(t *testing.T) {
 				c:  context.Background(),
 				id: 1,
 			},
-			want:    &domain.User{ID: 1, Name: "test"},
+			want:    &domain.User{ID: 1, Username: "test"},
 			wantErr: false,
 		},
 		{
@@ -187,23 +387,281 @@ func TestUserUsecase_GetUser(t *testing.T) {
 	}
 }
 
+func TestUserUsecase_UpdateUser_AuditFields(t *testing.T) {
+	var stored *domain.User
+	repo := &mockUserRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{ID: id, Username: "test", Email: "test@example.com"}, nil
+		},
+		updateFunc: func(ctx context.Context, user *domain.User) error {
+			stored = user
+			return nil
+		},
+	}
+	a := NewUserUsecase(repo, time.Second, WithClock(fixedClock(testNow)))
+
+	user := &domain.User{ID: 1, Username: "test", Email: "test@example.com"}
+	if err := a.UpdateUser(context.Background(), user); err != nil {
+		t.Fatalf("userUsecase.UpdateUser() error = %v", err)
+	}
+	if !stored.UpdatedAt.Equal(testNow) || stored.UpdatedBy != "" {
+		t.Errorf("userUsecase.UpdateUser() stored updated_at %v by %q, want %v by anonymous", stored.UpdatedAt, stored.UpdatedBy, testNow)
+	}
+}
+
+func TestUserUsecase_UpdateUser(t *testing.T) {
+	created := testNow.Add(-time.Hour)
+	stored := func(update func(ctx context.Context, user *domain.User) error) func() *mockUserRepository {
+		return func() *mockUserRepository {
+			return &mockUserRepository{
+				getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+					return &domain.User{ID: id, Username: "test", Email: "test@example.com", CreatedAt: created, Version: 3}, nil
+				},
+				updateFunc: update,
+			}
+		}
+	}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		mockRepo func() *mockUserRepository
+		user     *domain.User
+		wantErr  error
+	}{
+		{
+			name: "success",
+			mockRepo: stored(func(ctx context.Context, user *domain.User) error {
+				if version, ok := domain.ExpectedVersion(ctx); !ok || version != 3 {
+					return fmt.Errorf("update expected version %d, %v, want it pinned to 3", version, ok)
+				}
+				return nil
+			}),
+			user: &domain.User{ID: 1, Username: "test", Email: "test@example.com"},
+		},
+		{
+			name:     "unchanged server fields",
+			mockRepo: stored(func(ctx context.Context, user *domain.User) error { return nil }),
+			user:     &domain.User{ID: 1, Username: "test", Email: "test@example.com", CreatedAt: created, Version: 3},
+		},
+		{
+			name:     "changed created_at",
+			mockRepo: stored(nil),
+			user:     &domain.User{ID: 1, Username: "test", Email: "test@example.com", CreatedAt: testNow},
+			wantErr:  domain.ErrImmutableField,
+		},
+		{
+			name:     "changed version",
+			mockRepo: stored(nil),
+			user:     &domain.User{ID: 1, Username: "test", Email: "test@example.com", Version: 7},
+			wantErr:  domain.ErrImmutableField,
+		},
+		{
+			name:     "stale If-Match",
+			ctx:      domain.WithExpectedVersion(context.Background(), 2),
+			mockRepo: stored(nil),
+			user:     &domain.User{ID: 1, Username: "test", Email: "test@example.com"},
+			wantErr:  domain.ErrVersionConflict,
+		},
+		{
+			name:     "invalid user",
+			mockRepo: stored(nil),
+			user:     &domain.User{ID: 1, Username: "test"},
+			wantErr:  domain.ErrBadParamInput,
+		},
+		{
+			name: "not found",
+			mockRepo: func() *mockUserRepository {
+				return &mockUserRepository{
+					getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+						return nil, domain.ErrNotFound
+					},
+				}
+			},
+			user:    &domain.User{ID: 1, Username: "test", Email: "test@example.com"},
+			wantErr: domain.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &userUsecase{
+				userRepo:       tt.mockRepo(),
+				contextTimeout: time.Second,
+			}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			err := a.UpdateUser(ctx, tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("userUsecase.UpdateUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+var testNow = time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+
+type patchFunc func(user *domain.User) error
+
+func (f patchFunc) Apply(user *domain.User) error {
+	return f(user)
+}
+
+func TestUserUsecase_PatchUser(t *testing.T) {
+	stored := func() *mockUserRepository {
+		return &mockUserRepository{
+			getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+				return &domain.User{ID: id, Username: "test", Email: "test@example.com"}, nil
+			},
+			updateFunc: func(ctx context.Context, user *domain.User) error {
+				return nil
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		mockRepo func() *mockUserRepository
+		patch    patchFunc
+		want     *domain.User
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			mockRepo: stored,
+			patch: func(user *domain.User) error {
+				user.Email = "new@example.com"
+				return nil
+			},
+			want: &domain.User{ID: 1, Username: "test", Email: "new@example.com", UpdatedAt: testNow, UpdatedBy: "user:7"},
+		},
+		{
+			name:     "id is immutable",
+			mockRepo: stored,
+			patch: func(user *domain.User) error {
+				user.ID = 2
+				return nil
+			},
+			wantErr: domain.ErrImmutableField,
+		},
+		{
+			name:     "validation reapplied",
+			mockRepo: stored,
+			patch: func(user *domain.User) error {
+				user.Email = ""
+				return nil
+			},
+			wantErr: domain.ErrBadParamInput,
+		},
+		{
+			name: "not found",
+			mockRepo: func() *mockUserRepository {
+				return &mockUserRepository{
+					getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+						return nil, domain.ErrNotFound
+					},
+				}
+			},
+			patch: func(user *domain.User) error {
+				return nil
+			},
+			wantErr: domain.ErrNotFound,
+		},
+		{
+			name: "write is pinned to the version read",
+			mockRepo: func() *mockUserRepository {
+				repo := stored()
+				repo.updateFunc = func(ctx context.Context, user *domain.User) error {
+					if v, ok := domain.ExpectedVersion(ctx); !ok || v != user.Version {
+						t.Errorf("Update() called with expected version %d, %v", v, ok)
+					}
+					return domain.ErrVersionConflict
+				}
+				return repo
+			},
+			patch: func(user *domain.User) error {
+				return nil
+			},
+			wantErr: domain.ErrVersionConflict,
+		},
+		{
+			name:     "stale If-Match",
+			ctx:      domain.WithExpectedVersion(context.Background(), 5),
+			mockRepo: stored,
+			patch: func(user *domain.User) error {
+				t.Errorf("patch applied despite version mismatch")
+				return nil
+			},
+			wantErr: domain.ErrVersionConflict,
+		},
+		{
+			name:     "updated_at is immutable",
+			mockRepo: stored,
+			patch: func(user *domain.User) error {
+				user.UpdatedAt = testNow
+				return nil
+			},
+			wantErr: domain.ErrImmutableField,
+		},
+		{
+			name:     "version is immutable",
+			mockRepo: stored,
+			patch: func(user *domain.User) error {
+				user.Version = 9
+				return nil
+			},
+			wantErr: domain.ErrImmutableField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &userUsecase{
+				userRepo:       tt.mockRepo(),
+				contextTimeout: time.Second,
+				clock:          fixedClock(testNow),
+			}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx = domain.WithPrincipal(ctx, domain.Principal{Subject: "user:7"})
+			got, err := a.PatchUser(ctx, 1, tt.patch)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("userUsecase.PatchUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userUsecase.PatchUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestUserUsecase_DeleteUser(t *testing.T) {
 	type args struct {
 		c  context.Context
 		id int64
 	}
 	tests := []struct {
-		name    string
+		name     string
 		mockRepo func() *mockUserRepository
-		args    args
-		wantErr bool
-		err     error
+		args     args
+		wantErr  bool
+		err      error
 	}{
 		{
 			name: "success",
 			mockRepo: func() *mockUserRepository {
 				return &mockUserRepository{
-					deleteFunc: func(ctx context.Context, id int64) error {
+					deleteFunc: func(ctx context.Context, id int64, at time.Time) error {
+						if !at.Equal(testNow) {
+							return fmt.Errorf("deleted at %v, want the clock's %v", at, testNow)
+						}
 						return nil
 					},
 				}
@@ -218,7 +676,7 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 			name: "failure",
 			mockRepo: func() *mockUserRepository {
 				return &mockUserRepository{
-					deleteFunc: func(ctx context.Context, id int64) error {
+					deleteFunc: func(ctx context.Context, id int64, at time.Time) error {
 						return errors.New("delete error")
 					},
 				}
@@ -236,6 +694,7 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 			a := &userUsecase{
 				userRepo:       tt.mockRepo(),
 				contextTimeout: time.Second,
+				clock:          fixedClock(testNow),
 			}
 			err := a.DeleteUser(tt.args.c, tt.args.id)
 			if (err != nil) != tt.wantErr {
@@ -247,3 +706,136 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUsecase_ListUsers(t *testing.T) {
+	var got domain.ListOptions
+	repo := &mockUserRepository{
+		listFunc: func(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+			got = opts
+			return &domain.UserPage{}, nil
+		},
+	}
+	a := &userUsecase{userRepo: repo, contextTimeout: time.Second}
+
+	if _, err := a.ListUsers(context.Background(), domain.ListOptions{}); err != nil {
+		t.Fatalf("userUsecase.ListUsers() error = %v", err)
+	}
+	want := domain.ListOptions{Limit: domain.DefaultListLimit, SortBy: domain.SortByID}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("userUsecase.ListUsers() passed %+v, want %+v", got, want)
+	}
+
+	_, err := a.ListUsers(context.Background(), domain.ListOptions{SortBy: "email"})
+	if !errors.Is(err, domain.ErrBadParamInput) {
+		t.Errorf("userUsecase.ListUsers() error = %v, want %v", err, domain.ErrBadParamInput)
+	}
+}
+
+func TestUserUsecase_RestoreUser(t *testing.T) {
+	restored := &domain.User{ID: 1, Username: "test", Email: "test@example.com", Version: 3}
+	repo := &mockUserRepository{
+		restoreFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if id != 1 {
+				return nil, domain.ErrNotFound
+			}
+			return restored, nil
+		},
+	}
+	a := &userUsecase{userRepo: repo, contextTimeout: time.Second}
+
+	got, err := a.RestoreUser(context.Background(), 1)
+	if err != nil || got != restored {
+		t.Errorf("userUsecase.RestoreUser() = %v, %v, want %v, nil", got, err, restored)
+	}
+	if _, err := a.RestoreUser(context.Background(), 2); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("userUsecase.RestoreUser() error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestUserUsecase_Authorization(t *testing.T) {
+	users := map[int64]*domain.User{
+		1: {ID: 1, Username: "alice", Email: "alice@example.com", Role: domain.RoleUser, Version: 1},
+		2: {ID: 2, Username: "bob", Email: "bob@example.com", Role: domain.RoleUser, Version: 1},
+	}
+	var writes int
+	repo := &mockUserRepository{
+		createFunc: func(ctx context.Context, user *domain.User) error { writes++; return nil },
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if user, ok := users[id]; ok {
+				copied := *user
+				return &copied, nil
+			}
+			return nil, domain.ErrNotFound
+		},
+		updateFunc: func(ctx context.Context, user *domain.User) error { writes++; return nil },
+		deleteFunc: func(ctx context.Context, id int64, at time.Time) error { writes++; return nil },
+		listFunc: func(ctx context.Context, opts domain.ListOptions) (*domain.UserPage, error) {
+			return &domain.UserPage{}, nil
+		},
+		restoreFunc: func(ctx context.Context, id int64) (*domain.User, error) { writes++; return users[id], nil },
+	}
+	a := NewUserUsecase(repo, time.Second, WithAuthorizer(authz.NewPolicy(authz.DefaultRules...)))
+
+	alice := domain.WithPrincipal(context.Background(), domain.UserPrincipal(1, domain.RoleUser))
+	admin := domain.WithPrincipal(context.Background(), domain.UserPrincipal(9, domain.RoleAdmin))
+	anonymous := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"register anonymously", func() error {
+			return a.Register(anonymous, &domain.User{ID: 3, Username: "carol", Email: "carol@example.com"})
+		}, nil},
+		{"register as admin anonymously", func() error {
+			return a.Register(anonymous, &domain.User{ID: 3, Username: "carol", Email: "carol@example.com", Role: domain.RoleAdmin})
+		}, domain.ErrUnauthenticated},
+		{"read self", func() error { _, err := a.GetUser(alice, 1); return err }, nil},
+		{"read other", func() error { _, err := a.GetUser(alice, 2); return err }, domain.ErrForbidden},
+		{"read anonymously", func() error { _, err := a.GetUser(anonymous, 1); return err }, domain.ErrUnauthenticated},
+		{"read deleted as user", func() error { _, err := a.GetUser(domain.WithDeleted(alice), 1); return err }, domain.ErrForbidden},
+		{"update self", func() error {
+			return a.UpdateUser(alice, &domain.User{ID: 1, Username: "alice2", Email: "alice@example.com"})
+		}, nil},
+		{"update other", func() error {
+			return a.UpdateUser(alice, &domain.User{ID: 2, Username: "bob2", Email: "bob@example.com"})
+		}, domain.ErrForbidden},
+		{"promote self", func() error {
+			return a.UpdateUser(alice, &domain.User{ID: 1, Username: "alice", Email: "alice@example.com", Role: domain.RoleAdmin})
+		}, domain.ErrForbidden},
+		{"promote self by patch", func() error {
+			_, err := a.PatchUser(alice, 1, patchFunc(func(u *domain.User) error { u.Role = domain.RoleAdmin; return nil }))
+			return err
+		}, domain.ErrForbidden},
+		{"patch other", func() error {
+			_, err := a.PatchUser(alice, 2, patchFunc(func(u *domain.User) error { return nil }))
+			return err
+		}, domain.ErrForbidden},
+		{"delete self", func() error { return a.DeleteUser(alice, 1) }, nil},
+		{"delete other", func() error { return a.DeleteUser(alice, 2) }, domain.ErrForbidden},
+		{"list as user", func() error { _, err := a.ListUsers(alice, domain.ListOptions{}); return err }, domain.ErrForbidden},
+		{"restore as user", func() error { _, err := a.RestoreUser(alice, 1); return err }, domain.ErrForbidden},
+		{"admin promotes other", func() error {
+			return a.UpdateUser(admin, &domain.User{ID: 2, Username: "bob", Email: "bob@example.com", Role: domain.RoleAdmin})
+		}, nil},
+		{"admin deletes other", func() error { return a.DeleteUser(admin, 2) }, nil},
+		{"admin lists deleted", func() error {
+			_, err := a.ListUsers(admin, domain.ListOptions{IncludeDeleted: true})
+			return err
+		}, nil},
+		{"admin restores", func() error { _, err := a.RestoreUser(admin, 1); return err }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := writes
+			err := tt.call()
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && writes != before {
+				t.Errorf("a denied call reached the repository")
+			}
+		})
+	}
+}